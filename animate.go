@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"rtt/scene"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+func animateCommand(args []string, stdout, stderr io.Writer) int {
+	flags := pflag.NewFlagSet("animate", pflag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: rtt animate <scene.yaml> -o <frame-%04d.png|frame-%04d.ppm> --first <n> --last <m> [flags]")
+		flags.PrintDefaults()
+	}
+
+	output := flags.StringP("output", "o", "", "image files to write, with a verb such as %04d for the frame number")
+	first := flags.Int("first", 0, "first frame to render")
+	last := flags.Int("last", 0, "last frame to render")
+	options := addRenderFlags(flags)
+
+	if err := flags.Parse(args); err != nil {
+		if err == pflag.ErrHelp {
+			return exitOK
+		}
+		return exitUsage
+	}
+
+	if flags.NArg() != 1 {
+		fmt.Fprintln(stderr, "rtt animate: expected exactly one scene file")
+		flags.Usage()
+		return exitUsage
+	}
+
+	if *output == "" {
+		fmt.Fprintln(stderr, "rtt animate: an output file pattern must be given with -o")
+		flags.Usage()
+		return exitUsage
+	}
+
+	if err := checkFramePattern(*output); err != nil {
+		fmt.Fprintf(stderr, "rtt animate: %s\n", err)
+		return exitUsage
+	}
+
+	if *first < 0 || *last < *first {
+		fmt.Fprintln(stderr, "rtt animate: frames must not be negative and the last frame must not come before the first")
+		return exitUsage
+	}
+
+	if err := options.validate(); err != nil {
+		fmt.Fprintf(stderr, "rtt animate: %s\n", err)
+		return exitUsage
+	}
+
+	s, err := scene.LoadFile(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintf(stderr, "rtt animate: %s\n", err)
+		return exitFailure
+	}
+
+	c, err := options.camera(s)
+	if err != nil {
+		fmt.Fprintf(stderr, "rtt animate: %s\n", err)
+		return exitUsage
+	}
+
+	for frame := *first; frame <= *last; frame++ {
+		if err := s.Animation.Apply(float64(frame)); err != nil {
+			fmt.Fprintf(stderr, "rtt animate: %s\n", err)
+			return exitFailure
+		}
+
+		// The flags may have replaced the scene camera with a resized copy,
+		// which the animation does not move.
+		if err := c.SetTransform(s.Camera.Transform()); err != nil {
+			fmt.Fprintf(stderr, "rtt animate: frame %d: %s\n", frame, err)
+			return exitFailure
+		}
+
+		path := fmt.Sprintf(*output, frame)
+		if err := writeImage(path, c.RenderWithWorkers(s.World, options.workers)); err != nil {
+			fmt.Fprintf(stderr, "rtt animate: %s\n", err)
+			return exitFailure
+		}
+
+		fmt.Fprintln(stdout, path)
+	}
+
+	return exitOK
+}
+
+// checkFramePattern makes sure every frame gets its own file of a format
+// writeImage supports.
+func checkFramePattern(pattern string) error {
+	path := fmt.Sprintf(pattern, 0)
+
+	if strings.Contains(path, "%!") || path == fmt.Sprintf(pattern, 1) {
+		return fmt.Errorf("output %q must contain one verb such as %%04d for the frame number", pattern)
+	}
+
+	_, err := imageFormat(path)
+	return err
+}
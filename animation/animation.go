@@ -0,0 +1,127 @@
+package animation
+
+import (
+	"fmt"
+	"rtt/matrix"
+	"rtt/transformations"
+	"sort"
+)
+
+// Keyframe fixes a transform at a point in time, measured in frames.
+type Keyframe struct {
+	Frame     float64
+	Transform matrix.Mat4
+}
+
+// Track interpolates between keyframes. Before the first keyframe and after
+// the last one it holds still.
+type Track struct {
+	keyframes []Keyframe
+}
+
+// Target is anything whose transform can be animated, such as a shape or a
+// camera.
+type Target interface {
+	SetTransform(transform matrix.Mat4) error
+}
+
+// Mover is a target that can move while the camera's shutter is open. Over
+// each frame it moves from where its track puts it at that frame to where
+// the track puts it at the next, so that fast movement is blurred.
+type Mover interface {
+	Target
+	SetMotion(start, end matrix.Mat4) error
+}
+
+type binding struct {
+	target Target
+	track  *Track
+}
+
+// Animation moves its targets along their tracks. The targets are updated in
+// place, so the same world can be rendered frame after frame.
+type Animation struct {
+	bindings []binding
+}
+
+func NewTrack(keyframes ...Keyframe) (*Track, error) {
+	if len(keyframes) == 0 {
+		return nil, fmt.Errorf("a track needs at least one keyframe")
+	}
+
+	sorted := append([]Keyframe{}, keyframes...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Frame < sorted[j].Frame
+	})
+
+	for i, k := range sorted {
+		if i > 0 && k.Frame == sorted[i-1].Frame {
+			return nil, fmt.Errorf("two keyframes at frame %g", k.Frame)
+		}
+
+		if _, _, _, err := transformations.Decompose(k.Transform); err != nil {
+			return nil, fmt.Errorf("keyframe at frame %g: %w", k.Frame, err)
+		}
+	}
+
+	return &Track{keyframes: sorted}, nil
+}
+
+func (t *Track) At(frame float64) (matrix.Mat4, error) {
+	first := t.keyframes[0]
+	if frame <= first.Frame {
+		return first.Transform, nil
+	}
+
+	for i := 1; i < len(t.keyframes); i++ {
+		a, b := t.keyframes[i-1], t.keyframes[i]
+		if frame < b.Frame {
+			return transformations.Interpolate(a.Transform, b.Transform, (frame-a.Frame)/(b.Frame-a.Frame))
+		}
+	}
+
+	return t.keyframes[len(t.keyframes)-1].Transform, nil
+}
+
+func NewAnimation() *Animation {
+	return &Animation{}
+}
+
+func (a *Animation) Add(target Target, track *Track) {
+	a.bindings = append(a.bindings, binding{target: target, track: track})
+}
+
+func (a *Animation) Len() int {
+	return len(a.bindings)
+}
+
+// Apply moves every target to where its track puts it at frame, and sets
+// every mover moving towards where it will be at the next frame.
+func (a *Animation) Apply(frame float64) error {
+	for _, b := range a.bindings {
+		transform, err := b.track.At(frame)
+		if err != nil {
+			return err
+		}
+
+		if mover, ok := b.target.(Mover); ok {
+			next, err := b.track.At(frame + 1)
+			if err != nil {
+				return err
+			}
+
+			if !next.Equals(transform) {
+				if err := mover.SetMotion(transform, next); err != nil {
+					return fmt.Errorf("frame %g: %w", frame, err)
+				}
+				continue
+			}
+		}
+
+		if err := b.target.SetTransform(transform); err != nil {
+			return fmt.Errorf("frame %g: %w", frame, err)
+		}
+	}
+
+	return nil
+}
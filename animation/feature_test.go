@@ -0,0 +1,182 @@
+package animation
+
+import (
+	"context"
+	"fmt"
+	"rtt/matrix"
+	"rtt/ray"
+	"rtt/sharedtest"
+	"rtt/transformations"
+	"rtt/tuple"
+	"rtt/tupletest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/cucumber/godog"
+)
+
+func getMatrix(ctx context.Context, variable string) matrix.Mat4 {
+	return ctx.Value(sharedtest.Variables{Name: variable}).(matrix.Mat4)
+}
+
+func aMatrix(ctx context.Context, variable, kind string, x, y, z float64) (context.Context, error) {
+	m := transformations.Translation(x, y, z)
+	if kind == "scaling" {
+		m = transformations.Scaling(x, y, z)
+	}
+
+	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, m), nil
+}
+
+func aRotation(ctx context.Context, variable string, r float64) (context.Context, error) {
+	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, transformations.RotationY(r)), nil
+}
+
+func aShearing(ctx context.Context, variable string, xy, xz, yx, yz, zx, zy float64) (context.Context, error) {
+	m := transformations.Shearing(xy, xz, yx, yz, zx, zy)
+	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, m), nil
+}
+
+// parseTrack builds a track from a list such as "0: A, 10: B", where each
+// keyframe names a matrix variable.
+func parseTrack(ctx context.Context, list string) (*Track, error) {
+	keyframes := []Keyframe{}
+
+	for _, entry := range strings.Split(list, ", ") {
+		frame, name, ok := strings.Cut(entry, ": ")
+		if !ok {
+			return nil, fmt.Errorf("keyframe %q is not of the form frame: matrix", entry)
+		}
+
+		f, err := strconv.ParseFloat(frame, 64)
+		if err != nil {
+			return nil, err
+		}
+
+		keyframes = append(keyframes, Keyframe{Frame: f, Transform: getMatrix(ctx, name)})
+	}
+
+	return NewTrack(keyframes...)
+}
+
+func aTrack(ctx context.Context, variable, list string) (context.Context, error) {
+	track, err := parseTrack(ctx, list)
+	if err != nil {
+		return ctx, err
+	}
+
+	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, track), nil
+}
+
+func aTransformAt(ctx context.Context, variable, trackVariable string, frame float64) (context.Context, error) {
+	track := ctx.Value(sharedtest.Variables{Name: trackVariable}).(*Track)
+
+	m, err := track.At(frame)
+	if err != nil {
+		return ctx, err
+	}
+
+	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, m), nil
+}
+
+func aSphere(ctx context.Context, variable string) (context.Context, error) {
+	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, ray.NewSphere()), nil
+}
+
+func anAnimation(ctx context.Context, variable string) (context.Context, error) {
+	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, NewAnimation()), nil
+}
+
+func addToAnimation(ctx context.Context, variable, targetVariable, trackVariable string) (context.Context, error) {
+	a := ctx.Value(sharedtest.Variables{Name: variable}).(*Animation)
+	target := ctx.Value(sharedtest.Variables{Name: targetVariable}).(Target)
+	track := ctx.Value(sharedtest.Variables{Name: trackVariable}).(*Track)
+
+	a.Add(target, track)
+	return ctx, nil
+}
+
+func applyAnimation(ctx context.Context, variable string, frame float64) (context.Context, error) {
+	a := ctx.Value(sharedtest.Variables{Name: variable}).(*Animation)
+	return ctx, a.Apply(frame)
+}
+
+func assertTrackFails(ctx context.Context, list, expected string) error {
+	_, err := parseTrack(ctx, list)
+	if err == nil {
+		return fmt.Errorf("track(%s) did not fail", list)
+	}
+
+	if err.Error() != expected {
+		return fmt.Errorf("Error %q != %q!", err.Error(), expected)
+	}
+	return nil
+}
+
+func assertTransformedPoint(ctx context.Context, variable, pointVariable string, x, y, z float64) error {
+	var m matrix.Mat4
+	if name, ok := strings.CutSuffix(variable, ".transform"); ok {
+		m = ctx.Value(sharedtest.Variables{Name: name}).(*ray.Sphere).Transform()
+	} else {
+		m = getMatrix(ctx, variable)
+	}
+
+	p := ctx.Value(sharedtest.Variables{Name: pointVariable}).(tuple.Point)
+	actual := m.MultiplyPoint(p)
+	expected := tuple.NewPoint(x, y, z)
+
+	if !actual.Equals(expected) {
+		return fmt.Errorf("Error %+v != %+v!", actual, expected)
+	}
+	return nil
+}
+
+func assertTransformedPointAt(ctx context.Context, variable string, time float64, pointVariable string, x, y, z float64) error {
+	m := ctx.Value(sharedtest.Variables{Name: variable}).(*ray.Sphere).TransformAt(time)
+	p := ctx.Value(sharedtest.Variables{Name: pointVariable}).(tuple.Point)
+	actual := m.MultiplyPoint(p)
+	expected := tuple.NewPoint(x, y, z)
+
+	if !actual.Equals(expected) {
+		return fmt.Errorf("Error %+v != %+v!", actual, expected)
+	}
+	return nil
+}
+
+func InitializeScenario(sc *godog.ScenarioContext) {
+	v := sharedtest.TupleVariableName
+	m := sharedtest.MatrixVariableName
+	d := sharedtest.Decimal
+
+	tupletest.AddConstructPoint(sc)
+
+	sc.Step(fmt.Sprintf(`^%s ← (translation|scaling)\(%s, %s, %s\)$`, m, d, d, d), aMatrix)
+	sc.Step(fmt.Sprintf(`^%s ← rotation_y\(%s\)$`, m, d), aRotation)
+	sc.Step(fmt.Sprintf(`^%s ← shearing\(%s, %s, %s, %s, %s, %s\)$`, m, d, d, d, d, d, d), aShearing)
+	sc.Step(fmt.Sprintf(`^%s ← track\((.+)\)$`, v), aTrack)
+	sc.Step(fmt.Sprintf(`^%s ← at\(%s, %s\)$`, m, v, d), aTransformAt)
+	sc.Step(fmt.Sprintf(`^%s ← sphere\(\)$`, v), aSphere)
+	sc.Step(fmt.Sprintf(`^%s ← animation\(\)$`, v), anAnimation)
+	sc.Step(fmt.Sprintf(`^add\(%s, %s, %s\)$`, v, v, v), addToAnimation)
+	sc.Step(fmt.Sprintf(`^apply\(%s, %s\)$`, v, d), applyAnimation)
+
+	sc.Step(`^track\((.+)\) fails with "(.*)"$`, assertTrackFails)
+	sc.Step(fmt.Sprintf(`^([A-Z]+|[a-z]+\.transform) \* %s = point\(%s, %s, %s\)$`, v, d, d, d), assertTransformedPoint)
+	sc.Step(fmt.Sprintf(`^transform_at\(%s, %s\) \* %s = point\(%s, %s, %s\)$`, v, d, v, d, d, d), assertTransformedPointAt)
+}
+
+func TestFeatures(t *testing.T) {
+	suite := godog.TestSuite{
+		ScenarioInitializer: InitializeScenario,
+		Options: &godog.Options{
+			Format:   "pretty",
+			Paths:    []string{"features/animation.feature"},
+			TestingT: t,
+		},
+	}
+
+	if suite.Run() != 0 {
+		t.Fatal("non-zero exit status")
+	}
+}
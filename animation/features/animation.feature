@@ -0,0 +1,96 @@
+Feature: Keyframe animation
+
+Scenario: A track holds its first keyframe before it starts
+  Given A ← translation(0, 0, 0)
+    And B ← translation(10, 0, 0)
+    And track ← track(5: A, 15: B)
+    And p ← point(0, 0, 0)
+  When M ← at(track, 0)
+  Then M * p = point(0, 0, 0)
+
+Scenario: A track holds its last keyframe after it ends
+  Given A ← translation(0, 0, 0)
+    And B ← translation(10, 0, 0)
+    And track ← track(5: A, 15: B)
+    And p ← point(0, 0, 0)
+  When M ← at(track, 20)
+  Then M * p = point(10, 0, 0)
+
+Scenario: A track interpolates between keyframes
+  Given A ← translation(0, 0, 0)
+    And B ← translation(10, 0, 0)
+    And track ← track(5: A, 15: B)
+    And p ← point(0, 0, 0)
+  When M ← at(track, 7.5)
+  Then M * p = point(2.5, 0, 0)
+
+Scenario: Keyframes can be given in any order
+  Given A ← translation(0, 0, 0)
+    And B ← translation(10, 0, 0)
+    And C ← translation(10, 10, 0)
+    And track ← track(20: C, 0: A, 10: B)
+    And p ← point(0, 0, 0)
+  When M ← at(track, 15)
+  Then M * p = point(10, 5, 0)
+
+Scenario: Rotations are interpolated along an arc
+  Given A ← rotation_y(0)
+    And B ← rotation_y(1.5707963)
+    And track ← track(0: A, 10: B)
+    And p ← point(0, 0, 1)
+  When M ← at(track, 5)
+  Then M * p = point(0.70711, 0, 0.70711)
+
+Scenario: Scaling is interpolated
+  Given A ← scaling(1, 1, 1)
+    And B ← scaling(3, 1, 1)
+    And track ← track(0: A, 4: B)
+    And p ← point(1, 1, 1)
+  When M ← at(track, 1)
+  Then M * p = point(1.5, 1, 1)
+
+Scenario: Two keyframes cannot share a frame
+  Given A ← translation(0, 0, 0)
+    And B ← translation(10, 0, 0)
+  Then track(3: A, 3: B) fails with "two keyframes at frame 3"
+
+Scenario: Keyframes must be decomposable
+  Given A ← translation(0, 0, 0)
+    And B ← shearing(1, 0, 0, 0, 0, 0)
+  Then track(0: A, 1: B) fails with "keyframe at frame 1: matrix is not made of translation, rotation and scaling alone"
+
+Scenario: Applying an animation moves its targets
+  Given A ← translation(0, 0, 0)
+    And B ← translation(0, 4, 0)
+    And track ← track(0: A, 8: B)
+    And s ← sphere()
+    And anim ← animation()
+    And p ← point(0, 0, 0)
+  When add(anim, s, track)
+    And apply(anim, 2)
+  Then s.transform * p = point(0, 1, 0)
+
+Scenario: A moving shape moves over each frame towards the next
+  Given A ← translation(0, 0, 0)
+    And B ← translation(0, 4, 0)
+    And track ← track(0: A, 8: B)
+    And s ← sphere()
+    And anim ← animation()
+    And p ← point(0, 0, 0)
+  When add(anim, s, track)
+    And apply(anim, 2)
+  Then transform_at(s, 0) * p = point(0, 1, 0)
+    And transform_at(s, 0.5) * p = point(0, 1.25, 0)
+    And transform_at(s, 1) * p = point(0, 1.5, 0)
+
+Scenario: A shape that has stopped holds still over the frame
+  Given A ← translation(0, 0, 0)
+    And B ← translation(0, 4, 0)
+    And track ← track(0: A, 8: B)
+    And s ← sphere()
+    And anim ← animation()
+    And p ← point(0, 0, 0)
+  When add(anim, s, track)
+    And apply(anim, 8)
+  Then transform_at(s, 0) * p = point(0, 4, 0)
+    And transform_at(s, 1) * p = point(0, 4, 0)
@@ -0,0 +1,266 @@
+package camera
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"rtt/canvas"
+	"rtt/matrix"
+	"rtt/ray"
+	"rtt/tuple"
+	"rtt/world"
+	"sync"
+)
+
+type Camera struct {
+	HSize             int32
+	VSize             int32
+	FieldOfView       float64
+	PixelSize         float64
+	Seed              int64
+	halfWidth         float64
+	halfHeight        float64
+	sampling          Sampling
+	samples           int
+	aperture          float64
+	focalDistance     float64
+	shutterOpen       float64
+	shutterClose      float64
+	transformation    matrix.Mat4
+	transformationInv matrix.Mat4
+}
+
+func NewCamera(hsize, vsize int32, fieldOfView float64) *Camera {
+	c := &Camera{
+		HSize:             hsize,
+		VSize:             vsize,
+		FieldOfView:       fieldOfView,
+		sampling:          Grid,
+		samples:           1,
+		focalDistance:     1,
+		transformation:    matrix.Identity4,
+		transformationInv: matrix.Identity4,
+	}
+
+	halfView := math.Tan(fieldOfView / 2)
+	aspect := float64(hsize) / float64(vsize)
+
+	if aspect >= 1 {
+		c.halfWidth = halfView
+		c.halfHeight = halfView / aspect
+	} else {
+		c.halfWidth = halfView * aspect
+		c.halfHeight = halfView
+	}
+
+	c.PixelSize = (c.halfWidth * 2) / float64(hsize)
+
+	return c
+}
+
+func (c *Camera) SetTransform(transform matrix.Mat4) error {
+	inverse, err := transform.Invert()
+
+	if err != nil {
+		return err
+	}
+
+	c.transformation = transform
+	c.transformationInv = inverse
+	return nil
+}
+
+func (c *Camera) Transform() matrix.Mat4 {
+	return c.transformation
+}
+
+func (c *Camera) SetSampling(sampling Sampling, samples int) error {
+	if samples < 1 {
+		return fmt.Errorf("samples per pixel must be at least 1, found %d", samples)
+	}
+
+	if sampling != Jittered {
+		side := gridSide(samples)
+		if side*side != samples {
+			return fmt.Errorf("%s sampling needs a square number of samples per pixel, found %d", sampling, samples)
+		}
+	}
+
+	c.sampling = sampling
+	c.samples = samples
+	return nil
+}
+
+func (c *Camera) Sampling() (Sampling, int) {
+	return c.sampling, c.samples
+}
+
+// SetLens gives the camera a thin lens of diameter aperture, which brings
+// the world into focus at focalDistance in front of it, and blurs it more
+// the further in front of or behind that it is. An aperture of 0 makes the
+// camera a pinhole, with everything in focus.
+func (c *Camera) SetLens(aperture, focalDistance float64) error {
+	if aperture < 0 {
+		return fmt.Errorf("aperture must not be negative, found %g", aperture)
+	}
+	if focalDistance <= 0 {
+		return fmt.Errorf("focal distance must be positive, found %g", focalDistance)
+	}
+
+	c.aperture = aperture
+	c.focalDistance = focalDistance
+	return nil
+}
+
+func (c *Camera) Lens() (float64, float64) {
+	return c.aperture, c.focalDistance
+}
+
+// SetShutter opens the shutter at time open and closes it at time close,
+// within the frame that moving shapes move over from time 0 to time 1. Rays
+// are cast at times spread over when it is open, blurring what moves while
+// it is. A shutter that opens and closes at once stops motion at that time.
+func (c *Camera) SetShutter(open, close float64) error {
+	if open < 0 || close > 1 || open > close {
+		return fmt.Errorf("the shutter must open and then close between 0 and 1, found %g to %g", open, close)
+	}
+
+	c.shutterOpen = open
+	c.shutterClose = close
+	return nil
+}
+
+func (c *Camera) Shutter() (float64, float64) {
+	return c.shutterOpen, c.shutterClose
+}
+
+func (c *Camera) RayForPixel(px, py int32) *ray.Ray {
+	return c.RayForSample(px, py, 0.5, 0.5)
+}
+
+// RayForSample returns the ray through the point of the pixel that is dx of
+// its width from the left and dy of its height from the top.
+func (c *Camera) RayForSample(px, py int32, dx, dy float64) *ray.Ray {
+	return c.RayForLensSample(px, py, dx, dy, 0.5, 0.5)
+}
+
+// RayForLensSample returns the ray that leaves the lens at the point lu, lv
+// of the way across the square the lens is drawn from, and passes through
+// the point the sample dx, dy of the pixel is in focus at. With lu and lv
+// at 0.5 it leaves from the centre of the lens, like the ray of a pinhole.
+// The ray is cast when the shutter opens.
+func (c *Camera) RayForLensSample(px, py int32, dx, dy, lu, lv float64) *ray.Ray {
+	xOffset := (float64(px) + dx) * c.PixelSize
+	yOffset := (float64(py) + dy) * c.PixelSize
+
+	worldX := c.halfWidth - xOffset
+	worldY := c.halfHeight - yOffset
+
+	lensX, lensY := pointOnDisk(lu, lv)
+	radius := c.aperture / 2
+
+	lens := tuple.NewPoint(lensX*radius, lensY*radius, 0)
+	focus := tuple.NewPoint(worldX*c.focalDistance, worldY*c.focalDistance, -c.focalDistance)
+
+	origin := c.transformationInv.MultiplyPoint(lens)
+	direction := c.transformationInv.MultiplyPoint(focus).Subtract(origin).Normalize()
+
+	r := ray.NewRay(origin, direction)
+	r.Time = c.shutterOpen
+	return r
+}
+
+// pointOnDisk maps u and v in [0, 1) onto the unit disk, keeping points
+// that are spread evenly over the square spread evenly over the disk, and
+// neighbouring points neighbours.
+func pointOnDisk(u, v float64) (float64, float64) {
+	a, b := 2*u-1, 2*v-1
+	if a == 0 && b == 0 {
+		return 0, 0
+	}
+
+	var r, phi float64
+	if math.Abs(a) > math.Abs(b) {
+		r, phi = a, math.Pi/4*(b/a)
+	} else {
+		r, phi = b, math.Pi/2-math.Pi/4*(a/b)
+	}
+
+	return r * math.Cos(phi), r * math.Sin(phi)
+}
+
+func (c *Camera) Resize(hsize, vsize int32) *Camera {
+	resized := NewCamera(hsize, vsize, c.FieldOfView)
+	resized.transformation = c.transformation
+	resized.transformationInv = c.transformationInv
+	resized.sampling = c.sampling
+	resized.samples = c.samples
+	resized.aperture = c.aperture
+	resized.focalDistance = c.focalDistance
+	resized.shutterOpen = c.shutterOpen
+	resized.shutterClose = c.shutterClose
+	resized.Seed = c.Seed
+	return resized
+}
+
+func (c *Camera) Render(w *world.World) *canvas.Canvas {
+	return c.RenderWithWorkers(w, 1)
+}
+
+func (c *Camera) RenderWithWorkers(w *world.World, workers int) *canvas.Canvas {
+	image := canvas.NewCanvas(c.HSize, c.VSize)
+
+	if workers < 1 {
+		workers = 1
+	}
+
+	rows := make(chan int32)
+	var wg sync.WaitGroup
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for y := range rows {
+				// Each row samples from its own source, so the image does not
+				// depend on how the rows are shared out between the workers.
+				rng := rand.New(rand.NewSource(c.Seed*int64(c.VSize) + int64(y)))
+				for x := int32(0); x < c.HSize; x++ {
+					image.WritePixel(x, y, c.colorForPixel(w, x, y, rng))
+				}
+			}
+		}()
+	}
+
+	for y := int32(0); y < c.VSize; y++ {
+		rows <- y
+	}
+	close(rows)
+	wg.Wait()
+
+	return image
+}
+
+func (c *Camera) colorForPixel(w *world.World, px, py int32, rng *rand.Rand) tuple.Color {
+	moving := c.shutterClose > c.shutterOpen
+
+	if c.samples == 1 && c.sampling == Grid && c.aperture == 0 && !moving {
+		return w.Radiance(c.RayForPixel(px, py), rng)
+	}
+
+	offsets := c.sampling.offsets(c.samples, rng)
+	sum := tuple.Black
+
+	for _, o := range offsets {
+		r := c.RayForSample(px, py, o.x, o.y)
+		if c.aperture > 0 {
+			r = c.RayForLensSample(px, py, o.x, o.y, rng.Float64(), rng.Float64())
+		}
+		if moving {
+			r.Time += rng.Float64() * (c.shutterClose - c.shutterOpen)
+		}
+		sum = sum.Add(w.Radiance(r, rng))
+	}
+
+	return sum.ScalarDiv(float64(len(offsets)))
+}
@@ -0,0 +1,555 @@
+package camera
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"rtt/canvas"
+	"rtt/matrix"
+	"rtt/ray"
+	"rtt/shared"
+	"rtt/sharedtest"
+	"rtt/transformations"
+	"rtt/tuple"
+	"rtt/tupletest"
+	"rtt/world"
+	"strconv"
+	"testing"
+
+	"github.com/cucumber/godog"
+)
+
+func defaultWorld() *world.World {
+	w := world.NewWorld()
+	w.AddLight(ray.NewPointLight(tuple.NewPoint(-10, 10, -10), tuple.NewColor(1, 1, 1)))
+
+	s1 := ray.NewSphere()
+	s1.Material.Color = tuple.NewColor(0.8, 1.0, 0.6)
+	s1.Material.Diffuse = 0.7
+	s1.Material.Specular = 0.2
+	w.AddObject(s1)
+
+	s2 := ray.NewSphere()
+	s2.SetTransform(transformations.Scaling(0.5, 0.5, 0.5))
+	w.AddObject(s2)
+
+	return w
+}
+
+func getCamera(ctx context.Context, variable string) *Camera {
+	return ctx.Value(sharedtest.Variables{Name: variable}).(*Camera)
+}
+
+func anInteger(ctx context.Context, variable string, value int32) (context.Context, error) {
+	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, value), nil
+}
+
+func anAngle(ctx context.Context, variable string, divisor float64) (context.Context, error) {
+	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, math.Pi/divisor), nil
+}
+
+func aCameraFromVariables(ctx context.Context, variable, hsizeVariable, vsizeVariable, fovVariable string) (context.Context, error) {
+	hsize := ctx.Value(sharedtest.Variables{Name: hsizeVariable}).(int32)
+	vsize := ctx.Value(sharedtest.Variables{Name: vsizeVariable}).(int32)
+	fov := ctx.Value(sharedtest.Variables{Name: fovVariable}).(float64)
+
+	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, NewCamera(hsize, vsize, fov)), nil
+}
+
+func aCameraFromValues(ctx context.Context, variable string, hsize, vsize int32, divisor float64) (context.Context, error) {
+	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, NewCamera(hsize, vsize, math.Pi/divisor)), nil
+}
+
+func aRayForPixel(ctx context.Context, variable, cameraVariable string, x, y int32) (context.Context, error) {
+	c := getCamera(ctx, cameraVariable)
+	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, c.RayForPixel(x, y)), nil
+}
+
+func rotationTranslation(ctx context.Context, cameraVariable string, divisor, x, y, z float64) (context.Context, error) {
+	c := getCamera(ctx, cameraVariable)
+	m := transformations.RotationY(math.Pi / divisor).Multiply(transformations.Translation(x, y, z))
+	return ctx, c.SetTransform(m)
+}
+
+func aViewTransform(ctx context.Context, cameraVariable, fromVariable, toVariable, upVariable string) (context.Context, error) {
+	c := getCamera(ctx, cameraVariable)
+	from := ctx.Value(sharedtest.Variables{Name: fromVariable}).(tuple.Point)
+	to := ctx.Value(sharedtest.Variables{Name: toVariable}).(tuple.Point)
+	up := ctx.Value(sharedtest.Variables{Name: upVariable}).(tuple.Vector)
+	return ctx, c.SetTransform(transformations.ViewTransform(from, to, up))
+}
+
+func aDefaultWorld(ctx context.Context, variable string) (context.Context, error) {
+	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, defaultWorld()), nil
+}
+
+func aRender(ctx context.Context, variable, cameraVariable, worldVariable string) (context.Context, error) {
+	c := getCamera(ctx, cameraVariable)
+	w := ctx.Value(sharedtest.Variables{Name: worldVariable}).(*world.World)
+	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, c.Render(w)), nil
+}
+
+func aParallelRender(ctx context.Context, variable, cameraVariable, worldVariable string, workers int) (context.Context, error) {
+	c := getCamera(ctx, cameraVariable)
+	w := ctx.Value(sharedtest.Variables{Name: worldVariable}).(*world.World)
+	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, c.RenderWithWorkers(w, workers)), nil
+}
+
+func aJitteredLight(ctx context.Context, worldVariable, kind string) (context.Context, error) {
+	w := ctx.Value(sharedtest.Variables{Name: worldVariable}).(*world.World)
+
+	switch kind {
+	case "area":
+		corner := tuple.NewPoint(-11, 9, -11)
+		w.Lights = []ray.Light{ray.NewAreaLight(corner, tuple.NewVector(2, 0, 0), 4, tuple.NewVector(0, 2, 0), 4, tuple.NewColor(1, 1, 1))}
+	case "shape":
+		bulb := ray.NewSphere()
+		if err := bulb.SetTransform(transformations.Translation(-10, 10, -10)); err != nil {
+			return ctx, err
+		}
+		w.Lights = []ray.Light{ray.NewShapeLight(bulb, tuple.NewColor(50, 50, 50), 4, 4)}
+	case "environment":
+		sky := canvas.NewCanvas(8, 4)
+		for y := int32(0); y < 4; y++ {
+			for x := int32(0); x < 8; x++ {
+				sky.WritePixel(x, y, tuple.NewColor(float64(x+1)/8, 0.5, float64(y+1)/4))
+			}
+		}
+		w.Lights = []ray.Light{world.NewEnvironmentLight(world.NewEnvironmentMap(sky), 4, 4)}
+	}
+	return ctx, nil
+}
+
+func aResizedCamera(ctx context.Context, variable, cameraVariable string, hsize, vsize int32) (context.Context, error) {
+	c := getCamera(ctx, cameraVariable)
+	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, c.Resize(hsize, vsize)), nil
+}
+
+type samplingResult struct{}
+
+type lensResult struct{}
+
+type shutterResult struct{}
+
+func aRayForSample(ctx context.Context, variable, cameraVariable string, x, y int32, dx, dy float64) (context.Context, error) {
+	c := getCamera(ctx, cameraVariable)
+	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, c.RayForSample(x, y, dx, dy)), nil
+}
+
+func aRayForLensSample(ctx context.Context, variable, cameraVariable string, x, y int32, dx, dy, lu, lv float64) (context.Context, error) {
+	c := getCamera(ctx, cameraVariable)
+	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, c.RayForLensSample(x, y, dx, dy, lu, lv)), nil
+}
+
+func aLens(ctx context.Context, cameraVariable string, aperture, focalDistance float64) (context.Context, error) {
+	return ctx, getCamera(ctx, cameraVariable).SetLens(aperture, focalDistance)
+}
+
+func setLens(ctx context.Context, cameraVariable string, aperture, focalDistance float64) (context.Context, error) {
+	err := getCamera(ctx, cameraVariable).SetLens(aperture, focalDistance)
+	return context.WithValue(ctx, lensResult{}, &err), nil
+}
+
+func aShutter(ctx context.Context, cameraVariable string, open, close float64) (context.Context, error) {
+	return ctx, getCamera(ctx, cameraVariable).SetShutter(open, close)
+}
+
+func setShutter(ctx context.Context, cameraVariable string, open, close float64) (context.Context, error) {
+	err := getCamera(ctx, cameraVariable).SetShutter(open, close)
+	return context.WithValue(ctx, shutterResult{}, &err), nil
+}
+
+// everyObjectMoves sets every object of the world moving by the translation
+// from where it is at time 0 to where it is at time 1.
+func everyObjectMoves(ctx context.Context, worldVariable string, x, y, z float64) (context.Context, error) {
+	w := ctx.Value(sharedtest.Variables{Name: worldVariable}).(*world.World)
+
+	for _, o := range w.Objects {
+		start := o.TransformAt(0)
+		if err := o.SetMotion(start, transformations.Translation(x, y, z).Multiply(start)); err != nil {
+			return ctx, err
+		}
+	}
+	return ctx, nil
+}
+
+func someSampleOffsets(ctx context.Context, variable, name string, samples int) (context.Context, error) {
+	sampling, err := ParseSampling(name)
+	if err != nil {
+		return ctx, err
+	}
+	offsets := sampling.offsets(samples, rand.New(rand.NewSource(1)))
+	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, offsets), nil
+}
+
+func setSampling(ctx context.Context, cameraVariable, name string, samples int) (context.Context, error) {
+	c := getCamera(ctx, cameraVariable)
+	sampling, err := ParseSampling(name)
+	if err != nil {
+		return ctx, err
+	}
+	err = c.SetSampling(sampling, samples)
+	return context.WithValue(ctx, samplingResult{}, &err), nil
+}
+
+func aSampling(ctx context.Context, cameraVariable, name string, samples int) (context.Context, error) {
+	c := getCamera(ctx, cameraVariable)
+	sampling, err := ParseSampling(name)
+	if err != nil {
+		return ctx, err
+	}
+	return ctx, c.SetSampling(sampling, samples)
+}
+
+func aSeed(ctx context.Context, cameraVariable string, seed int64) (context.Context, error) {
+	getCamera(ctx, cameraVariable).Seed = seed
+	return ctx, nil
+}
+
+func assertSampling(ctx context.Context, variable, expected string) error {
+	sampling, _ := getCamera(ctx, variable).Sampling()
+	if sampling.String() != expected {
+		return fmt.Errorf("Error sampling %s != %s!", sampling, expected)
+	}
+	return nil
+}
+
+func assertSamples(ctx context.Context, variable string, expected int) error {
+	_, samples := getCamera(ctx, variable).Sampling()
+	if samples != expected {
+		return fmt.Errorf("Error samples %d != %d!", samples, expected)
+	}
+	return nil
+}
+
+func assertOffsetCount(ctx context.Context, variable string, expected int) error {
+	offsets := ctx.Value(sharedtest.Variables{Name: variable}).([]offset)
+	if len(offsets) != expected {
+		return fmt.Errorf("Error count %d != %d!", len(offsets), expected)
+	}
+	return nil
+}
+
+func assertOffset(ctx context.Context, variable string, index int, x, y float64) error {
+	o := ctx.Value(sharedtest.Variables{Name: variable}).([]offset)[index]
+	if !shared.CompareFloat(o.x, x) || !shared.CompareFloat(o.y, y) {
+		return fmt.Errorf("Error offset %d is (%f, %f) not (%f, %f)!", index, o.x, o.y, x, y)
+	}
+	return nil
+}
+
+func assertOffsetsInCells(ctx context.Context, variable string, cols, rows int) error {
+	offsets := ctx.Value(sharedtest.Variables{Name: variable}).([]offset)
+	width, height := 1/float64(cols), 1/float64(rows)
+
+	for i, o := range offsets {
+		col, row := i%cols, (i/cols)%rows
+
+		if o.x < float64(col)*width || o.x >= float64(col+1)*width || o.y < float64(row)*height || o.y >= float64(row+1)*height {
+			return fmt.Errorf("Error offset %d (%f, %f) is outside cell %d, %d!", i, o.x, o.y, col, row)
+		}
+	}
+	return nil
+}
+
+func assertLens(ctx context.Context, cameraVariable, component string, expected float64) error {
+	aperture, focalDistance := getCamera(ctx, cameraVariable).Lens()
+
+	actual := aperture
+	if component == "focal_distance" {
+		actual = focalDistance
+	}
+
+	if !shared.CompareFloat(actual, expected) {
+		return fmt.Errorf("Error %s %g != %g!", component, actual, expected)
+	}
+	return nil
+}
+
+func assertLensFails(ctx context.Context, expected string) error {
+	err := *ctx.Value(lensResult{}).(*error)
+	if err == nil {
+		return fmt.Errorf("setting the lens succeeded")
+	}
+	if err.Error() != expected {
+		return fmt.Errorf("Error %q != %q!", err.Error(), expected)
+	}
+	return nil
+}
+
+func assertShutter(ctx context.Context, cameraVariable, component string, expected float64) error {
+	open, close := getCamera(ctx, cameraVariable).Shutter()
+
+	actual := open
+	if component == "shutter_close" {
+		actual = close
+	}
+
+	if !shared.CompareFloat(actual, expected) {
+		return fmt.Errorf("Error %s %g != %g!", component, actual, expected)
+	}
+	return nil
+}
+
+func assertShutterFails(ctx context.Context, expected string) error {
+	err := *ctx.Value(shutterResult{}).(*error)
+	if err == nil {
+		return fmt.Errorf("setting the shutter succeeded")
+	}
+	if err.Error() != expected {
+		return fmt.Errorf("Error %q != %q!", err.Error(), expected)
+	}
+	return nil
+}
+
+func assertRayTime(ctx context.Context, variable string, expected float64) error {
+	r := ctx.Value(sharedtest.Variables{Name: variable}).(*ray.Ray)
+	if !shared.CompareFloat(r.Time, expected) {
+		return fmt.Errorf("Error time %g != %g!", r.Time, expected)
+	}
+	return nil
+}
+
+// assertRayPassesThrough checks that the point lies ahead of the ray, along
+// its direction from its origin.
+func assertRayPassesThrough(ctx context.Context, variable, xs, ys, zs string) error {
+	r := ctx.Value(sharedtest.Variables{Name: variable}).(*ray.Ray)
+	x, y, z, err := sharedtest.ParseXYZ(xs, ys, zs)
+	if err != nil {
+		return err
+	}
+
+	towards := tuple.NewPoint(x, y, z).Subtract(r.Origin).Normalize()
+	if !towards.Equals(r.Direction) {
+		return fmt.Errorf("Error ray %+v does not pass through point(%g, %g, %g)!", r, x, y, z)
+	}
+	return nil
+}
+
+func assertSamplingSucceeds(ctx context.Context) error {
+	if err := *ctx.Value(samplingResult{}).(*error); err != nil {
+		return fmt.Errorf("setting the sampling failed: %s", err)
+	}
+	return nil
+}
+
+func assertSamplingFails(ctx context.Context, expected string) error {
+	err := *ctx.Value(samplingResult{}).(*error)
+	if err == nil {
+		return fmt.Errorf("setting the sampling succeeded")
+	}
+	if err.Error() != expected {
+		return fmt.Errorf("Error %q != %q!", err.Error(), expected)
+	}
+	return nil
+}
+
+func assertPixelAverage(ctx context.Context, variable string, x, y int32, px, py int32, cameraVariable, worldVariable string) error {
+	image := ctx.Value(sharedtest.Variables{Name: variable}).(*canvas.Canvas)
+	c := getCamera(ctx, cameraVariable)
+	w := ctx.Value(sharedtest.Variables{Name: worldVariable}).(*world.World)
+
+	expected := tuple.Black
+	for _, dy := range []float64{0.25, 0.75} {
+		for _, dx := range []float64{0.25, 0.75} {
+			expected = expected.Add(w.ColorAt(c.RayForSample(px, py, dx, dy)))
+		}
+	}
+	expected = expected.ScalarDiv(4)
+
+	actual := image.PixelAt(x, y)
+	if !actual.Equals(expected) {
+		return fmt.Errorf("Error %+v != %+v!", actual, expected)
+	}
+	return nil
+}
+
+func assertDifferentImage(ctx context.Context, aVariable, bVariable string) error {
+	if assertSameImage(ctx, aVariable, bVariable) == nil {
+		return fmt.Errorf("Error %s and %s are the same!", aVariable, bVariable)
+	}
+	return nil
+}
+
+func assertSize(ctx context.Context, variable, component string, expected int32) error {
+	c := getCamera(ctx, variable)
+
+	actual := c.HSize
+	if component == "vsize" {
+		actual = c.VSize
+	}
+
+	if actual != expected {
+		return fmt.Errorf("Error %s %d != %d!", component, actual, expected)
+	}
+	return nil
+}
+
+func assertFieldOfView(ctx context.Context, variable string, divisor float64) error {
+	c := getCamera(ctx, variable)
+	if !shared.CompareFloat(c.FieldOfView, math.Pi/divisor) {
+		return fmt.Errorf("Error %f != %f!", c.FieldOfView, math.Pi/divisor)
+	}
+	return nil
+}
+
+func assertIdentityTransform(ctx context.Context, variable string) error {
+	c := getCamera(ctx, variable)
+	if !c.Transform().Equals(matrix.Identity4) {
+		return fmt.Errorf("Error %+v is not the identity!", c.Transform())
+	}
+	return nil
+}
+
+func assertPixelSize(ctx context.Context, variable string, expected float64) error {
+	c := getCamera(ctx, variable)
+	if !shared.CompareFloat(c.PixelSize, expected) {
+		return fmt.Errorf("Error %f != %f!", c.PixelSize, expected)
+	}
+	return nil
+}
+
+func assertRayOrigin(ctx context.Context, variable, xs, ys, zs string) error {
+	r := ctx.Value(sharedtest.Variables{Name: variable}).(*ray.Ray)
+	x, y, z, err := sharedtest.ParseXYZ(xs, ys, zs)
+	if err != nil {
+		return err
+	}
+
+	expected := tuple.NewPoint(x, y, z)
+	if !r.Origin.Equals(expected) {
+		return fmt.Errorf("Error %+v != %+v!", r.Origin, expected)
+	}
+	return nil
+}
+
+func assertRayDirection(ctx context.Context, variable, xs, ys, zs string) error {
+	r := ctx.Value(sharedtest.Variables{Name: variable}).(*ray.Ray)
+	x, y, z, err := sharedtest.ParseXYZ(xs, ys, zs)
+	if err != nil {
+		return err
+	}
+
+	expected := tuple.NewVector(x, y, z)
+	if !r.Direction.Equals(expected) {
+		return fmt.Errorf("Error %+v != %+v!", r.Direction, expected)
+	}
+	return nil
+}
+
+func assertPixelAt(ctx context.Context, variable string, x, y int32, r, g, b float64, tolerance string) error {
+	image := ctx.Value(sharedtest.Variables{Name: variable}).(*canvas.Canvas)
+	expected := tuple.NewColor(r, g, b)
+	actual := image.PixelAt(x, y)
+
+	if tolerance != "" {
+		t, err := strconv.ParseFloat(tolerance, 64)
+		if err != nil {
+			return err
+		}
+		if !tuple.CompareTupleWithin(actual.Tuple(), expected.Tuple(), shared.Tolerance{Absolute: t}) {
+			return fmt.Errorf("Error %+v is not within %g of %+v!", actual, t, expected)
+		}
+		return nil
+	}
+
+	if !actual.Equals(expected) {
+		return fmt.Errorf("Error %+v != %+v!", actual, expected)
+	}
+	return nil
+}
+
+func assertSameImage(ctx context.Context, aVariable, bVariable string) error {
+	a := ctx.Value(sharedtest.Variables{Name: aVariable}).(*canvas.Canvas)
+	b := ctx.Value(sharedtest.Variables{Name: bVariable}).(*canvas.Canvas)
+
+	for i := range a.Pixels {
+		if !a.Pixels[i].Equals(b.Pixels[i]) {
+			return fmt.Errorf("Error pixel %d %+v != %+v!", i, a.Pixels[i], b.Pixels[i])
+		}
+	}
+	return nil
+}
+
+func constructors(sc *godog.ScenarioContext) {
+	v := `([a-z_]+)`
+	d := sharedtest.Decimal
+	n := sharedtest.PosInt
+
+	tupletest.AddConstructPoint(sc)
+	tupletest.AddConstructVector(sc)
+	sc.Step(fmt.Sprintf(`^%s ← %s$`, v, n), anInteger)
+	sc.Step(fmt.Sprintf(`^%s ← π/%s$`, v, n), anAngle)
+	sc.Step(fmt.Sprintf(`^%s ← camera\(%s, %s, %s\)$`, v, v, v, v), aCameraFromVariables)
+	sc.Step(fmt.Sprintf(`^%s ← camera\(%s, %s, π/%s\)$`, v, n, n, n), aCameraFromValues)
+	sc.Step(fmt.Sprintf(`^%s ← ray_for_pixel\(%s, %s, %s\)$`, v, v, n, n), aRayForPixel)
+	sc.Step(fmt.Sprintf(`^%s.transform ← rotation_y\(π/%s\) \* translation\(%s, %s, %s\)$`, v, n, d, d, d), rotationTranslation)
+	sc.Step(fmt.Sprintf(`^%s.transform ← view_transform\(%s, %s, %s\)$`, v, v, v, v), aViewTransform)
+	sc.Step(fmt.Sprintf(`^%s ← default_world\(\)$`, v), aDefaultWorld)
+	sc.Step(fmt.Sprintf(`^%s ← render\(%s, %s\)$`, v, v, v), aRender)
+	sc.Step(fmt.Sprintf(`^%s ← render\(%s, %s, %s\)$`, v, v, v, n), aParallelRender)
+	sc.Step(fmt.Sprintf(`^%s is lit by a jittered (area|shape|environment) light$`, v), aJitteredLight)
+	sc.Step(fmt.Sprintf(`^%s ← ray_for_sample\(%s, %s, %s, %s, %s\)$`, v, v, n, n, d, d), aRayForSample)
+	sc.Step(fmt.Sprintf(`^%s ← sample_offsets\(%s, %s\)$`, v, v, n), someSampleOffsets)
+	sc.Step(fmt.Sprintf(`^setting the sampling of %s to %s with %s samples$`, v, v, n), setSampling)
+	sc.Step(fmt.Sprintf(`^the sampling of %s is %s with %s samples$`, v, v, n), aSampling)
+	sc.Step(fmt.Sprintf(`^%s.seed ← %s$`, v, n), aSeed)
+	sc.Step(fmt.Sprintf(`^%s ← ray_for_lens_sample\(%s, %s, %s, %s, %s, %s, %s\)$`, v, v, n, n, d, d, d, d), aRayForLensSample)
+	sc.Step(fmt.Sprintf(`^%s has a lens with aperture %s focused at %s$`, v, d, d), aLens)
+	sc.Step(fmt.Sprintf(`^setting the lens of %s to aperture %s focused at %s$`, v, d, d), setLens)
+	sc.Step(fmt.Sprintf(`^%s has a shutter open from %s to %s$`, v, d, d), aShutter)
+	sc.Step(fmt.Sprintf(`^setting the shutter of %s to open from %s to %s$`, v, d, d), setShutter)
+	sc.Step(fmt.Sprintf(`^every object of %s moves by translation\(%s, %s, %s\)$`, v, d, d, d), everyObjectMoves)
+	sc.Step(fmt.Sprintf(`^%s ← resize\(%s, %s, %s\)$`, v, v, n, n), aResizedCamera)
+}
+
+func assertions(sc *godog.ScenarioContext) {
+	v := `([a-z_]+)`
+	d := sharedtest.Decimal
+	n := sharedtest.PosInt
+
+	sc.Step(fmt.Sprintf(`^%s.(hsize|vsize) = %s$`, v, n), assertSize)
+	sc.Step(fmt.Sprintf(`^%s.field_of_view = π/%s$`, v, n), assertFieldOfView)
+	sc.Step(fmt.Sprintf(`^%s.transform = identity_matrix$`, v), assertIdentityTransform)
+	sc.Step(fmt.Sprintf(`^%s.pixel_size = %s$`, v, d), assertPixelSize)
+	sc.Step(fmt.Sprintf(`^%s.origin = point\(%s, %s, %s\)$`, v, d, d, d), assertRayOrigin)
+	sc.Step(fmt.Sprintf(`^%s.direction = vector\(%s, %s, %s\)$`, v, d, d, d), assertRayDirection)
+	sc.Step(fmt.Sprintf(`^pixel_at\(%s, %s, %s\) = color\(%s, %s, %s\)(?: within ([0-9\.]+))?$`, v, n, n, d, d, d), assertPixelAt)
+	sc.Step(fmt.Sprintf(`^%s.(aperture|focal_distance) = %s$`, v, d), assertLens)
+	sc.Step(`^setting the lens fails with "(.*)"$`, assertLensFails)
+	sc.Step(fmt.Sprintf(`^%s.(shutter_open|shutter_close) = %s$`, v, d), assertShutter)
+	sc.Step(`^setting the shutter fails with "(.*)"$`, assertShutterFails)
+	sc.Step(fmt.Sprintf(`^%s.time = %s$`, v, d), assertRayTime)
+	sc.Step(fmt.Sprintf(`^%s passes through point\(%s, %s, %s\)$`, v, d, d, d), assertRayPassesThrough)
+	sc.Step(fmt.Sprintf(`^%s = %s$`, v, v), assertSameImage)
+	sc.Step(fmt.Sprintf(`^%s ≠ %s$`, v, v), assertDifferentImage)
+	sc.Step(fmt.Sprintf(`^%s.sampling = %s$`, v, v), assertSampling)
+	sc.Step(fmt.Sprintf(`^%s.samples = %s$`, v, n), assertSamples)
+	sc.Step(fmt.Sprintf(`^%s.count = %s$`, v, n), assertOffsetCount)
+	sc.Step(fmt.Sprintf(`^%s\[%s\] = \(%s, %s\)$`, v, n, d, d), assertOffset)
+	sc.Step(fmt.Sprintf(`^every sample in %s lies within its cell of a %sx%s grid$`, v, n, n), assertOffsetsInCells)
+	sc.Step(`^setting the sampling succeeds$`, assertSamplingSucceeds)
+	sc.Step(`^setting the sampling fails with "(.*)"$`, assertSamplingFails)
+	sc.Step(fmt.Sprintf(`^pixel_at\(%s, %s, %s\) = the average of the samples of pixel %s, %s of %s in %s$`, v, n, n, n, n, v, v), assertPixelAverage)
+}
+
+func initializeScenario(sc *godog.ScenarioContext) {
+	constructors(sc)
+	assertions(sc)
+}
+
+func TestFeatures(t *testing.T) {
+	suite := godog.TestSuite{
+		ScenarioInitializer: initializeScenario,
+		Options: &godog.Options{
+			Format:   "pretty",
+			Paths:    []string{"features/camera.feature"},
+			TestingT: t,
+		},
+	}
+
+	if suite.Run() != 0 {
+		t.Fatal("non-zero exit status")
+	}
+}
@@ -0,0 +1,297 @@
+Feature: Camera
+
+Scenario: Constructing a camera
+  Given hsize ← 160
+    And vsize ← 120
+    And field_of_view ← π/2
+  When c ← camera(hsize, vsize, field_of_view)
+  Then c.hsize = 160
+    And c.vsize = 120
+    And c.field_of_view = π/2
+    And c.transform = identity_matrix
+
+Scenario: The pixel size for a horizontal canvas
+  Given c ← camera(200, 125, π/2)
+  Then c.pixel_size = 0.01
+
+Scenario: The pixel size for a vertical canvas
+  Given c ← camera(125, 200, π/2)
+  Then c.pixel_size = 0.01
+
+Scenario: Constructing a ray through the center of the canvas
+  Given c ← camera(201, 101, π/2)
+  When r ← ray_for_pixel(c, 100, 50)
+  Then r.origin = point(0, 0, 0)
+    And r.direction = vector(0, 0, -1)
+
+Scenario: Constructing a ray through a corner of the canvas
+  Given c ← camera(201, 101, π/2)
+  When r ← ray_for_pixel(c, 0, 0)
+  Then r.origin = point(0, 0, 0)
+    And r.direction = vector(0.66519, 0.33259, -0.66851)
+
+Scenario: Constructing a ray when the camera is transformed
+  Given c ← camera(201, 101, π/2)
+  When c.transform ← rotation_y(π/4) * translation(0, -2, 5)
+    And r ← ray_for_pixel(c, 100, 50)
+  Then r.origin = point(0, 2, -5)
+    And r.direction = vector(√2/2, 0, -√2/2)
+
+Scenario: Rendering a world with a camera
+  Given w ← default_world()
+    And c ← camera(11, 11, π/2)
+    And from ← point(0, 0, -5)
+    And to ← point(0, 0, 0)
+    And up ← vector(0, 1, 0)
+    And c.transform ← view_transform(from, to, up)
+  When image ← render(c, w)
+  Then pixel_at(image, 5, 5) = color(0.38066, 0.47583, 0.2855)
+
+Scenario: Rendering with several workers matches a single worker
+  Given w ← default_world()
+    And c ← camera(11, 11, π/2)
+    And from ← point(0, 0, -5)
+    And to ← point(0, 0, 0)
+    And up ← vector(0, 1, 0)
+    And c.transform ← view_transform(from, to, up)
+  When image ← render(c, w)
+    And parallel ← render(c, w, 4)
+  Then image = parallel
+
+Scenario: Resizing a camera keeps its orientation
+  Given c ← camera(201, 101, π/2)
+    And c.transform ← rotation_y(π/4) * translation(0, -2, 5)
+  When resized ← resize(c, 403, 203)
+    And r ← ray_for_pixel(resized, 201, 101)
+  Then resized.hsize = 403
+    And resized.vsize = 203
+    And r.origin = point(0, 2, -5)
+    And r.direction = vector(√2/2, 0, -√2/2)
+
+Scenario: A camera takes a single sample through the centre of each pixel by default
+  Given c ← camera(160, 120, π/2)
+  Then c.sampling = grid
+    And c.samples = 1
+
+Scenario: Constructing a ray through the centre of a pixel sample
+  Given c ← camera(201, 101, π/2)
+  When r ← ray_for_sample(c, 100, 50, 0.5, 0.5)
+  Then r.origin = point(0, 0, 0)
+    And r.direction = vector(0, 0, -1)
+
+Scenario: Constructing a ray through the corner of a pixel
+  Given c ← camera(201, 101, π/2)
+  When r ← ray_for_sample(c, 0, 0, 0, 0)
+  Then r.origin = point(0, 0, 0)
+    And r.direction = vector(0.66630, 0.33481, -0.66630)
+
+Scenario: Grid sampling takes the centres of the cells
+  When offsets ← sample_offsets(grid, 4)
+  Then offsets.count = 4
+    And offsets[0] = (0.25, 0.25)
+    And offsets[1] = (0.75, 0.25)
+    And offsets[2] = (0.25, 0.75)
+    And offsets[3] = (0.75, 0.75)
+
+Scenario: Stratified sampling takes one sample within each cell
+  When offsets ← sample_offsets(stratified, 9)
+  Then offsets.count = 9
+    And every sample in offsets lies within its cell of a 3x3 grid
+
+Scenario: Jittered sampling takes samples anywhere in the pixel
+  When offsets ← sample_offsets(jittered, 5)
+  Then offsets.count = 5
+    And every sample in offsets lies within its cell of a 1x1 grid
+
+Scenario Outline: Grid and stratified sampling need a square number of samples
+  Given c ← camera(160, 120, π/2)
+  When setting the sampling of c to <sampling> with <samples> samples
+  Then setting the sampling fails with "<error>"
+
+  Examples:
+    | sampling   | samples | error                                                                   |
+    | grid       | 3       | grid sampling needs a square number of samples per pixel, found 3       |
+    | stratified | 8       | stratified sampling needs a square number of samples per pixel, found 8 |
+    | jittered   | 0       | samples per pixel must be at least 1, found 0                           |
+
+Scenario: Jittered sampling accepts any number of samples
+  Given c ← camera(160, 120, π/2)
+  When setting the sampling of c to jittered with 3 samples
+  Then setting the sampling succeeds
+    And c.sampling = jittered
+    And c.samples = 3
+
+Scenario: Supersampling averages the samples taken in a pixel
+  Given w ← default_world()
+    And c ← camera(11, 11, π/2)
+    And from ← point(0, 0, -5)
+    And to ← point(0, 0, 0)
+    And up ← vector(0, 1, 0)
+    And c.transform ← view_transform(from, to, up)
+    And the sampling of c is grid with 4 samples
+  When image ← render(c, w)
+  Then pixel_at(image, 5, 5) = the average of the samples of pixel 5, 5 of c in w
+    And pixel_at(image, 2, 2) = the average of the samples of pixel 2, 2 of c in w
+
+Scenario: Jittered renders are reproducible for a seed
+  Given w ← default_world()
+    And c ← camera(11, 11, π/2)
+    And from ← point(0, 0, -5)
+    And to ← point(0, 0, 0)
+    And up ← vector(0, 1, 0)
+    And c.transform ← view_transform(from, to, up)
+    And the sampling of c is stratified with 4 samples
+    And c.seed ← 7
+  When first ← render(c, w, 1)
+    And second ← render(c, w, 4)
+  Then first = second
+
+Scenario Outline: Renders with a jittered light are reproducible for a seed
+  Given w ← default_world()
+    And w is lit by a jittered <kind> light
+    And c ← camera(11, 11, π/2)
+    And from ← point(0, 0, -5)
+    And to ← point(0, 0, 0)
+    And up ← vector(0, 1, 0)
+    And c.transform ← view_transform(from, to, up)
+    And c.seed ← 7
+  When first ← render(c, w, 1)
+    And second ← render(c, w, 4)
+  Then first = second
+
+  Examples:
+    | kind        |
+    | area        |
+    | shape       |
+    | environment |
+
+Scenario: Jittered renders differ between seeds
+  Given w ← default_world()
+    And c ← camera(11, 11, π/2)
+    And from ← point(0, 0, -5)
+    And to ← point(0, 0, 0)
+    And up ← vector(0, 1, 0)
+    And c.transform ← view_transform(from, to, up)
+    And the sampling of c is jittered with 4 samples
+    And c.seed ← 7
+  When first ← render(c, w)
+    And c.seed ← 8
+    And second ← render(c, w)
+  Then first ≠ second
+
+Scenario: A camera is a pinhole by default
+  Given c ← camera(160, 120, π/2)
+  Then c.aperture = 0
+    And c.focal_distance = 1
+
+Scenario: A ray through the centre of the lens is the ray of a pinhole
+  Given c ← camera(201, 101, π/2)
+    And c has a lens with aperture 0.5 focused at 10
+  When r ← ray_for_lens_sample(c, 100, 50, 0.5, 0.5, 0.5, 0.5)
+  Then r.origin = point(0, 0, 0)
+    And r.direction = vector(0, 0, -1)
+
+Scenario Outline: Rays from across the lens meet where the pixel is in focus
+  Given c ← camera(201, 101, π/2)
+    And c has a lens with aperture 0.5 focused at 10
+  When r ← ray_for_lens_sample(c, 0, 0, 0.5, 0.5, <lu>, <lv>)
+  Then r.origin = point(<x>, <y>, 0)
+    And r passes through point(9.95025, 4.97512, -10)
+
+  Examples:
+    | lu  | lv  | x        | y        |
+    | 0.5 | 0.5 | 0        | 0        |
+    | 1   | 0.5 | 0.25     | 0        |
+    | 0.5 | 1   | 0        | 0.25     |
+    | 0   | 0   | -0.17678 | -0.17678 |
+
+Scenario: The lens moves with the camera
+  Given c ← camera(201, 101, π/2)
+    And c has a lens with aperture 0.5 focused at 10
+  When c.transform ← rotation_y(π/4) * translation(0, -2, 5)
+    And r ← ray_for_lens_sample(c, 100, 50, 0.5, 0.5, 1, 0.5)
+  Then r.origin = point(0.17678, 2, -4.82322)
+    And r passes through point(7.07107, 2, -12.07107)
+
+Scenario Outline: A lens needs a focal distance in front of it
+  Given c ← camera(160, 120, π/2)
+  When setting the lens of c to aperture <aperture> focused at <distance>
+  Then setting the lens fails with "<error>"
+
+  Examples:
+    | aperture | distance | error                                     |
+    | -1       | 1        | aperture must not be negative, found -1   |
+    | 0.5      | 0        | focal distance must be positive, found 0  |
+    | 0.5      | -2       | focal distance must be positive, found -2 |
+
+Scenario: Resizing a camera keeps its lens
+  Given c ← camera(160, 120, π/2)
+    And c has a lens with aperture 0.5 focused at 10
+  When resized ← resize(c, 320, 240)
+  Then resized.aperture = 0.5
+    And resized.focal_distance = 10
+
+Scenario: A lens blurs what is out of focus
+  Given w ← default_world()
+    And c ← camera(11, 11, π/2)
+    And from ← point(0, 0, -5)
+    And to ← point(0, 0, 0)
+    And up ← vector(0, 1, 0)
+    And c.transform ← view_transform(from, to, up)
+    And the sampling of c is stratified with 64 samples
+  When pinhole ← render(c, w)
+    And c has a lens with aperture 0.2 focused at 4
+    And focused ← render(c, w)
+    And c has a lens with aperture 2 focused at 100
+    And blurred ← render(c, w)
+  Then pixel_at(pinhole, 5, 5) = color(0.36981, 0.46132, 0.27829)
+    And pixel_at(focused, 5, 5) = color(0.36981, 0.46132, 0.27829) within 0.01
+    And pixel_at(pinhole, 3, 5) = color(0, 0, 0)
+    And pixel_at(focused, 3, 5) = color(0, 0, 0)
+    And pixel_at(blurred, 3, 5) = color(0.05, 0.06, 0.04) within 0.01
+
+Scenario: A camera's shutter is open for an instant at time 0 by default
+  Given c ← camera(160, 120, π/2)
+  When r ← ray_for_pixel(c, 80, 60)
+  Then c.shutter_open = 0
+    And c.shutter_close = 0
+    And r.time = 0
+
+Scenario: Rays are cast when the shutter opens
+  Given c ← camera(201, 101, π/2)
+    And c has a shutter open from 0.25 to 0.75
+  When r ← ray_for_pixel(c, 100, 50)
+  Then r.time = 0.25
+
+Scenario Outline: The shutter opens and closes within the frame
+  Given c ← camera(160, 120, π/2)
+  When setting the shutter of c to open from <open> to <close>
+  Then setting the shutter fails with "<error>"
+
+  Examples:
+    | open | close | error                                                                    |
+    | -1   | 0.5   | the shutter must open and then close between 0 and 1, found -1 to 0.5    |
+    | 0.5  | 2     | the shutter must open and then close between 0 and 1, found 0.5 to 2     |
+    | 0.75 | 0.25  | the shutter must open and then close between 0 and 1, found 0.75 to 0.25 |
+
+Scenario: Resizing a camera keeps its shutter
+  Given c ← camera(160, 120, π/2)
+    And c has a shutter open from 0.25 to 0.75
+  When resized ← resize(c, 320, 240)
+  Then resized.shutter_open = 0.25
+    And resized.shutter_close = 0.75
+
+Scenario: Motion blurs what moves while the shutter is open
+  Given w ← default_world()
+    And c ← camera(11, 11, π/2)
+    And from ← point(0, 0, -5)
+    And to ← point(0, 0, 0)
+    And up ← vector(0, 1, 0)
+    And c.transform ← view_transform(from, to, up)
+    And the sampling of c is stratified with 64 samples
+    And every object of w moves by translation(-2, 0, 0)
+  When still ← render(c, w)
+    And c has a shutter open from 0 to 1
+    And blurred ← render(c, w)
+  Then pixel_at(still, 3, 5) = color(0, 0, 0)
+    And pixel_at(blurred, 3, 5) = color(0.21, 0.27, 0.16) within 0.01
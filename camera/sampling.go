@@ -0,0 +1,73 @@
+package camera
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+type Sampling int
+
+const (
+	// Grid places the samples at the centres of a regular grid of cells.
+	Grid Sampling = iota
+	// Jittered places each sample anywhere in the pixel.
+	Jittered
+	// Stratified places one sample anywhere within each cell of a grid.
+	Stratified
+)
+
+var samplingNames = []string{"grid", "jittered", "stratified"}
+
+func (s Sampling) String() string {
+	if s < 0 || int(s) >= len(samplingNames) {
+		return fmt.Sprintf("Sampling(%d)", int(s))
+	}
+	return samplingNames[s]
+}
+
+func ParseSampling(name string) (Sampling, error) {
+	for i, n := range samplingNames {
+		if n == name {
+			return Sampling(i), nil
+		}
+	}
+	return Grid, fmt.Errorf("unknown sampling pattern %q, expected grid, jittered or stratified", name)
+}
+
+type offset struct {
+	x float64
+	y float64
+}
+
+func gridSide(samples int) int {
+	return int(math.Round(math.Sqrt(float64(samples))))
+}
+
+// offsets returns where in the pixel each sample is taken, as fractions of
+// the pixel size measured from its top left corner.
+func (s Sampling) offsets(samples int, rng *rand.Rand) []offset {
+	result := make([]offset, 0, samples)
+
+	if s == Jittered {
+		for i := 0; i < samples; i++ {
+			result = append(result, offset{rng.Float64(), rng.Float64()})
+		}
+		return result
+	}
+
+	side := gridSide(samples)
+	cell := 1 / float64(side)
+
+	for row := 0; row < side; row++ {
+		for col := 0; col < side; col++ {
+			dx, dy := 0.5, 0.5
+			if s == Stratified {
+				dx, dy = rng.Float64(), rng.Float64()
+			}
+			result = append(result, offset{(float64(col) + dx) * cell, (float64(row) + dy) * cell})
+		}
+	}
+
+	return result
+}
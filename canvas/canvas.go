@@ -8,13 +8,13 @@ import (
 )
 
 type Canvas struct {
-	Pixels []tuple.Tuple
+	Pixels []tuple.Color
 	Width  int32
 	Height int32
 }
 
 func NewCanvas(width, height int32) *Canvas {
-	pixels := make([]tuple.Tuple, width*height)
+	pixels := make([]tuple.Color, width*height)
 	return &Canvas{
 		pixels,
 		width,
@@ -22,12 +22,12 @@ func NewCanvas(width, height int32) *Canvas {
 	}
 }
 
-func (c *Canvas) WritePixel(x, y int32, color *tuple.Tuple) {
-	c.Pixels[y*c.Width+x] = *color
+func (c *Canvas) WritePixel(x, y int32, color tuple.Color) {
+	c.Pixels[y*c.Width+x] = color
 }
 
-func (c *Canvas) PixelAt(x, y int32) *tuple.Tuple {
-	return &c.Pixels[y*c.Width+x]
+func (c *Canvas) PixelAt(x, y int32) tuple.Color {
+	return c.Pixels[y*c.Width+x]
 }
 
 func componentTo255(c float64) byte {
@@ -65,13 +65,13 @@ func (c *Canvas) ToPPM() *string {
 	var current_line strings.Builder
 
 	for i, p := range c.Pixels {
-		r := fmt.Sprintf("%d", componentTo255(p.Red()))
+		r := fmt.Sprintf("%d", componentTo255(p.R))
 		appendComponent(&builder, &current_line, r, false)
 
-		g := fmt.Sprintf("%d", componentTo255(p.Green()))
+		g := fmt.Sprintf("%d", componentTo255(p.G))
 		appendComponent(&builder, &current_line, g, false)
 
-		b := fmt.Sprintf("%d", componentTo255(p.Blue()))
+		b := fmt.Sprintf("%d", componentTo255(p.B))
 		x := i % int(c.Width)
 		appendComponent(&builder, &current_line, b, x == int(c.Width)-1)
 	}
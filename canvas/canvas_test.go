@@ -1,9 +1,13 @@
 package canvas
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"image"
+	"image/color"
+	"os"
 	"rtt/sharedtest"
 	"rtt/tuple"
 	"strings"
@@ -20,7 +24,7 @@ func aCanvas(ctx context.Context, variable string, width, height int32) (context
 }
 
 func aColor(ctx context.Context, variable string, x, y, z float64) (context.Context, error) {
-	p := tuple.Color(x, y, z)
+	p := tuple.NewColor(x, y, z)
 	return context.WithValue(ctx, variables{name: variable}, p), nil
 }
 
@@ -58,10 +62,10 @@ func aCanvasComponentEquals(ctx context.Context, variable string, component stri
 
 func everyPixelCheck(ctx context.Context, variable, color string) error {
 	canvas := ctx.Value(variables{variable}).(*Canvas)
-	c := ctx.Value(variables{name: color}).(*tuple.Tuple)
+	c := ctx.Value(variables{name: color}).(tuple.Color)
 
 	for _, p := range canvas.Pixels {
-		if !tuple.CompareTuple(&p, c) {
+		if !p.Equals(c) {
 			return errors.New("pixel check failed")
 		}
 	}
@@ -70,7 +74,7 @@ func everyPixelCheck(ctx context.Context, variable, color string) error {
 
 func everyPixelSet(ctx context.Context, variable, color string) error {
 	canvas := ctx.Value(variables{variable}).(*Canvas)
-	c := ctx.Value(variables{name: color}).(*tuple.Tuple)
+	c := ctx.Value(variables{name: color}).(tuple.Color)
 
 	for x := 0; x < int(canvas.Width); x++ {
 		for y := 0; y < int(canvas.Height); y++ {
@@ -83,16 +87,16 @@ func everyPixelSet(ctx context.Context, variable, color string) error {
 
 func writePixel(ctx context.Context, canvas_var string, x, y int32, color_var string) {
 	canvas := ctx.Value(variables{name: canvas_var}).(*Canvas)
-	color := ctx.Value(variables{name: color_var}).(*tuple.Tuple)
+	color := ctx.Value(variables{name: color_var}).(tuple.Color)
 	canvas.WritePixel(x, y, color)
 }
 
 func pixelAt(ctx context.Context, canvas_var string, x, y int32, color_var string) error {
 	canvas := ctx.Value(variables{name: canvas_var}).(*Canvas)
-	color := ctx.Value(variables{name: color_var}).(*tuple.Tuple)
+	color := ctx.Value(variables{name: color_var}).(tuple.Color)
 	actual := canvas.PixelAt(x, y)
 
-	if !tuple.CompareTuple(color, actual) {
+	if !color.Equals(actual) {
 		return fmt.Errorf("pixel at %d, %d was %+v not %+v", x, y, actual, color)
 	}
 	return nil
@@ -126,18 +130,163 @@ func canvasToPPM(ctx context.Context, destination, canvas_var string) context.Co
 	return context.WithValue(ctx, variables{name: destination}, value)
 }
 
+func canvasToImage(ctx context.Context, destination, canvas_var string) context.Context {
+	canvas := ctx.Value(variables{name: canvas_var}).(*Canvas)
+	return context.WithValue(ctx, variables{name: destination}, canvas.ToImage())
+}
+
+func imageBounds(ctx context.Context, variable string, width, height int) error {
+	img := ctx.Value(variables{name: variable}).(*image.RGBA)
+	size := img.Bounds().Size()
+
+	if size.X != width || size.Y != height {
+		return fmt.Errorf("image was %dx%d not %dx%d", size.X, size.Y, width, height)
+	}
+	return nil
+}
+
+func imagePixelAt(ctx context.Context, variable string, x, y int, r, g, b int) error {
+	img := ctx.Value(variables{name: variable}).(*image.RGBA)
+	actual := img.RGBAAt(x, y)
+	expected := color.RGBA{R: uint8(r), G: uint8(g), B: uint8(b), A: 255}
+
+	if actual != expected {
+		return fmt.Errorf("image pixel at %d, %d was %+v not %+v", x, y, actual, expected)
+	}
+	return nil
+}
+
+func aFile(ctx context.Context, variable string, content *godog.DocString) context.Context {
+	value := content.Content + "\n"
+	return context.WithValue(ctx, variables{name: variable}, &value)
+}
+
+func canvasFromPPM(ctx context.Context, destination, ppm_var string) (context.Context, error) {
+	ppm := ctx.Value(variables{name: ppm_var}).(*string)
+	canvas, err := FromPPM([]byte(*ppm))
+	if err != nil {
+		return ctx, err
+	}
+	return context.WithValue(ctx, variables{name: destination}, canvas), nil
+}
+
+func canvasToPNG(ctx context.Context, destination, canvas_var string) (context.Context, error) {
+	canvas := ctx.Value(variables{name: canvas_var}).(*Canvas)
+
+	var buffer bytes.Buffer
+	if err := canvas.WritePNG(&buffer); err != nil {
+		return ctx, err
+	}
+
+	value := buffer.String()
+	return context.WithValue(ctx, variables{name: destination}, &value), nil
+}
+
+func canvasToHDR(ctx context.Context, destination, canvas_var string) context.Context {
+	canvas := ctx.Value(variables{name: canvas_var}).(*Canvas)
+	value := string(canvas.ToHDR())
+	return context.WithValue(ctx, variables{name: destination}, &value)
+}
+
+func lastRGBE(ctx context.Context, variable string, r, g, b, e int) error {
+	data := *ctx.Value(variables{name: variable}).(*string)
+	expected := string([]byte{byte(r), byte(g), byte(b), byte(e)})
+
+	if actual := data[len(data)-4:]; actual != expected {
+		return fmt.Errorf("last pixel was stored as %v not %v", []byte(actual), []byte(expected))
+	}
+	return nil
+}
+
+func aFileOnDisk(ctx context.Context, variable, path string) (context.Context, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ctx, err
+	}
+	value := string(data)
+	return context.WithValue(ctx, variables{name: variable}, &value), nil
+}
+
+func decodedCanvas(ctx context.Context, destination, data_var string) (context.Context, error) {
+	data := ctx.Value(variables{name: data_var}).(*string)
+	canvas, err := Decode([]byte(*data))
+	if err != nil {
+		return ctx, err
+	}
+	return context.WithValue(ctx, variables{name: destination}, canvas), nil
+}
+
+func decodeFails(ctx context.Context, data_var, expected string) error {
+	data := ctx.Value(variables{name: data_var}).(*string)
+	_, err := Decode([]byte(*data))
+
+	if err == nil || err.Error() != expected {
+		return fmt.Errorf("expected error %q, got %v", expected, err)
+	}
+	return nil
+}
+
+func canvasFromPPMFails(ctx context.Context, ppm_var, expected string) error {
+	ppm := ctx.Value(variables{name: ppm_var}).(*string)
+	_, err := FromPPM([]byte(*ppm))
+
+	if err == nil || err.Error() != expected {
+		return fmt.Errorf("expected error %q, got %v", expected, err)
+	}
+	return nil
+}
+
+func stringsEqual(ctx context.Context, a_var, b_var string) error {
+	a := ctx.Value(variables{name: a_var}).(*string)
+	b := ctx.Value(variables{name: b_var}).(*string)
+
+	if *a != *b {
+		return fmt.Errorf("Failed! %q != %q", *a, *b)
+	}
+	return nil
+}
+
+func namedColor(ctx context.Context, canvas_var string, x, y int32, name string) error {
+	canvas := ctx.Value(variables{name: canvas_var}).(*Canvas)
+	colors := map[string]tuple.Color{
+		"red":   tuple.Red,
+		"white": tuple.White,
+	}
+	actual := canvas.PixelAt(x, y)
+
+	if !colors[name].Equals(actual) {
+		return fmt.Errorf("pixel at %d, %d was %+v not %s", x, y, actual, name)
+	}
+	return nil
+}
+
 func CanvasAssertions(ctx *godog.ScenarioContext) {
 	regex := fmt.Sprintf(`^(.+)\.(width|height) = %s$`, sharedtest.PosInt)
 	ctx.Step(regex, aCanvasComponentEquals)
 	ctx.Step(`^every pixel of (.+) is (.+)$`, everyPixelCheck)
+	regex = fmt.Sprintf(`^pixel_at\((.+), %s, %s\) = (red|white)$`, sharedtest.PosInt, sharedtest.PosInt)
+	ctx.Step(regex, namedColor)
 	regex = fmt.Sprintf(`^pixel_at\((.+), %s, %s\) = (.+)$`, sharedtest.PosInt, sharedtest.PosInt)
 	ctx.Step(regex, pixelAt)
+	ctx.Step(`^canvas_from_ppm\((.+)\) fails with "(.+)"$`, canvasFromPPMFails)
+	ctx.Step(`^decode_canvas\((.+)\) fails with "(.+)"$`, decodeFails)
+	ctx.Step(`^([a-z0-9]+) = ([a-z0-9]+)$`, stringsEqual)
+	ctx.Step(`^(.+)\.bounds = (\d+)x(\d+)$`, imageBounds)
+	ctx.Step(`^image_pixel_at\((.+), (\d+), (\d+)\) = rgb\((\d+), (\d+), (\d+)\)$`, imagePixelAt)
+	ctx.Step(`^the last pixel of (.+) is stored as rgbe\((\d+), (\d+), (\d+), (\d+)\)$`, lastRGBE)
 }
 
 func CanvasAssignments(ctx *godog.ScenarioContext) {
 	regex := fmt.Sprintf(`^write_pixel\((.+), %s, %s, (.+)\)$`, sharedtest.PosInt, sharedtest.PosInt)
 	ctx.Step(regex, writePixel)
 	ctx.Step(`^(.+) ← canvas_to_ppm\((.+)\)$`, canvasToPPM)
+	ctx.Step(`^(.+) ← canvas_to_image\((.+)\)$`, canvasToImage)
+	ctx.Step(`^(.+) ← canvas_from_ppm\((.+)\)$`, canvasFromPPM)
+	ctx.Step(`^(.+) ← canvas_to_png\((.+)\)$`, canvasToPNG)
+	ctx.Step(`^(.+) ← canvas_to_hdr\((.+)\)$`, canvasToHDR)
+	ctx.Step(`^(.+) ← decode_canvas\((.+)\)$`, decodedCanvas)
+	ctx.Step(`^(.+) ← the file "(.+)"$`, aFileOnDisk)
+	ctx.Step(`^(.+) ← a file containing:$`, aFile)
 	ctx.Step(`^lines (\d+)-(\d+) of (.+) are$`, linesAre)
 	ctx.Step(`^(.+) ends with a newline character$`, endsWithNewline)
 	ctx.Step(`^set every pixel of (.+) to (.+)$`, everyPixelSet)
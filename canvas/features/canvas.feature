@@ -56,3 +56,153 @@ Scenario: PPM files are terminated by a newline character
   Given c ← canvas(5, 3)
   When ppm ← canvas_to_ppm(c)
   Then ppm ends with a newline character
+
+Scenario: Converting a canvas to an image
+  Given c ← canvas(5, 3)
+    And c1 ← color(1.5, 0, 0)
+    And c2 ← color(0, 0.5, 0)
+    And c3 ← color(-0.5, 0, 1)
+  When write_pixel(c, 0, 0, c1)
+    And write_pixel(c, 2, 1, c2)
+    And write_pixel(c, 4, 2, c3)
+    And img ← canvas_to_image(c)
+  Then img.bounds = 5x3
+    And image_pixel_at(img, 0, 0) = rgb(255, 0, 0)
+    And image_pixel_at(img, 2, 1) = rgb(0, 128, 0)
+    And image_pixel_at(img, 4, 2) = rgb(0, 0, 255)
+    And image_pixel_at(img, 1, 1) = rgb(0, 0, 0)
+
+Scenario: Reading a canvas from a PPM file
+  Given ppm ← a file containing:
+    """
+    P3
+    # a comment
+    2 2
+    255
+    255 0 0 0 255 0
+    0 0 255 255 255 255
+    """
+  When c ← canvas_from_ppm(ppm)
+  Then c.width = 2
+    And c.height = 2
+    And pixel_at(c, 0, 0) = red
+    And pixel_at(c, 1, 1) = white
+
+Scenario: PPM files round trip through a canvas
+  Given c ← canvas(5, 3)
+    And c1 ← color(1, 0, 0)
+    And c2 ← color(0, 0.5, 0)
+  When write_pixel(c, 0, 0, c1)
+    And write_pixel(c, 2, 1, c2)
+    And ppm ← canvas_to_ppm(c)
+    And c3 ← canvas_from_ppm(ppm)
+    And ppm2 ← canvas_to_ppm(c3)
+  Then ppm = ppm2
+
+Scenario: Reading a PPM file with the wrong number of components
+  Given ppm ← a file containing:
+    """
+    P3
+    2 2
+    255
+    255 0 0
+    """
+  Then canvas_from_ppm(ppm) fails with "expected 12 colour components, found 3"
+
+Scenario: PNG images round trip through a canvas
+  Given c ← canvas(5, 3)
+    And c1 ← color(1, 0, 0)
+    And c2 ← color(1, 1, 1)
+  When write_pixel(c, 0, 0, c1)
+    And write_pixel(c, 4, 2, c2)
+    And png ← canvas_to_png(c)
+    And c3 ← decode_canvas(png)
+  Then c3.width = 5
+    And c3.height = 3
+    And pixel_at(c3, 0, 0) = red
+    And pixel_at(c3, 4, 2) = white
+
+Scenario: Decoding a PPM file
+  Given ppm ← a file containing:
+    """
+    P3
+    1 1
+    255
+    255 255 255
+    """
+  When c ← decode_canvas(ppm)
+  Then pixel_at(c, 0, 0) = white
+
+Scenario: Decoding an image in an unknown format
+  Given gif ← a file containing:
+    """
+    GIF89a
+    """
+  Then decode_canvas(gif) fails with "unknown image format, expected a plain PPM, a PNG or a Radiance HDR"
+
+Scenario: HDR images round trip colours brighter than white
+  Given c ← canvas(2, 1)
+    And c1 ← color(4, 0.5, 100)
+    And c2 ← color(0.25, 1, 0)
+  When write_pixel(c, 0, 0, c1)
+    And write_pixel(c, 1, 0, c2)
+    And hdr ← canvas_to_hdr(c)
+    And c3 ← decode_canvas(hdr)
+  Then c3.width = 2
+    And c3.height = 1
+    And pixel_at(c3, 0, 0) = c1
+    And pixel_at(c3, 1, 0) = c2
+
+Scenario Outline: HDR images clamp colours too bright or too dark to store
+  Given c ← canvas(1, 1)
+    And c1 ← color(<red>, <green>, <blue>)
+  When write_pixel(c, 0, 0, c1)
+    And hdr ← canvas_to_hdr(c)
+  Then the last pixel of hdr is stored as rgbe(<rgbe>)
+
+  Examples:
+    | red                                        | green | blue | rgbe           |
+    | 4                                          | 0.5   | 100  | 8, 1, 200, 135 |
+    | 10000000000000000000000000000000000000000  | 1     | 0    | 255, 0, 0, 255 |
+    | 0.0000000000000000000000000000000000000001 | 0     | 0    | 0, 0, 0, 0     |
+
+Scenario: Decoding a run-length encoded HDR image
+  Given hdr ← the file "testdata/rle.hdr"
+    And c1 ← color(1, 0.5, 0.25)
+    And c2 ← color(0, 0, 0)
+    And c3 ← color(3, 0, 0)
+    And c4 ← color(7, 0, 0)
+  When c ← decode_canvas(hdr)
+  Then c.width = 8
+    And c.height = 2
+    And pixel_at(c, 0, 0) = c1
+    And pixel_at(c, 7, 0) = c1
+    And pixel_at(c, 0, 1) = c2
+    And pixel_at(c, 3, 1) = c3
+    And pixel_at(c, 7, 1) = c4
+
+Scenario: Decoding an HDR image whose pixels are cut short
+  Given hdr ← a file containing:
+    """
+    #?RADIANCE
+    FORMAT=32-bit_rle_rgbe
+
+    -Y 2 +X 2
+    """
+  Then decode_canvas(hdr) fails with "HDR pixel data ends early"
+
+Scenario Outline: Decoding an HDR image of an unsupported size
+  Given hdr ← a file containing:
+    """
+    #?RADIANCE
+    FORMAT=32-bit_rle_rgbe
+
+    <resolution>
+    """
+  Then decode_canvas(hdr) fails with "<error>"
+
+  Examples:
+    | resolution        | error                                                                      |
+    | -Y 0 +X 2         | unsupported HDR resolution "-Y 0 +X 2", expected -Y height +X width        |
+    | -Y 2 +X 40000     | HDR image of 40000 by 2 pixels is larger than the 32767 by 32767 supported |
+    | -Y 30000 +X 30000 | HDR pixel data ends early                                                  |
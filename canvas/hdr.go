@@ -0,0 +1,198 @@
+package canvas
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"rtt/tuple"
+	"strings"
+)
+
+// HDR images are Radiance RGBE files. Each pixel is stored as three 8-bit
+// mantissas sharing an 8-bit exponent, so colours keep their full range
+// instead of being clipped to [0, 1] like those of a PPM or a PNG.
+
+const (
+	hdrFormat = "32-bit_rle_rgbe"
+	// hdrExponentBias turns a stored exponent into the power of two that a
+	// mantissa is scaled by, counting the 8 bits of the mantissa.
+	hdrExponentBias = 128 + 8
+	// hdrMinRunLength and hdrMaxRunLength are the widths of scanline that
+	// may be run-length encoded.
+	hdrMinRunLength = 8
+	hdrMaxRunLength = 0x7fff
+	// hdrMaxSize is the largest width or height of image that is read.
+	hdrMaxSize = 0x7fff
+	// hdrMaxRun is the most pixels a run of an encoded scanline covers.
+	hdrMaxRun = 127
+	// hdrMaxExponent is the largest power of two, as math.Frexp counts
+	// them, that a stored exponent scales a colour by.
+	hdrMaxExponent = 255 - 128
+	// hdrMinValue is the darkest colour written. Darker ones, down to those
+	// whose exponent could not be stored, are written as the zero pixel.
+	hdrMinValue = 1e-32
+)
+
+// FromHDR reads a canvas from a Radiance HDR image, with scanlines that are
+// either flat or run-length encoded.
+func FromHDR(data []byte) (*Canvas, error) {
+	if !bytes.HasPrefix(data, []byte("#?")) {
+		return nil, errors.New("not a Radiance HDR image")
+	}
+
+	for {
+		end := bytes.IndexByte(data, '\n')
+		if end < 0 {
+			return nil, errors.New("HDR header has no end")
+		}
+		line := string(data[:end])
+		data = data[end+1:]
+
+		if line == "" {
+			break
+		}
+		if format, ok := strings.CutPrefix(line, "FORMAT="); ok && format != hdrFormat {
+			return nil, fmt.Errorf("unsupported HDR format %q, expected %s", format, hdrFormat)
+		}
+	}
+
+	end := bytes.IndexByte(data, '\n')
+	if end < 0 {
+		return nil, errors.New("HDR image has no resolution")
+	}
+	resolution := string(data[:end])
+	data = data[end+1:]
+
+	var width, height int
+	if _, err := fmt.Sscanf(resolution, "-Y %d +X %d", &height, &width); err != nil || width <= 0 || height <= 0 {
+		return nil, fmt.Errorf("unsupported HDR resolution %q, expected -Y height +X width", resolution)
+	}
+	if width > hdrMaxSize || height > hdrMaxSize {
+		return nil, fmt.Errorf("HDR image of %d by %d pixels is larger than the %d by %d supported", width, height, hdrMaxSize, hdrMaxSize)
+	}
+	if len(data) < height*minScanlineLength(width) {
+		return nil, errors.New("HDR pixel data ends early")
+	}
+
+	c := NewCanvas(int32(width), int32(height))
+	scanline := make([]byte, width*4)
+
+	for y := 0; y < height; y++ {
+		var err error
+		if data, err = readScanline(data, scanline); err != nil {
+			return nil, fmt.Errorf("scanline %d: %w", y, err)
+		}
+
+		for x := 0; x < width; x++ {
+			c.WritePixel(int32(x), int32(y), fromRGBE(scanline[x*4:x*4+4]))
+		}
+	}
+
+	return c, nil
+}
+
+// minScanlineLength is the fewest bytes a scanline of width pixels can be
+// stored in: flat, or encoded as one run of a repeated byte for each
+// component of up to hdrMaxRun pixels.
+func minScanlineLength(width int) int {
+	if width >= hdrMinRunLength && width <= hdrMaxRunLength {
+		return 4 + 4*2*((width+hdrMaxRun-1)/hdrMaxRun)
+	}
+	return width * 4
+}
+
+// readScanline fills scanline with the RGBE pixels at the start of data and
+// returns what follows them.
+func readScanline(data, scanline []byte) ([]byte, error) {
+	width := len(scanline) / 4
+
+	encoded := width >= hdrMinRunLength && width <= hdrMaxRunLength &&
+		len(data) >= 4 && data[0] == 2 && data[1] == 2 && int(data[2])<<8|int(data[3]) == width
+
+	if !encoded {
+		if len(data) < len(scanline) {
+			return nil, errors.New("HDR pixel data ends early")
+		}
+		copy(scanline, data)
+		return data[len(scanline):], nil
+	}
+
+	// Run-length encoded scanlines hold each component of every pixel in
+	// turn, as runs of one repeated byte or of literal bytes.
+	data = data[4:]
+	for component := 0; component < 4; component++ {
+		for x := 0; x < width; {
+			if len(data) == 0 {
+				return nil, errors.New("HDR pixel data ends early")
+			}
+			count := int(data[0])
+			repeat := count > 128
+			if repeat {
+				count -= 128
+			}
+
+			if count == 0 || x+count > width || (repeat && len(data) < 2) || (!repeat && len(data) < count+1) {
+				return nil, errors.New("invalid HDR run")
+			}
+
+			for i := 0; i < count; i++ {
+				if repeat {
+					scanline[(x+i)*4+component] = data[1]
+				} else {
+					scanline[(x+i)*4+component] = data[1+i]
+				}
+			}
+
+			x += count
+			if repeat {
+				data = data[2:]
+			} else {
+				data = data[1+count:]
+			}
+		}
+	}
+
+	return data, nil
+}
+
+func fromRGBE(rgbe []byte) tuple.Color {
+	if rgbe[3] == 0 {
+		return tuple.Black
+	}
+
+	scale := math.Ldexp(1, int(rgbe[3])-hdrExponentBias)
+	return tuple.NewColor(float64(rgbe[0])*scale, float64(rgbe[1])*scale, float64(rgbe[2])*scale)
+}
+
+func toRGBE(color tuple.Color) []byte {
+	largest := math.Max(color.R, math.Max(color.G, color.B))
+	if !(largest >= hdrMinValue) {
+		return []byte{0, 0, 0, 0}
+	}
+
+	// Colours too bright for the exponent are clamped to the brightest that
+	// can be stored.
+	_, exponent := math.Frexp(largest)
+	if exponent > hdrMaxExponent || math.IsInf(largest, 1) {
+		exponent = hdrMaxExponent
+	}
+	scale := math.Ldexp(256, -exponent)
+
+	component := func(value float64) byte {
+		return byte(math.Min(255, math.Max(0, value)*scale))
+	}
+	return []byte{component(color.R), component(color.G), component(color.B), byte(exponent + 128)}
+}
+
+// ToHDR writes the canvas as a Radiance HDR image with flat scanlines.
+func (c *Canvas) ToHDR() []byte {
+	var buffer bytes.Buffer
+
+	fmt.Fprintf(&buffer, "#?RADIANCE\nFORMAT=%s\n\n-Y %d +X %d\n", hdrFormat, c.Height, c.Width)
+	for _, pixel := range c.Pixels {
+		buffer.Write(toRGBE(pixel))
+	}
+
+	return buffer.Bytes()
+}
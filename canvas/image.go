@@ -0,0 +1,67 @@
+package canvas
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"rtt/tuple"
+)
+
+func (c *Canvas) ToImage() *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, int(c.Width), int(c.Height)))
+
+	for y := int32(0); y < c.Height; y++ {
+		for x := int32(0); x < c.Width; x++ {
+			p := c.PixelAt(x, y)
+			img.SetRGBA(int(x), int(y), color.RGBA{
+				R: componentTo255(p.R),
+				G: componentTo255(p.G),
+				B: componentTo255(p.B),
+				A: 255,
+			})
+		}
+	}
+
+	return img
+}
+
+func (c *Canvas) WritePNG(w io.Writer) error {
+	return png.Encode(w, c.ToImage())
+}
+
+// FromImage reads the pixels of an image into a canvas, taking its 8-bit
+// components as they are written by ToImage, without any gamma.
+func FromImage(img image.Image) *Canvas {
+	bounds := img.Bounds()
+	c := NewCanvas(int32(bounds.Dx()), int32(bounds.Dy()))
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			p := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			c.WritePixel(int32(x-bounds.Min.X), int32(y-bounds.Min.Y), tuple.NewColor(float64(p.R)/255, float64(p.G)/255, float64(p.B)/255))
+		}
+	}
+
+	return c
+}
+
+// Decode reads a canvas from a plain PPM, a PNG or a Radiance HDR image.
+func Decode(data []byte) (*Canvas, error) {
+	switch {
+	case bytes.HasPrefix(data, []byte("P3")):
+		return FromPPM(data)
+	case bytes.HasPrefix(data, []byte("\x89PNG")):
+		img, err := png.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		return FromImage(img), nil
+	case bytes.HasPrefix(data, []byte("#?")):
+		return FromHDR(data)
+	}
+
+	return nil, errors.New("unknown image format, expected a plain PPM, a PNG or a Radiance HDR")
+}
@@ -0,0 +1,68 @@
+package canvas
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"rtt/tuple"
+	"strconv"
+)
+
+func ppmTokens(data []byte) []string {
+	tokens := []string{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), len(data)+1)
+
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if i := bytes.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		for _, field := range bytes.Fields(line) {
+			tokens = append(tokens, string(field))
+		}
+	}
+
+	return tokens
+}
+
+func FromPPM(data []byte) (*Canvas, error) {
+	tokens := ppmTokens(data)
+
+	if len(tokens) < 4 || tokens[0] != "P3" {
+		return nil, errors.New("not a plain PPM (P3) image")
+	}
+
+	header := make([]int, 3)
+	for i := range header {
+		value, err := strconv.Atoi(tokens[i+1])
+		if err != nil || value <= 0 {
+			return nil, fmt.Errorf("invalid PPM header value %q", tokens[i+1])
+		}
+		header[i] = value
+	}
+
+	width, height, maxValue := header[0], header[1], float64(header[2])
+	components := tokens[4:]
+
+	if len(components) != width*height*3 {
+		return nil, fmt.Errorf("expected %d colour components, found %d", width*height*3, len(components))
+	}
+
+	c := NewCanvas(int32(width), int32(height))
+
+	for i := range c.Pixels {
+		rgb := make([]float64, 3)
+		for j := range rgb {
+			value, err := strconv.Atoi(components[i*3+j])
+			if err != nil {
+				return nil, fmt.Errorf("invalid colour component %q", components[i*3+j])
+			}
+			rgb[j] = float64(value) / maxValue
+		}
+		c.Pixels[i] = tuple.NewColor(rgb[0], rgb[1], rgb[2])
+	}
+
+	return c, nil
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"rtt/examples"
+	"runtime"
+
+	"github.com/spf13/pflag"
+)
+
+func exampleCommand(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		exampleUsage(stderr)
+		return exitUsage
+	}
+
+	switch args[0] {
+	case "list":
+		for _, e := range examples.All() {
+			fmt.Fprintf(stdout, "%-10s %s\n", e.Name, e.Description)
+		}
+		return exitOK
+	case "render":
+		return exampleRenderCommand(args[1:], stderr)
+	default:
+		fmt.Fprintf(stderr, "rtt example: unknown subcommand %q\n", args[0])
+		exampleUsage(stderr)
+		return exitUsage
+	}
+}
+
+func exampleUsage(w io.Writer) {
+	fmt.Fprintln(w, "usage: rtt example list")
+	fmt.Fprintln(w, "       rtt example render <name> [-o <output.png|output.ppm>] [flags]")
+}
+
+func exampleRenderCommand(args []string, stderr io.Writer) int {
+	flags := pflag.NewFlagSet("example render", pflag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		exampleUsage(stderr)
+		flags.PrintDefaults()
+	}
+
+	output := flags.StringP("output", "o", "", "image file to write (default <name>.ppm)")
+	workers := flags.Int("workers", runtime.NumCPU(), "number of rows rendered concurrently")
+
+	if err := flags.Parse(args); err != nil {
+		if err == pflag.ErrHelp {
+			return exitOK
+		}
+		return exitUsage
+	}
+
+	if flags.NArg() != 1 {
+		fmt.Fprintln(stderr, "rtt example render: expected exactly one example name")
+		flags.Usage()
+		return exitUsage
+	}
+
+	name := flags.Arg(0)
+	e, ok := examples.Lookup(name)
+	if !ok {
+		fmt.Fprintf(stderr, "rtt example render: unknown example %q, see rtt example list\n", name)
+		return exitUsage
+	}
+
+	path := *output
+	if path == "" {
+		path = name + ".ppm"
+	}
+
+	if _, err := imageFormat(path); err != nil {
+		fmt.Fprintf(stderr, "rtt example render: %s\n", err)
+		return exitUsage
+	}
+
+	if *workers < 1 {
+		fmt.Fprintln(stderr, "rtt example render: workers must be at least 1")
+		return exitUsage
+	}
+
+	image, err := e.Render(*workers)
+	if err != nil {
+		fmt.Fprintf(stderr, "rtt example render: %s: %s\n", name, err)
+		return exitFailure
+	}
+
+	if err := writeImage(path, image); err != nil {
+		fmt.Fprintf(stderr, "rtt example render: %s\n", err)
+		return exitFailure
+	}
+
+	return exitOK
+}
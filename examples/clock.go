@@ -0,0 +1,36 @@
+package examples
+
+import (
+	"math"
+	"rtt/canvas"
+	"rtt/transformations"
+	"rtt/tuple"
+)
+
+func init() {
+	Register(Example{
+		Name:        "clock",
+		Description: "twelve hour marks rotated around the y axis",
+		Render:      clock,
+	})
+}
+
+func clock(workers int) (*canvas.Canvas, error) {
+	c := canvas.NewCanvas(800, 600)
+	midX := c.Width / 2
+	midY := c.Height / 2
+	radius := float64(midY) / 2.0
+
+	origin := tuple.NewPoint(0, 0, 0)
+	c.WritePixel(int32(origin.X)+midX, int32(origin.Z)+midY, tuple.Red)
+
+	twelve := tuple.NewPoint(0, 0, 1)
+
+	for hour := 0.0; hour < 12; hour++ {
+		r := transformations.RotationY(hour * math.Pi / 6.0)
+		p := r.MultiplyPoint(twelve)
+		c.WritePixel(int32(radius*p.X)+midX, int32(radius*p.Z)+midY, tuple.White)
+	}
+
+	return c, nil
+}
@@ -0,0 +1,40 @@
+package examples
+
+import (
+	"rtt/canvas"
+	"sort"
+)
+
+type Example struct {
+	Name        string
+	Description string
+	Render      func(workers int) (*canvas.Canvas, error)
+}
+
+var registry = map[string]Example{}
+
+func Register(e Example) {
+	if _, exists := registry[e.Name]; exists {
+		panic("example registered twice: " + e.Name)
+	}
+	registry[e.Name] = e
+}
+
+func Lookup(name string) (Example, bool) {
+	e, ok := registry[name]
+	return e, ok
+}
+
+func All() []Example {
+	result := make([]Example, 0, len(registry))
+
+	for _, e := range registry {
+		result = append(result, e)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+
+	return result
+}
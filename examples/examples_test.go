@@ -0,0 +1,62 @@
+package examples
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"rtt/canvas"
+	"rtt/goldentest"
+	"rtt/sharedtest"
+	"runtime"
+	"testing"
+
+	"github.com/cucumber/godog"
+)
+
+func aRenderedExample(ctx context.Context, variable, name string) (context.Context, error) {
+	e, ok := Lookup(name)
+	if !ok {
+		return ctx, fmt.Errorf("unknown example %s", name)
+	}
+	image, err := e.Render(runtime.NumCPU())
+	if err != nil {
+		return ctx, err
+	}
+	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, image), nil
+}
+
+func assertMatchesGolden(ctx context.Context, variable, path string, tolerance int) error {
+	image := ctx.Value(sharedtest.Variables{Name: variable}).(*canvas.Canvas)
+	return goldentest.Compare(path, image, tolerance)
+}
+
+func assertEveryExampleHasGolden(dir string) error {
+	for _, e := range All() {
+		if _, err := os.Stat(filepath.Join(dir, e.Name+".ppm")); err != nil {
+			return fmt.Errorf("example %s has no golden image: %w", e.Name, err)
+		}
+	}
+	return nil
+}
+
+func initializeScenario(sc *godog.ScenarioContext) {
+	sc.Step(fmt.Sprintf(`^%s ← render_example\("([a-z-]+)"\)$`, sharedtest.TupleVariableName), aRenderedExample)
+	sc.Step(fmt.Sprintf(`^%s matches the golden image "([^"]+)" with tolerance %s$`, sharedtest.TupleVariableName, sharedtest.PosInt), assertMatchesGolden)
+	sc.Step(`^every example has a golden image in "([^"]+)"$`, assertEveryExampleHasGolden)
+}
+
+func TestFeatures(t *testing.T) {
+	suite := godog.TestSuite{
+		ScenarioInitializer: initializeScenario,
+		Options: &godog.Options{
+			Format:   "pretty",
+			Paths:    []string{"features/examples.feature"},
+			TestingT: t,
+		},
+	}
+
+	if suite.Run() != 0 {
+		t.Fatal("non-zero exit status")
+	}
+}
@@ -0,0 +1,14 @@
+Feature: Examples
+
+Scenario Outline: Rendering an example matches its golden image
+  When image ← render_example("<name>")
+  Then image matches the golden image "testdata/golden/<name>.ppm" with tolerance <tolerance>
+
+  Examples:
+    | name    | tolerance |
+    | clock   | 0         |
+    | sphere  | 0         |
+    | spheres | 1         |
+
+Scenario: Every example has a golden image
+  Then every example has a golden image in "testdata/golden"
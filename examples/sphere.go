@@ -0,0 +1,43 @@
+package examples
+
+import (
+	"rtt/canvas"
+	"rtt/ray"
+	"rtt/tuple"
+)
+
+func init() {
+	Register(Example{
+		Name:        "sphere",
+		Description: "silhouette of a unit sphere cast onto a wall",
+		Render:      sphere,
+	})
+}
+
+func sphere(workers int) (*canvas.Canvas, error) {
+	shape := ray.NewSphere()
+	rayOrigin := tuple.NewPoint(0, 0, -5)
+	wallZ := 10.0
+	wallSize := 7.0
+	canvasPixels := 100.0
+	pixelSize := wallSize / canvasPixels
+	half := wallSize / 2.0
+
+	c := canvas.NewCanvas(int32(canvasPixels), int32(canvasPixels))
+	for y := 0; y < int(c.Height); y++ {
+		worldY := half - pixelSize*float64(y)
+		for x := 0; x < int(c.Width); x++ {
+			worldX := -half + pixelSize*float64(x)
+			position := tuple.NewPoint(worldX, worldY, wallZ)
+
+			r := ray.NewRay(rayOrigin, position.Subtract(rayOrigin).Normalize())
+			intersections := shape.Intersect(r)
+
+			if intersections.Hit() != nil {
+				c.WritePixel(int32(x), int32(y), tuple.Red)
+			}
+		}
+	}
+
+	return c, nil
+}
@@ -0,0 +1,97 @@
+package examples
+
+import (
+	"math"
+	"rtt/camera"
+	"rtt/canvas"
+	"rtt/ray"
+	"rtt/transformations"
+	"rtt/tuple"
+	"rtt/world"
+)
+
+func init() {
+	Register(Example{
+		Name:        "spheres",
+		Description: "three spheres in a room built from flattened spheres",
+		Render:      spheres,
+	})
+}
+
+func spheres(workers int) (*canvas.Canvas, error) {
+	w := world.NewWorld()
+	w.AddLight(ray.NewPointLight(tuple.NewPoint(-10, 10, -10), tuple.NewColor(1, 1, 1)))
+
+	wallMaterial := ray.NewMaterial()
+	wallMaterial.Color = tuple.NewColor(1, 0.9, 0.9)
+	wallMaterial.Specular = 0
+
+	flatten := transformations.Scaling(10, 0.01, 10)
+
+	floor := ray.NewSphere()
+	if err := floor.SetTransform(flatten); err != nil {
+		return nil, err
+	}
+	floor.Material = *wallMaterial
+	w.AddObject(floor)
+
+	leftWall := ray.NewSphere()
+	leftWallTransform := transformations.Identity().
+		Then(flatten).
+		RotateX(math.Pi/2).
+		RotateY(-math.Pi/4).
+		Translate(0, 0, 5).
+		Matrix()
+	if err := leftWall.SetTransform(leftWallTransform); err != nil {
+		return nil, err
+	}
+	leftWall.Material = *wallMaterial
+	w.AddObject(leftWall)
+
+	rightWall := ray.NewSphere()
+	rightWallTransform := transformations.Identity().
+		Then(flatten).
+		RotateX(math.Pi/2).
+		RotateY(math.Pi/4).
+		Translate(0, 0, 5).
+		Matrix()
+	if err := rightWall.SetTransform(rightWallTransform); err != nil {
+		return nil, err
+	}
+	rightWall.Material = *wallMaterial
+	w.AddObject(rightWall)
+
+	middle := ray.NewSphere()
+	if err := middle.SetTransform(transformations.Translation(-0.5, 1, 0.5)); err != nil {
+		return nil, err
+	}
+	middle.Material.Color = tuple.NewColor(0.1, 1, 0.5)
+	middle.Material.Diffuse = 0.7
+	middle.Material.Specular = 0.3
+	w.AddObject(middle)
+
+	right := ray.NewSphere()
+	if err := right.SetTransform(transformations.Identity().Scale(0.5, 0.5, 0.5).Translate(1.5, 0.5, -0.5).Matrix()); err != nil {
+		return nil, err
+	}
+	right.Material.Color = tuple.NewColor(0.5, 1, 0.1)
+	right.Material.Diffuse = 0.7
+	right.Material.Specular = 0.3
+	w.AddObject(right)
+
+	left := ray.NewSphere()
+	if err := left.SetTransform(transformations.Identity().Scale(0.33, 0.33, 0.33).Translate(-1.5, 0.33, -0.75).Matrix()); err != nil {
+		return nil, err
+	}
+	left.Material.Color = tuple.NewColor(1, 0.8, 0.1)
+	left.Material.Diffuse = 0.7
+	left.Material.Specular = 0.3
+	w.AddObject(left)
+
+	c := camera.NewCamera(100, 50, math.Pi/3)
+	if err := c.SetTransform(transformations.ViewTransform(tuple.NewPoint(0, 1.5, -5), tuple.NewPoint(0, 1, 0), tuple.NewVector(0, 1, 0))); err != nil {
+		return nil, err
+	}
+
+	return c.RenderWithWorkers(w, workers), nil
+}
@@ -0,0 +1,186 @@
+Feature: Command line
+
+Background:
+  Given a scene file "scene.yaml":
+    """
+    - add: camera
+      width: 20
+      height: 10
+      field-of-view: 1.0471975512
+      from: [0, 0, -5]
+      to: [0, 0, 0]
+      up: [0, 1, 0]
+    - add: light
+      at: [-10, 10, -10]
+      intensity: [1, 1, 1]
+    - add: sphere
+      material:
+        color: [1, 0.2, 1]
+    """
+
+Scenario: Rendering a scene to a PNG
+  When I run "rtt render scene.yaml -o out.png"
+  Then the exit code is 0
+    And "out.png" is a 20x10 PNG image
+
+Scenario: Rendering a scene to a PPM
+  When I run "rtt render scene.yaml -o out.ppm --workers 2 --depth 1"
+  Then the exit code is 0
+    And "out.ppm" starts with "P3\n20 10\n255\n"
+
+Scenario: Overriding the image size
+  When I run "rtt render --width 8 scene.yaml --height 6 -o out.png"
+  Then the exit code is 0
+    And "out.png" is a 8x6 PNG image
+
+Scenario: Supersampled renders are reproducible for a seed
+  When I run "rtt render scene.yaml -o first.ppm --sampling jittered --samples 4 --seed 3"
+    And I run "rtt render scene.yaml -o second.ppm --sampling jittered --samples 4 --seed 3 --workers 1"
+    And I run "rtt render scene.yaml -o plain.ppm"
+  Then the exit code is 0
+    And "first.ppm" is identical to "second.ppm"
+    And "first.ppm" is not identical to "plain.ppm"
+
+Scenario: Path traced renders are reproducible for a seed
+  When I run "rtt render scene.yaml -o first.ppm --integrator path --samples 4 --seed 3"
+    And I run "rtt render scene.yaml -o second.ppm --integrator path --samples 4 --seed 3 --workers 1"
+    And I run "rtt render scene.yaml -o whitted.ppm --samples 4 --seed 3"
+  Then the exit code is 0
+    And "first.ppm" is identical to "second.ppm"
+    And "first.ppm" is not identical to "whitted.ppm"
+
+Scenario: Rendering with an unknown integrator
+  When I run "rtt render scene.yaml -o out.png --integrator photon"
+  Then the exit code is 2
+    And stderr contains "unknown integrator \"photon\""
+
+Scenario: Rendering with an invalid number of samples
+  When I run "rtt render scene.yaml -o out.png --samples 3"
+  Then the exit code is 2
+    And stderr contains "grid sampling needs a square number of samples per pixel, found 3"
+
+Scenario: Rendering with an unknown sampling pattern
+  When I run "rtt render scene.yaml -o out.png --sampling random"
+  Then the exit code is 2
+    And stderr contains "unknown sampling pattern \"random\""
+
+Scenario: Running without a command
+  When I run "rtt"
+  Then the exit code is 2
+    And stderr contains "usage: rtt <command>"
+
+Scenario: Running an unknown command
+  When I run "rtt draw scene.yaml"
+  Then the exit code is 2
+    And stderr contains "rtt: unknown command \"draw\""
+
+Scenario: Rendering without an output file
+  When I run "rtt render scene.yaml"
+  Then the exit code is 2
+    And stderr contains "an output file must be given with -o"
+
+Scenario: Rendering to an unsupported format
+  When I run "rtt render scene.yaml -o out.jpg"
+  Then the exit code is 2
+    And stderr contains "unsupported image format \".jpg\""
+
+Scenario: Rendering a missing scene file
+  When I run "rtt render missing.yaml -o out.png"
+  Then the exit code is 1
+    And stderr contains "rtt render: open missing.yaml"
+
+Scenario: Rendering an invalid scene file
+  Given a scene file "broken.yaml":
+    """
+    - add: camera
+      width: 20
+      height: 10
+      field-of-view: 1
+      from: [0, 0, -5]
+      to: [0, 0, 0]
+      up: [0, 1, 0]
+    - add: cube
+    """
+  When I run "rtt render broken.yaml -o out.png"
+  Then the exit code is 1
+    And stderr contains "rtt render: broken.yaml: line 8: unknown object type \"cube\""
+
+Scenario: Listing the examples
+  When I run "rtt example list"
+  Then the exit code is 0
+    And stdout contains "clock"
+    And stdout contains "sphere"
+    And stdout contains "spheres"
+
+Scenario: Rendering the clock example reproduces clock.ppm
+  When I run "rtt example render clock"
+  Then the exit code is 0
+    And "clock.ppm" is identical to the fixture "examples/testdata/golden/clock.ppm"
+
+Scenario: Rendering the sphere example reproduces sphere.ppm
+  When I run "rtt example render sphere -o silhouette.ppm"
+  Then the exit code is 0
+    And "silhouette.ppm" is identical to the fixture "examples/testdata/golden/sphere.ppm"
+
+Scenario: Rendering an unknown example
+  When I run "rtt example render teapot"
+  Then the exit code is 2
+    And stderr contains "unknown example \"teapot\""
+
+Scenario: Rendering an example that cannot be built
+  When I run "rtt example render broken"
+  Then the exit code is 1
+    And stderr contains "rtt example render: broken: input matrix not invertible"
+
+Scenario: Animating a scene renders one numbered image per frame
+  Given a scene file "spin.yaml":
+    """
+    - add: camera
+      width: 8
+      height: 4
+      field-of-view: 1.0471975512
+      from: [0, 0, -5]
+      to: [0, 0, 0]
+      up: [0, 1, 0]
+      keyframes:
+        - frame: 0
+          from: [0, 0, -5]
+          to: [0, 0, 0]
+          up: [0, 1, 0]
+        - frame: 4
+          from: [5, 0, 0]
+          to: [0, 0, 0]
+          up: [0, 1, 0]
+    - add: light
+      at: [-10, 10, -10]
+      intensity: [1, 1, 1]
+    - add: sphere
+      keyframes:
+        - frame: 0
+          transform:
+            - [translate, -1, 0, 0]
+        - frame: 4
+          transform:
+            - [translate, 1, 0, 0]
+    """
+  When I run "rtt animate spin.yaml -o spin-%03d.png --first 1 --last 3 --width 6 --height 6"
+  Then the exit code is 0
+    And stdout contains "spin-001.png\nspin-002.png\nspin-003.png\n"
+    And "spin-001.png" is a 6x6 PNG image
+    And "spin-003.png" is a 6x6 PNG image
+    And "spin-001.png" is not identical to "spin-003.png"
+
+Scenario: Animating needs a frame number in the output name
+  When I run "rtt animate scene.yaml -o frame.png --last 2"
+  Then the exit code is 2
+    And stderr contains "output \"frame.png\" must contain one verb such as %04d for the frame number"
+
+Scenario: Animating frames in the wrong order
+  When I run "rtt animate scene.yaml -o frame-%d.png --first 5 --last 2"
+  Then the exit code is 2
+    And stderr contains "the last frame must not come before the first"
+
+Scenario: Rendering with a negative surface offset
+  When I run "rtt render scene.yaml -o out.png --surface-offset -1"
+  Then the exit code is 2
+    And stderr contains "surface offset must not be negative"
@@ -0,0 +1,62 @@
+Feature: Golden images
+
+Background:
+  Given c ← canvas(4, 3)
+    And red ← color(1, 0, 0)
+    And write_pixel(c, 1, 1, red)
+
+Scenario: A canvas matches an identical golden image
+  Given the golden image "same.ppm" contains c
+  Then c matches the golden image "same.ppm" with tolerance 0
+
+Scenario: Differences within the tolerance are accepted
+  Given the golden image "close.ppm" contains c
+    And nearly ← color(0.99, 0.01, 0)
+    And write_pixel(c, 1, 1, nearly)
+  Then c matches the golden image "close.ppm" with tolerance 3
+
+Scenario: Differences beyond the tolerance are reported with a diff image
+  Given the golden image "far.ppm" contains c
+    And blue ← color(0, 0, 1)
+    And write_pixel(c, 2, 0, blue)
+  When comparing c against the golden image "far.ppm" with tolerance 3
+  Then the comparison fails with "1 pixels differ"
+    And the diff image "far.diff.png" marks pixel 2, 0
+    And the diff image "far.diff.png" does not mark pixel 1, 1
+
+Scenario: Images of a different size are reported
+  Given the golden image "small.ppm" contains c
+    And d ← canvas(2, 2)
+  When comparing d against the golden image "small.ppm" with tolerance 0
+  Then the comparison fails with "image is 2x2"
+
+Scenario: A missing golden image asks for -update
+  When comparing c against the golden image "missing.ppm" with tolerance 0
+  Then the comparison fails with "run go test with -update to create it"
+
+Scenario: The update flag regenerates the golden image
+  Given the update flag is set
+  When comparing c against the golden image "fresh/new.ppm" with tolerance 0
+  Then the comparison succeeds
+    And the golden image "fresh/new.ppm" exists
+
+Scenario: Rendering a scene file and comparing it
+  Given a scene file "scene.yaml":
+    """
+    - add: camera
+      width: 8
+      height: 6
+      field-of-view: 1.0471975512
+      from: [0, 0, -5]
+      to: [0, 0, 0]
+      up: [0, 1, 0]
+    - add: light
+      at: [-10, 10, -10]
+      intensity: [1, 1, 1]
+    - add: sphere
+    """
+    And the update flag is set
+    And the scene "scene.yaml" is compared against the golden image "scene.ppm" with tolerance 0
+    And the update flag is not set
+  When comparing the scene "scene.yaml" against the golden image "scene.ppm" with tolerance 0
+  Then the comparison succeeds
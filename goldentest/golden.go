@@ -0,0 +1,141 @@
+package goldentest
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"rtt/canvas"
+	"rtt/scene"
+	"runtime"
+	"strings"
+)
+
+var Update = flag.Bool("update", false, "regenerate golden images instead of comparing against them")
+
+type Difference struct {
+	X, Y     int
+	Actual   color.RGBA
+	Expected color.RGBA
+}
+
+func DiffPath(goldenPath string) string {
+	return strings.TrimSuffix(goldenPath, filepath.Ext(goldenPath)) + ".diff.png"
+}
+
+func write(goldenPath string, actual *canvas.Canvas) error {
+	if err := os.MkdirAll(filepath.Dir(goldenPath), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(goldenPath, []byte(*actual.ToPPM()), 0666)
+}
+
+func channelDelta(a, b uint8) int {
+	if a > b {
+		return int(a - b)
+	}
+	return int(b - a)
+}
+
+func Differences(actual, expected *image.RGBA, tolerance int) []Difference {
+	result := []Difference{}
+	bounds := expected.Bounds()
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			a := actual.RGBAAt(x, y)
+			e := expected.RGBAAt(x, y)
+
+			if channelDelta(a.R, e.R) > tolerance || channelDelta(a.G, e.G) > tolerance || channelDelta(a.B, e.B) > tolerance {
+				result = append(result, Difference{X: x, Y: y, Actual: a, Expected: e})
+			}
+		}
+	}
+
+	return result
+}
+
+func diffImage(expected *image.RGBA, differences []Difference) *image.RGBA {
+	bounds := expected.Bounds()
+	img := image.NewRGBA(bounds)
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			e := expected.RGBAAt(x, y)
+			grey := uint8((int(e.R) + int(e.G) + int(e.B)) / 12)
+			img.SetRGBA(x, y, color.RGBA{R: grey, G: grey, B: grey, A: 255})
+		}
+	}
+
+	for _, d := range differences {
+		img.SetRGBA(d.X, d.Y, color.RGBA{R: 255, A: 255})
+	}
+
+	return img
+}
+
+func writeDiff(path string, img *image.RGBA) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+func Compare(goldenPath string, actual *canvas.Canvas, tolerance int) error {
+	if *Update {
+		return write(goldenPath, actual)
+	}
+
+	data, err := os.ReadFile(goldenPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("golden image %s does not exist, run go test with -update to create it", goldenPath)
+	}
+	if err != nil {
+		return err
+	}
+
+	golden, err := canvas.FromPPM(data)
+	if err != nil {
+		return fmt.Errorf("reading golden image %s: %w", goldenPath, err)
+	}
+
+	if golden.Width != actual.Width || golden.Height != actual.Height {
+		return fmt.Errorf("image is %dx%d but golden image %s is %dx%d", actual.Width, actual.Height, goldenPath, golden.Width, golden.Height)
+	}
+
+	expected := golden.ToImage()
+	differences := Differences(actual.ToImage(), expected, tolerance)
+
+	if len(differences) == 0 {
+		return nil
+	}
+
+	diffPath := DiffPath(goldenPath)
+	if err := writeDiff(diffPath, diffImage(expected, differences)); err != nil {
+		return fmt.Errorf("%d pixels differ from %s, and the diff image could not be written: %w", len(differences), goldenPath, err)
+	}
+
+	first := differences[0]
+	return fmt.Errorf("%d pixels differ from %s by more than %d (first at %d, %d: got %v want %v), diff written to %s",
+		len(differences), goldenPath, tolerance, first.X, first.Y, first.Actual, first.Expected, diffPath)
+}
+
+func CompareScene(scenePath, goldenPath string, tolerance int) error {
+	s, err := scene.LoadFile(scenePath)
+	if err != nil {
+		return err
+	}
+
+	return Compare(goldenPath, s.Camera.RenderWithWorkers(s.World, runtime.NumCPU()), tolerance)
+}
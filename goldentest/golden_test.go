@@ -0,0 +1,163 @@
+package goldentest
+
+import (
+	"context"
+	"fmt"
+	"image/png"
+	"os"
+	"path/filepath"
+	"rtt/canvas"
+	"rtt/sharedtest"
+	"rtt/tuple"
+	"rtt/tupletest"
+	"strings"
+	"testing"
+
+	"github.com/cucumber/godog"
+)
+
+type directory struct{}
+
+type comparison struct{}
+
+func inDir(ctx context.Context, name string) string {
+	return filepath.Join(ctx.Value(directory{}).(string), name)
+}
+
+func getCanvas(ctx context.Context, variable string) *canvas.Canvas {
+	return ctx.Value(sharedtest.Variables{Name: variable}).(*canvas.Canvas)
+}
+
+func aCanvas(ctx context.Context, variable string, width, height int32) (context.Context, error) {
+	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, canvas.NewCanvas(width, height)), nil
+}
+
+func writePixel(ctx context.Context, variable string, x, y int32, colorVariable string) (context.Context, error) {
+	c := getCanvas(ctx, variable)
+	color := ctx.Value(sharedtest.Variables{Name: colorVariable}).(tuple.Color)
+	c.WritePixel(x, y, color)
+	return ctx, nil
+}
+
+func aGoldenImage(ctx context.Context, name, variable string) (context.Context, error) {
+	return ctx, write(inDir(ctx, name), getCanvas(ctx, variable))
+}
+
+func aSceneFile(ctx context.Context, name string, content *godog.DocString) (context.Context, error) {
+	return ctx, os.WriteFile(inDir(ctx, name), []byte(content.Content), 0666)
+}
+
+func setUpdate(ctx context.Context, not string) (context.Context, error) {
+	*Update = not == ""
+	return ctx, nil
+}
+
+func compare(ctx context.Context, variable, name string, tolerance int) (context.Context, error) {
+	err := Compare(inDir(ctx, name), getCanvas(ctx, variable), tolerance)
+	return context.WithValue(ctx, comparison{}, &err), nil
+}
+
+func compareScene(ctx context.Context, sceneName, name string, tolerance int) (context.Context, error) {
+	err := CompareScene(inDir(ctx, sceneName), inDir(ctx, name), tolerance)
+	return context.WithValue(ctx, comparison{}, &err), nil
+}
+
+func assertMatches(ctx context.Context, variable, name string, tolerance int) error {
+	return Compare(inDir(ctx, name), getCanvas(ctx, variable), tolerance)
+}
+
+func assertSceneCompared(ctx context.Context, sceneName, name string, tolerance int) error {
+	return CompareScene(inDir(ctx, sceneName), inDir(ctx, name), tolerance)
+}
+
+func assertSucceeds(ctx context.Context) error {
+	err := *ctx.Value(comparison{}).(*error)
+	if err != nil {
+		return fmt.Errorf("comparison failed: %s", err)
+	}
+	return nil
+}
+
+func assertFails(ctx context.Context, expected string) error {
+	err := *ctx.Value(comparison{}).(*error)
+	if err == nil {
+		return fmt.Errorf("comparison succeeded")
+	}
+	if !strings.Contains(err.Error(), expected) {
+		return fmt.Errorf("error %q does not contain %q", err.Error(), expected)
+	}
+	return nil
+}
+
+func assertExists(ctx context.Context, name string) error {
+	_, err := os.Stat(inDir(ctx, name))
+	return err
+}
+
+func assertDiffMarks(ctx context.Context, name string, not string, x, y int) error {
+	f, err := os.Open(inDir(ctx, name))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	img, err := png.Decode(f)
+	if err != nil {
+		return err
+	}
+
+	r, g, b, _ := img.At(x, y).RGBA()
+	marked := r == 0xffff && g == 0 && b == 0
+
+	if marked == (not != "") {
+		return fmt.Errorf("pixel %d, %d marked was %t", x, y, marked)
+	}
+	return nil
+}
+
+func initializeScenario(sc *godog.ScenarioContext) {
+	sc.Before(func(ctx context.Context, s *godog.Scenario) (context.Context, error) {
+		dir, err := os.MkdirTemp("", "rtt-golden")
+		if err != nil {
+			return ctx, err
+		}
+		return context.WithValue(ctx, directory{}, dir), nil
+	})
+	sc.After(func(ctx context.Context, s *godog.Scenario, err error) (context.Context, error) {
+		*Update = false
+		return ctx, os.RemoveAll(ctx.Value(directory{}).(string))
+	})
+
+	v := sharedtest.TupleVariableName
+	n := sharedtest.PosInt
+
+	tupletest.AddConstructColor(sc)
+	sc.Step(fmt.Sprintf(`^%s ← canvas\(%s, %s\)$`, v, n, n), aCanvas)
+	sc.Step(fmt.Sprintf(`^write_pixel\(%s, %s, %s, %s\)$`, v, n, n, v), writePixel)
+	sc.Step(fmt.Sprintf(`^the golden image "([^"]+)" contains %s$`, v), aGoldenImage)
+	sc.Step(`^a scene file "([^"]+)":$`, aSceneFile)
+	sc.Step(`^the update flag is (not )?set$`, setUpdate)
+	sc.Step(fmt.Sprintf(`^comparing %s against the golden image "([^"]+)" with tolerance %s$`, v, n), compare)
+	sc.Step(fmt.Sprintf(`^comparing the scene "([^"]+)" against the golden image "([^"]+)" with tolerance %s$`, n), compareScene)
+	sc.Step(fmt.Sprintf(`^%s matches the golden image "([^"]+)" with tolerance %s$`, v, n), assertMatches)
+	sc.Step(fmt.Sprintf(`^the scene "([^"]+)" is compared against the golden image "([^"]+)" with tolerance %s$`, n), assertSceneCompared)
+	sc.Step(`^the comparison succeeds$`, assertSucceeds)
+	sc.Step(`^the comparison fails with "(.+)"$`, assertFails)
+	sc.Step(`^the golden image "([^"]+)" exists$`, assertExists)
+	sc.Step(fmt.Sprintf(`^the diff image "([^"]+)" (does not )?marks? pixel %s, %s$`, n, n), assertDiffMarks)
+}
+
+func TestFeatures(t *testing.T) {
+	suite := godog.TestSuite{
+		ScenarioInitializer: initializeScenario,
+		Options: &godog.Options{
+			Format:   "pretty",
+			Paths:    []string{"features/golden.feature"},
+			TestingT: t,
+		},
+	}
+
+	if suite.Run() != 0 {
+		t.Fatal("non-zero exit status")
+	}
+}
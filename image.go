@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"rtt/canvas"
+	"strings"
+)
+
+func imageFormat(path string) (string, error) {
+	ext := strings.ToLower(filepath.Ext(path))
+
+	switch ext {
+	case ".png", ".ppm":
+		return ext, nil
+	default:
+		return "", fmt.Errorf("unsupported image format %q, expected .png or .ppm", ext)
+	}
+}
+
+func writeImage(path string, c *canvas.Canvas) error {
+	format, err := imageFormat(path)
+	if err != nil {
+		return err
+	}
+
+	if format == ".ppm" {
+		return os.WriteFile(path, []byte(*c.ToPPM()), 0666)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := c.WritePNG(f); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
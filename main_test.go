@@ -0,0 +1,204 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image/png"
+	"os"
+	"path/filepath"
+	"rtt/canvas"
+	"rtt/examples"
+	"rtt/matrix"
+	"strings"
+	"testing"
+
+	"github.com/cucumber/godog"
+)
+
+type invocation struct {
+	code   int
+	stdout string
+	stderr string
+}
+
+// The broken example stands in for one whose scene cannot be built.
+func init() {
+	examples.Register(examples.Example{
+		Name:        "broken",
+		Description: "an example with a transform that cannot be inverted",
+		Render: func(workers int) (*canvas.Canvas, error) {
+			return nil, matrix.ErrNotInvertible
+		},
+	})
+}
+
+type workingDirectory struct{}
+
+type lastInvocation struct{}
+
+func unescape(s string) string {
+	return strings.NewReplacer(`\"`, `"`, `\n`, "\n").Replace(s)
+}
+
+func getDir(ctx context.Context) string {
+	return ctx.Value(workingDirectory{}).(string)
+}
+
+func getInvocation(ctx context.Context) *invocation {
+	return ctx.Value(lastInvocation{}).(*invocation)
+}
+
+func aSceneFile(ctx context.Context, name string, content *godog.DocString) (context.Context, error) {
+	return ctx, os.WriteFile(filepath.Join(getDir(ctx), name), []byte(content.Content), 0666)
+}
+
+func iRun(ctx context.Context, commandLine string) (context.Context, error) {
+	fields := strings.Fields(commandLine)
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return ctx, err
+	}
+	if err := os.Chdir(getDir(ctx)); err != nil {
+		return ctx, err
+	}
+	defer os.Chdir(cwd)
+
+	var stdout, stderr bytes.Buffer
+	code := run(fields[1:], &stdout, &stderr)
+
+	result := &invocation{code: code, stdout: stdout.String(), stderr: stderr.String()}
+	return context.WithValue(ctx, lastInvocation{}, result), nil
+}
+
+func assertExitCode(ctx context.Context, expected int) error {
+	result := getInvocation(ctx)
+	if result.code != expected {
+		return fmt.Errorf("exit code was %d not %d, stderr: %s", result.code, expected, result.stderr)
+	}
+	return nil
+}
+
+func assertStderrContains(ctx context.Context, expected string) error {
+	result := getInvocation(ctx)
+	expected = unescape(expected)
+	if !strings.Contains(result.stderr, expected) {
+		return fmt.Errorf("stderr %q does not contain %q", result.stderr, expected)
+	}
+	return nil
+}
+
+func assertStdoutContains(ctx context.Context, expected string) error {
+	result := getInvocation(ctx)
+	expected = unescape(expected)
+	if !strings.Contains(result.stdout, expected) {
+		return fmt.Errorf("stdout %q does not contain %q", result.stdout, expected)
+	}
+	return nil
+}
+
+func assertIdenticalToFixture(ctx context.Context, name, fixture string) error {
+	actual, err := os.ReadFile(filepath.Join(getDir(ctx), name))
+	if err != nil {
+		return err
+	}
+
+	expected, err := os.ReadFile(fixture)
+	if err != nil {
+		return err
+	}
+
+	if !bytes.Equal(actual, expected) {
+		return fmt.Errorf("%s differs from %s", name, fixture)
+	}
+	return nil
+}
+
+func assertIdenticalFiles(ctx context.Context, name, not, other string) error {
+	actual, err := os.ReadFile(filepath.Join(getDir(ctx), name))
+	if err != nil {
+		return err
+	}
+
+	expected, err := os.ReadFile(filepath.Join(getDir(ctx), other))
+	if err != nil {
+		return err
+	}
+
+	identical := bytes.Equal(actual, expected)
+	if identical == (not != "") {
+		return fmt.Errorf("%s and %s identical was %t", name, other, identical)
+	}
+	return nil
+}
+
+func assertPNGSize(ctx context.Context, name string, width, height int) error {
+	f, err := os.Open(filepath.Join(getDir(ctx), name))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	img, err := png.Decode(f)
+	if err != nil {
+		return err
+	}
+
+	size := img.Bounds().Size()
+	if size.X != width || size.Y != height {
+		return fmt.Errorf("image was %dx%d not %dx%d", size.X, size.Y, width, height)
+	}
+	return nil
+}
+
+func assertFilePrefix(ctx context.Context, name, prefix string) error {
+	data, err := os.ReadFile(filepath.Join(getDir(ctx), name))
+	if err != nil {
+		return err
+	}
+
+	prefix = unescape(prefix)
+	if !strings.HasPrefix(string(data), prefix) {
+		return fmt.Errorf("%s does not start with %q", name, prefix)
+	}
+	return nil
+}
+
+func initializeScenario(sc *godog.ScenarioContext) {
+	sc.Before(func(ctx context.Context, s *godog.Scenario) (context.Context, error) {
+		dir, err := os.MkdirTemp("", "rtt-cli")
+		if err != nil {
+			return ctx, err
+		}
+		return context.WithValue(ctx, workingDirectory{}, dir), nil
+	})
+	sc.After(func(ctx context.Context, s *godog.Scenario, err error) (context.Context, error) {
+		return ctx, os.RemoveAll(getDir(ctx))
+	})
+
+	sc.Step(`^a scene file "([^"]+)":$`, aSceneFile)
+	sc.Step(`^I run "(.+)"$`, iRun)
+	sc.Step(`^the exit code is (\d+)$`, assertExitCode)
+	sc.Step(`^stderr contains "(.+)"$`, assertStderrContains)
+	sc.Step(`^stdout contains "(.+)"$`, assertStdoutContains)
+	sc.Step(`^"([^"]+)" is identical to the fixture "([^"]+)"$`, assertIdenticalToFixture)
+	sc.Step(`^"([^"]+)" is (not )?identical to "([^"]+)"$`, assertIdenticalFiles)
+	sc.Step(`^"([^"]+)" is a (\d+)x(\d+) PNG image$`, assertPNGSize)
+	sc.Step(`^"([^"]+)" starts with "(.+)"$`, assertFilePrefix)
+}
+
+func TestFeatures(t *testing.T) {
+	suite := godog.TestSuite{
+		ScenarioInitializer: initializeScenario,
+		Options: &godog.Options{
+			Format:   "pretty",
+			Paths:    []string{"features/cli.feature"},
+			TestingT: t,
+		},
+	}
+
+	if suite.Run() != 0 {
+		t.Fatal("non-zero exit status")
+	}
+}
@@ -0,0 +1,95 @@
+package matrix
+
+import (
+	"rtt/tuple"
+	"testing"
+)
+
+var benchmarkValues = []float64{
+	-5, 2, 6, -8,
+	1, -5, 1, 8,
+	7, 7, -6, -7,
+	1, -3, 7, 4,
+}
+
+var (
+	matrixSink *Matrix
+	mat4Sink   Mat4
+	tupleSink  *tuple.Tuple
+	pointSink  tuple.Point
+)
+
+func BenchmarkMatrixMultiply(b *testing.B) {
+	m, _ := FromValues(4, 4, benchmarkValues)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		matrixSink, _ = m.Multiply(m)
+	}
+}
+
+func BenchmarkMat4Multiply(b *testing.B) {
+	m := Mat4(benchmarkValues)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		mat4Sink = m.Multiply(m)
+	}
+}
+
+func BenchmarkMatrixMultiplyTuple(b *testing.B) {
+	m, _ := FromValues(4, 4, benchmarkValues)
+	t := tuple.NewPoint(1, 2, 3).Tuple()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		tupleSink, _ = m.MultiplyTuple(t)
+	}
+}
+
+func BenchmarkMat4MultiplyTuple(b *testing.B) {
+	m := Mat4(benchmarkValues)
+	t := tuple.NewPoint(1, 2, 3).Tuple()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		tupleSink = m.MultiplyTuple(t)
+	}
+}
+
+func BenchmarkMat4MultiplyPoint(b *testing.B) {
+	m := Mat4(benchmarkValues)
+	p := tuple.NewPoint(1, 2, 3)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		pointSink = m.MultiplyPoint(p)
+	}
+}
+
+func BenchmarkMatrixTranspose(b *testing.B) {
+	m, _ := FromValues(4, 4, benchmarkValues)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		matrixSink = m.Transpose()
+	}
+}
+
+func BenchmarkMat4Transpose(b *testing.B) {
+	m := Mat4(benchmarkValues)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		mat4Sink = m.Transpose()
+	}
+}
+
+func BenchmarkMatrixInvert(b *testing.B) {
+	m, _ := FromValues(4, 4, benchmarkValues)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		matrixSink, _ = m.Invert()
+	}
+}
+
+func BenchmarkMat4Invert(b *testing.B) {
+	m := Mat4(benchmarkValues)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		mat4Sink, _ = m.Invert()
+	}
+}
@@ -1,5 +1,6 @@
 Feature: Matrices
 
+@mat4
 Scenario: Constructing and inspecting a 4x4 matrix
   Given the following 4x4 matrix M:
     |  1   |  2   |  3   |  4   |
@@ -32,6 +33,7 @@ Scenario: A 3x3 matrix ought to be representable
     And M[1,1] = -2
     And M[2,2] = 1
 
+@mat4
 Scenario: Matrix equality with identical matrices
   Given the following 4x4 matrix A:
       | 1 | 2 | 3 | 4 |
@@ -45,6 +47,7 @@ Scenario: Matrix equality with identical matrices
       | 5 | 4 | 3 | 2 |
   Then A = B
 
+@mat4
 Scenario: Matrix equality with different matrices
   Given the following 4x4 matrix A:
       | 1 | 2 | 3 | 4 |
@@ -58,6 +61,35 @@ Scenario: Matrix equality with different matrices
       | 4 | 3 | 2 | 1 |
   Then A != B
 
+@mat4
+Scenario: Matrices with large entries are compared relative to their size
+  Given the following 4x4 matrix A:
+      | 1000000000 | 0 | 0 | 2000000000 |
+      | 0          | 1 | 0 | 0          |
+      | 0          | 0 | 1 | 0          |
+      | 0          | 0 | 0 | 1          |
+    And the following 4x4 matrix B:
+      | 1000000000.5 | 0 | 0 | 2000000001 |
+      | 0            | 1 | 0 | 0          |
+      | 0            | 0 | 1 | 0          |
+      | 0            | 0 | 0 | 1          |
+  Then A = B
+
+@mat4
+Scenario: Small entries are compared with an absolute tolerance
+  Given the following 4x4 matrix A:
+      | 0.000001 | 0 | 0 | 0 |
+      | 0        | 1 | 0 | 0 |
+      | 0        | 0 | 1 | 0 |
+      | 0        | 0 | 0 | 1 |
+    And the following 4x4 matrix B:
+      | 0.0001 | 0 | 0 | 0 |
+      | 0      | 1 | 0 | 0 |
+      | 0      | 0 | 1 | 0 |
+      | 0      | 0 | 0 | 1 |
+  Then A != B
+
+@mat4
 Scenario: Multiplying two matrices
   Given the following 4x4 matrix A:
       | 1 | 2 | 3 | 4 |
@@ -77,6 +109,7 @@ Scenario: Multiplying two matrices
     And D = A * B
   Then C = D
 
+@mat4
 Scenario: A matrix multiplied by a tuple
   Given the following 4x4 matrix A:
       | 1 | 2 | 3 | 4 |
@@ -88,6 +121,7 @@ Scenario: A matrix multiplied by a tuple
     And d = A * b
   Then d = c
 
+@mat4
 Scenario: Multiplying a matrix by the identity matrix
   Given the following 4x4 matrix A:
     | 0 | 1 |  2 |  4 |
@@ -98,12 +132,14 @@ Scenario: Multiplying a matrix by the identity matrix
   And B = A * ID
   Then B = A
 
+@mat4
 Scenario: Multiplying the identity matrix by a tuple
   Given a ← tuple(1, 2, 3, 4)
   And ID = identity_matrix
   And b = ID * a
   Then b = a
 
+@mat4
 Scenario: Transposing a matrix
   Given the following 4x4 matrix A:
     | 0 | 9 | 3 | 0 |
@@ -118,6 +154,7 @@ Scenario: Transposing a matrix
     | 0 | 8 | 3 | 8 |
   Then B = C
 
+@mat4
 Scenario: Transposing the identity matrix
   Given ID = identity_matrix
   And A = transpose(ID)
@@ -192,6 +229,7 @@ Scenario: Calculating the determinant of a 4x4 matrix
     And cofactor(A, 0, 3) = 51
     And determinant(A) = -4071
 
+@mat4
 Scenario: Testing an invertible matrix for invertibility
   Given the following 4x4 matrix A:
     |  6 |  4 |  4 |  4 |
@@ -201,6 +239,7 @@ Scenario: Testing an invertible matrix for invertibility
   Then determinant(A) = -2120
     And A is invertible
 
+@mat4
 Scenario: Testing a noninvertible matrix for invertibility
   Given the following 4x4 matrix A:
     | -4 |  2 | -2 | -3 |
@@ -210,6 +249,24 @@ Scenario: Testing a noninvertible matrix for invertibility
   Then determinant(A) = 0
     And A is not invertible
 
+@mat4
+Scenario: A nearly singular 4x4 matrix is not invertible
+  Given the following 4x4 matrix A:
+    | 1 | 2 | 3                 | 0 |
+    | 4 | 5 | 6                 | 0 |
+    | 7 | 8 | 9.000000000000001 | 0 |
+    | 0 | 0 | 0                 | 1 |
+  Then A is not invertible
+
+@mat4
+Scenario: A small but well conditioned 4x4 matrix is invertible
+  Given the following 4x4 matrix A:
+    | 0.0001 |      0 |      0 | 0 |
+    |      0 | 0.0001 |      0 | 0 |
+    |      0 |      0 | 0.0001 | 0 |
+    |      0 |      0 |      0 | 1 |
+  Then A is invertible
+
 Scenario: Calculating the inverse of a matrix
   Given the following 4x4 matrix A:
       | -5 |  2 |  6 | -8 |
@@ -229,6 +286,7 @@ Scenario: Calculating the inverse of a matrix
       | -0.52256 | -0.81391 | -0.30075 |  0.30639 |
     And A = B
 
+@mat4
 Scenario: Calculating the inverse of another matrix
   Given the following 4x4 matrix A:
     |  8 | -5 |  9 |  2 |
@@ -243,6 +301,7 @@ Scenario: Calculating the inverse of another matrix
     | -0.69231 | -0.69231 | -0.76923 | -1.92308 |
     And B = C
 
+@mat4
 Scenario: Calculating the inverse of a third matrix
   Given the following 4x4 matrix A:
     |  9 |  3 |  0 |  9 |
@@ -257,6 +316,7 @@ Scenario: Calculating the inverse of a third matrix
     |  0.17778 |  0.06667 | -0.26667 |  0.33333 |
     And B = C
 
+@mat4
 Scenario: Multiplying a product by its inverse
   Given the following 4x4 matrix A:
       |  3 | -9 |  7 |  3 |
@@ -272,3 +332,95 @@ Scenario: Multiplying a product by its inverse
     And D ← inverse(B)
     And E = C * D
   Then E = A
+
+Scenario: Multiplying matrices of different shapes
+  Given the following 2x3 matrix A:
+    | 1 | 2 | 3 |
+    | 4 | 5 | 6 |
+    And the following 3x2 matrix B:
+    |  7 |  8 |
+    |  9 | 10 |
+    | 11 | 12 |
+    And the following 2x2 matrix C:
+    |  58 |  64 |
+    | 139 | 154 |
+  When D = A * B
+  Then D = C
+
+Scenario: Multiplying matrices with mismatched dimensions
+  Given the following 2x3 matrix A:
+    | 1 | 2 | 3 |
+    | 4 | 5 | 6 |
+    And the following 2x2 matrix B:
+    | 1 | 2 |
+    | 3 | 4 |
+  Then A * B fails with "cannot multiply a 2x3 matrix by a 2x2 matrix: matrix dimensions do not match"
+
+Scenario: Transposing a non-square matrix
+  Given the following 2x3 matrix A:
+    | 1 | 2 | 3 |
+    | 4 | 5 | 6 |
+    And the following 3x2 matrix C:
+    | 1 | 4 |
+    | 2 | 5 |
+    | 3 | 6 |
+  When B = transpose(A)
+  Then B = C
+
+Scenario: Calculating the determinant of a 5x5 matrix
+  Given the following 5x5 matrix A:
+    | 2 | 0 | 0 | 0 | 0 |
+    | 0 | 3 | 0 | 0 | 0 |
+    | 0 | 0 | 0 | 4 | 0 |
+    | 0 | 0 | 5 | 0 | 0 |
+    | 1 | 0 | 0 | 0 | 1 |
+  Then determinant(A) = -120
+
+Scenario: Calculating the inverse of a 5x5 matrix
+  Given the following 5x5 matrix A:
+    | 2 | 0 | 0 | 0 | 1 |
+    | 0 | 1 | 0 | 0 | 0 |
+    | 0 | 0 | 4 | 0 | 0 |
+    | 0 | 0 | 0 | 1 | 0 |
+    | 1 | 0 | 0 | 0 | 1 |
+    And B ← inverse(A)
+    And C = A * B
+    And the following 5x5 matrix D:
+    |  1 | 0 | 0    | 0 |  -1 |
+    |  0 | 1 | 0    | 0 |   0 |
+    |  0 | 0 | 0.25 | 0 |   0 |
+    |  0 | 0 | 0    | 1 |   0 |
+    | -1 | 0 | 0    | 0 |   2 |
+  Then B = D
+    And C[4,4] = 1
+    And C[0,4] = 0
+
+Scenario: Solving a linear system
+  Given the following 3x3 matrix A:
+    |  2 |  1 | -1 |
+    | -3 | -1 |  2 |
+    | -2 |  1 |  2 |
+    And the following 3x1 matrix B:
+    |   8 |
+    | -11 |
+    |  -3 |
+    And the following 3x1 matrix C:
+    |  2 |
+    |  3 |
+    | -1 |
+  When X ← solve(A, B)
+  Then X = C
+
+Scenario: A nearly singular matrix is not invertible
+  Given the following 3x3 matrix A:
+    | 1 | 2 | 3 |
+    | 4 | 5 | 6 |
+    | 7 | 8 | 9.000000000000001 |
+  Then A is not invertible
+
+Scenario: A matrix whose rows differ greatly in size is invertible
+  Given the following 3x3 matrix A:
+    | 20000000000000 | 0 | 0 |
+    | 0              | 1 | 2 |
+    | 0              | 3 | 4 |
+  Then A is invertible
@@ -0,0 +1,154 @@
+package matrix
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// SingularTolerance is how small a pivot may get, relative to the largest
+// entry of its row, before the matrix is treated as singular.
+const SingularTolerance = 1e-12
+
+var ErrNotSquare = errors.New("matrix is not square")
+
+// LU is the decomposition of a square matrix into lower and upper
+// triangular factors with partial pivoting, so that P*A = L*U. Both factors
+// are packed into one matrix, with L's unit diagonal left implicit.
+//
+// Each row is measured against its own largest entry, both to choose the
+// pivots and to judge whether they are negligible, so that rows of very
+// different sizes do not make the matrix look singular.
+type LU struct {
+	lu     *Matrix
+	pivot  []int
+	sign   float64
+	scales []float64
+}
+
+func (a Matrix) LU() (*LU, error) {
+	if !a.IsSquare() {
+		return nil, fmt.Errorf("cannot decompose a %dx%d matrix: %w", a.height, a.width, ErrNotSquare)
+	}
+
+	n := a.height
+	lu := New(n, n)
+	copy(lu.values, a.values)
+
+	pivot := make([]int, n)
+	for i := range pivot {
+		pivot[i] = i
+	}
+
+	scales := make([]float64, n)
+	for row := range scales {
+		for col := 0; col < n; col++ {
+			scales[row] = math.Max(scales[row], math.Abs(a.At(row, col)))
+		}
+	}
+
+	// relative is the size of an entry next to the largest of the row it
+	// came from.
+	relative := func(row, col int) float64 {
+		if scales[pivot[row]] == 0 {
+			return 0
+		}
+		return math.Abs(lu.At(row, col)) / scales[pivot[row]]
+	}
+
+	sign := 1.0
+
+	for col := 0; col < n; col++ {
+		best := col
+		for row := col + 1; row < n; row++ {
+			if relative(row, col) > relative(best, col) {
+				best = row
+			}
+		}
+
+		if best != col {
+			for x := 0; x < n; x++ {
+				tmp := lu.At(col, x)
+				lu.set(col, x, lu.At(best, x))
+				lu.set(best, x, tmp)
+			}
+			pivot[col], pivot[best] = pivot[best], pivot[col]
+			sign = -sign
+		}
+
+		p := lu.At(col, col)
+		if p == 0 {
+			continue
+		}
+
+		for row := col + 1; row < n; row++ {
+			factor := lu.At(row, col) / p
+			lu.set(row, col, factor)
+			for x := col + 1; x < n; x++ {
+				lu.set(row, x, lu.At(row, x)-factor*lu.At(col, x))
+			}
+		}
+	}
+
+	return &LU{lu: lu, pivot: pivot, sign: sign, scales: scales}, nil
+}
+
+func (d LU) Determinant() float64 {
+	det := d.sign
+	for i := 0; i < d.lu.height; i++ {
+		det *= d.lu.At(i, i)
+	}
+	return det
+}
+
+// IsSingular reports whether any pivot is negligible next to the entries of
+// the row it came from, which catches matrices that are singular up to
+// rounding.
+func (d LU) IsSingular() bool {
+	for i := 0; i < d.lu.height; i++ {
+		scale := d.scales[d.pivot[i]]
+		if scale == 0 || math.Abs(d.lu.At(i, i)) <= SingularTolerance*scale {
+			return true
+		}
+	}
+	return false
+}
+
+// Solve returns X such that A*X = B, solving for every column of B.
+func (d LU) Solve(b *Matrix) (*Matrix, error) {
+	n := d.lu.height
+	if b.height != n {
+		return nil, fmt.Errorf("cannot solve a %dx%d system for a %dx%d matrix: %w", n, n, b.height, b.width, ErrDimensionMismatch)
+	}
+
+	if d.IsSingular() {
+		return nil, ErrNotInvertible
+	}
+
+	x := New(n, b.width)
+	for row := 0; row < n; row++ {
+		for col := 0; col < b.width; col++ {
+			x.set(row, col, b.At(d.pivot[row], col))
+		}
+	}
+
+	for col := 0; col < b.width; col++ {
+		for row := 0; row < n; row++ {
+			v := x.At(row, col)
+			for i := 0; i < row; i++ {
+				v -= d.lu.At(row, i) * x.At(i, col)
+			}
+			x.set(row, col, v)
+		}
+
+		for row := n - 1; row >= 0; row-- {
+			v := x.At(row, col)
+			for i := row + 1; i < n; i++ {
+				v -= d.lu.At(row, i) * x.At(i, col)
+			}
+			x.set(row, col, v/d.lu.At(row, row))
+		}
+	}
+
+	return x, nil
+}
@@ -0,0 +1,215 @@
+package matrix
+
+import (
+	"errors"
+	"math"
+	"rtt/shared"
+	"rtt/tuple"
+)
+
+// Mat4 is a 4x4 matrix stored row by row. Being a plain array it is copied
+// by value, so none of its operations allocate.
+type Mat4 [16]float64
+
+var Identity4 = Mat4{
+	1, 0, 0, 0,
+	0, 1, 0, 0,
+	0, 0, 1, 0,
+	0, 0, 0, 1,
+}
+
+var ErrNotInvertible = errors.New("input matrix not invertible")
+
+func (m Mat4) At(row, col int) float64 {
+	return m[row*4+col]
+}
+
+func (a Mat4) Multiply(b Mat4) Mat4 {
+	return Mat4{
+		a[0]*b[0] + a[1]*b[4] + a[2]*b[8] + a[3]*b[12],
+		a[0]*b[1] + a[1]*b[5] + a[2]*b[9] + a[3]*b[13],
+		a[0]*b[2] + a[1]*b[6] + a[2]*b[10] + a[3]*b[14],
+		a[0]*b[3] + a[1]*b[7] + a[2]*b[11] + a[3]*b[15],
+
+		a[4]*b[0] + a[5]*b[4] + a[6]*b[8] + a[7]*b[12],
+		a[4]*b[1] + a[5]*b[5] + a[6]*b[9] + a[7]*b[13],
+		a[4]*b[2] + a[5]*b[6] + a[6]*b[10] + a[7]*b[14],
+		a[4]*b[3] + a[5]*b[7] + a[6]*b[11] + a[7]*b[15],
+
+		a[8]*b[0] + a[9]*b[4] + a[10]*b[8] + a[11]*b[12],
+		a[8]*b[1] + a[9]*b[5] + a[10]*b[9] + a[11]*b[13],
+		a[8]*b[2] + a[9]*b[6] + a[10]*b[10] + a[11]*b[14],
+		a[8]*b[3] + a[9]*b[7] + a[10]*b[11] + a[11]*b[15],
+
+		a[12]*b[0] + a[13]*b[4] + a[14]*b[8] + a[15]*b[12],
+		a[12]*b[1] + a[13]*b[5] + a[14]*b[9] + a[15]*b[13],
+		a[12]*b[2] + a[13]*b[6] + a[14]*b[10] + a[15]*b[14],
+		a[12]*b[3] + a[13]*b[7] + a[14]*b[11] + a[15]*b[15],
+	}
+}
+
+func (m Mat4) MultiplyTuple(t *tuple.Tuple) *tuple.Tuple {
+	return &tuple.Tuple{
+		X: m[0]*t.X + m[1]*t.Y + m[2]*t.Z + m[3]*t.W,
+		Y: m[4]*t.X + m[5]*t.Y + m[6]*t.Z + m[7]*t.W,
+		Z: m[8]*t.X + m[9]*t.Y + m[10]*t.Z + m[11]*t.W,
+		W: m[12]*t.X + m[13]*t.Y + m[14]*t.Z + m[15]*t.W,
+	}
+}
+
+// MultiplyPoint treats p as a tuple with W = 1, so translations apply.
+func (m Mat4) MultiplyPoint(p tuple.Point) tuple.Point {
+	return tuple.Point{
+		X: m[0]*p.X + m[1]*p.Y + m[2]*p.Z + m[3],
+		Y: m[4]*p.X + m[5]*p.Y + m[6]*p.Z + m[7],
+		Z: m[8]*p.X + m[9]*p.Y + m[10]*p.Z + m[11],
+	}
+}
+
+// MultiplyVector treats v as a tuple with W = 0, so translations are ignored.
+func (m Mat4) MultiplyVector(v tuple.Vector) tuple.Vector {
+	return tuple.Vector{
+		X: m[0]*v.X + m[1]*v.Y + m[2]*v.Z,
+		Y: m[4]*v.X + m[5]*v.Y + m[6]*v.Z,
+		Z: m[8]*v.X + m[9]*v.Y + m[10]*v.Z,
+	}
+}
+
+func (m Mat4) Transpose() Mat4 {
+	return Mat4{
+		m[0], m[4], m[8], m[12],
+		m[1], m[5], m[9], m[13],
+		m[2], m[6], m[10], m[14],
+		m[3], m[7], m[11], m[15],
+	}
+}
+
+// minors returns the determinants of the 2x2 matrices formed by each pair of
+// columns, first in the top two rows and then in the bottom two. Both the
+// determinant and the inverse are built from them.
+func (m Mat4) minors() (s, c [6]float64) {
+	s[0] = m[0]*m[5] - m[4]*m[1]
+	s[1] = m[0]*m[6] - m[4]*m[2]
+	s[2] = m[0]*m[7] - m[4]*m[3]
+	s[3] = m[1]*m[6] - m[5]*m[2]
+	s[4] = m[1]*m[7] - m[5]*m[3]
+	s[5] = m[2]*m[7] - m[6]*m[3]
+
+	c[0] = m[8]*m[13] - m[12]*m[9]
+	c[1] = m[8]*m[14] - m[12]*m[10]
+	c[2] = m[8]*m[15] - m[12]*m[11]
+	c[3] = m[9]*m[14] - m[13]*m[10]
+	c[4] = m[9]*m[15] - m[13]*m[11]
+	c[5] = m[10]*m[15] - m[14]*m[11]
+
+	return s, c
+}
+
+func determinant(s, c [6]float64) float64 {
+	return s[0]*c[5] - s[1]*c[4] + s[2]*c[3] + s[3]*c[2] - s[4]*c[1] + s[5]*c[0]
+}
+
+func (m Mat4) Determinant() float64 {
+	return determinant(m.minors())
+}
+
+func (m Mat4) IsInvertible() bool {
+	return !m.isSingular()
+}
+
+// isSingular reports whether m is singular up to rounding. An affine
+// transform is singular exactly when its 3x3 linear part is, so only that
+// part is tested, and a large translation cannot make a well conditioned
+// transform look singular.
+func (m Mat4) isSingular() bool {
+	if m[12] == 0 && m[13] == 0 && m[14] == 0 && m[15] == 1 {
+		return m.blockIsSingular(3)
+	}
+	return m.blockIsSingular(4)
+}
+
+// blockIsSingular eliminates a copy of the top left n by n block of m with
+// partial pivoting, and reports whether any pivot is negligible next to the
+// largest entry of the block.
+func (m Mat4) blockIsSingular(n int) bool {
+	scale := 0.0
+	for row := 0; row < n; row++ {
+		for col := 0; col < n; col++ {
+			scale = math.Max(scale, math.Abs(m[row*4+col]))
+		}
+	}
+	if scale == 0 {
+		return true
+	}
+
+	a := m
+	for col := 0; col < n; col++ {
+		best := col
+		for row := col + 1; row < n; row++ {
+			if math.Abs(a[row*4+col]) > math.Abs(a[best*4+col]) {
+				best = row
+			}
+		}
+
+		for x := col; x < n; x++ {
+			a[col*4+x], a[best*4+x] = a[best*4+x], a[col*4+x]
+		}
+
+		p := a[col*4+col]
+		if math.Abs(p) <= SingularTolerance*scale {
+			return true
+		}
+
+		for row := col + 1; row < n; row++ {
+			factor := a[row*4+col] / p
+			for x := col + 1; x < n; x++ {
+				a[row*4+x] -= factor * a[col*4+x]
+			}
+		}
+	}
+	return false
+}
+
+func (m Mat4) Invert() (Mat4, error) {
+	if m.isSingular() {
+		return Mat4{}, ErrNotInvertible
+	}
+
+	s, c := m.minors()
+	inv := 1 / determinant(s, c)
+
+	return Mat4{
+		(m[5]*c[5] - m[6]*c[4] + m[7]*c[3]) * inv,
+		(-m[1]*c[5] + m[2]*c[4] - m[3]*c[3]) * inv,
+		(m[13]*s[5] - m[14]*s[4] + m[15]*s[3]) * inv,
+		(-m[9]*s[5] + m[10]*s[4] - m[11]*s[3]) * inv,
+
+		(-m[4]*c[5] + m[6]*c[2] - m[7]*c[1]) * inv,
+		(m[0]*c[5] - m[2]*c[2] + m[3]*c[1]) * inv,
+		(-m[12]*s[5] + m[14]*s[2] - m[15]*s[1]) * inv,
+		(m[8]*s[5] - m[10]*s[2] + m[11]*s[1]) * inv,
+
+		(m[4]*c[4] - m[5]*c[2] + m[7]*c[0]) * inv,
+		(-m[0]*c[4] + m[1]*c[2] - m[3]*c[0]) * inv,
+		(m[12]*s[4] - m[13]*s[2] + m[15]*s[0]) * inv,
+		(-m[8]*s[4] + m[9]*s[2] - m[11]*s[0]) * inv,
+
+		(-m[4]*c[3] + m[5]*c[1] - m[6]*c[0]) * inv,
+		(m[0]*c[3] - m[1]*c[1] + m[2]*c[0]) * inv,
+		(-m[12]*s[3] + m[13]*s[1] - m[14]*s[0]) * inv,
+		(m[8]*s[3] - m[9]*s[1] + m[10]*s[0]) * inv,
+	}, nil
+}
+
+func (a Mat4) Equals(b Mat4) bool {
+	return a.EqualsWithin(b, shared.DefaultTolerance)
+}
+
+func (a Mat4) EqualsWithin(b Mat4, t shared.Tolerance) bool {
+	for i := range a {
+		if !t.Equal(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -2,80 +2,110 @@ package matrix
 
 import (
 	"errors"
-	"math"
+	"fmt"
 	"rtt/shared"
 	"rtt/tuple"
 )
 
+// Matrix is a general matrix of any size stored row by row. Transforms use
+// the fixed-size Mat4 instead.
 type Matrix struct {
 	values []float64
 	width  int
 	height int
 }
 
-func FromValues(values []float64) *Matrix {
-	size := int(math.Sqrt(float64(len(values))))
+var ErrDimensionMismatch = errors.New("matrix dimensions do not match")
+
+func New(rows, cols int) *Matrix {
 	return &Matrix{
-		values: values,
-		width:  size,
-		height: size,
+		values: make([]float64, rows*cols),
+		width:  cols,
+		height: rows,
 	}
 }
 
-func matrix(size int) *Matrix {
-	values := make([]float64, size*size)
-	return &Matrix{
-		values: values,
-		width:  size,
-		height: size,
+func FromValues(rows, cols int, values []float64) (*Matrix, error) {
+	if rows < 1 || cols < 1 {
+		return nil, fmt.Errorf("%dx%d is not a valid matrix size", rows, cols)
 	}
+
+	if len(values) != rows*cols {
+		return nil, fmt.Errorf("expected %d values for a %dx%d matrix, found %d", rows*cols, rows, cols, len(values))
+	}
+
+	m := New(rows, cols)
+	copy(m.values, values)
+	return m, nil
+}
+
+func IdentityN(size int) *Matrix {
+	m := New(size, size)
+	for i := 0; i < size; i++ {
+		m.set(i, i, 1)
+	}
+	return m
 }
 
 func Matrix4() *Matrix {
-	return matrix(4)
+	return New(4, 4)
 }
 
 func Matrix3() *Matrix {
-	return matrix(3)
+	return New(3, 3)
 }
 
 func Matrix2() *Matrix {
-	return matrix(2)
+	return New(2, 2)
 }
 
-var Identity = &Matrix{
-	values: []float64{
-		1, 0, 0, 0,
-		0, 1, 0, 0,
-		0, 0, 1, 0,
-		0, 0, 0, 1,
-	},
-	width:  4,
-	height: 4,
+var Identity = IdentityN(4)
+
+func (m Matrix) Rows() int {
+	return m.height
+}
+
+func (m Matrix) Cols() int {
+	return m.width
+}
+
+func (m Matrix) IsSquare() bool {
+	return m.width == m.height
 }
 
 func (m Matrix) At(y, x int) float64 {
-	return m.values[y*m.height+x]
+	return m.values[y*m.width+x]
 }
 
 func (m Matrix) set(y, x int, value float64) {
-	m.values[y*m.height+x] = value
+	m.values[y*m.width+x] = value
 }
 
-func (a Matrix) Multiply(b *Matrix) *Matrix {
-	m := Matrix4()
+func (a Matrix) Multiply(b *Matrix) (*Matrix, error) {
+	if a.width != b.height {
+		return nil, fmt.Errorf("cannot multiply a %dx%d matrix by a %dx%d matrix: %w", a.height, a.width, b.height, b.width, ErrDimensionMismatch)
+	}
+
+	m := New(a.height, b.width)
 
-	for row := 0; row < 4; row++ {
-		for col := 0; col < 4; col++ {
-			value := a.At(row, 0)*b.At(0, col) + a.At(row, 1)*b.At(1, col) + a.At(row, 2)*b.At(2, col) + a.At(row, 3)*b.At(3, col)
+	for row := 0; row < a.height; row++ {
+		for col := 0; col < b.width; col++ {
+			value := 0.0
+			for i := 0; i < a.width; i++ {
+				value += a.At(row, i) * b.At(i, col)
+			}
 			m.set(row, col, value)
 		}
 	}
 
-	return m
+	return m, nil
 }
 
-func (a Matrix) MultiplyTuple(t *tuple.Tuple) *tuple.Tuple {
+func (a Matrix) MultiplyTuple(t *tuple.Tuple) (*tuple.Tuple, error) {
+	if a.width != 4 || a.height != 4 {
+		return nil, fmt.Errorf("cannot multiply a %dx%d matrix by a tuple: %w", a.height, a.width, ErrDimensionMismatch)
+	}
+
 	result := make([]float64, 4)
 
 	for y := 0; y < 4; y++ {
@@ -93,11 +123,11 @@ func (a Matrix) MultiplyTuple(t *tuple.Tuple) *tuple.Tuple {
 		Y: result[1],
 		Z: result[2],
 		W: result[3],
-	}
+	}, nil
 }
 
 func (a Matrix) Transpose() *Matrix {
-	m := matrix(a.width)
+	m := New(a.width, a.height)
 
 	for y := 0; y < a.height; y++ {
 		for x := 0; x < a.width; x++ {
@@ -108,37 +138,32 @@ func (a Matrix) Transpose() *Matrix {
 	return m
 }
 
-func (a Matrix) Determinant() float64 {
-	result := 0.0
-	if a.width == 2 {
-		result = a.At(0, 0)*a.At(1, 1) - a.At(0, 1)*a.At(1, 0)
-	} else {
-		for x := 0; x < a.width; x++ {
-			result = result + a.At(0, x)*a.Cofactor(0, x)
-		}
+func (a Matrix) Determinant() (float64, error) {
+	lu, err := a.LU()
+	if err != nil {
+		return 0, err
 	}
-	return result
+	return lu.Determinant(), nil
 }
 
 func (a Matrix) Equals(b *Matrix) bool {
+	return a.EqualsWithin(b, shared.DefaultTolerance)
+}
+
+func (a Matrix) EqualsWithin(b *Matrix, t shared.Tolerance) bool {
 	if a.width != b.width || a.height != b.height {
 		return false
 	}
-	for x := 0; x < int(a.width); x++ {
-		for y := 0; y < int(a.height); y++ {
-			valueA := a.At(y, x)
-			valueB := b.At(y, x)
-
-			if !shared.CompareFloat(valueA, valueB) {
-				return false
-			}
+	for i := range a.values {
+		if !t.Equal(a.values[i], b.values[i]) {
+			return false
 		}
 	}
 	return true
 }
 
 func (a Matrix) Submatrix(yToDelete, xToDelete int) *Matrix {
-	res := matrix(a.width - 1)
+	res := New(a.height-1, a.width-1)
 
 	for y := 0; y < a.height; y++ {
 		if y == yToDelete {
@@ -165,39 +190,37 @@ func (a Matrix) Submatrix(yToDelete, xToDelete int) *Matrix {
 	return res
 }
 
-func (a Matrix) Minor(y, x int) float64 {
+func (a Matrix) Minor(y, x int) (float64, error) {
 	return a.Submatrix(y, x).Determinant()
 }
 
-func (a Matrix) Cofactor(y, x int) float64 {
-	minor := a.Minor(y, x)
+func (a Matrix) Cofactor(y, x int) (float64, error) {
+	minor, err := a.Minor(y, x)
 
 	if (y+x)%2 == 0 {
-		return minor
+		return minor, err
 	} else {
-		return -minor
+		return -minor, err
 	}
 }
 
 func (a Matrix) IsInvertible() bool {
-	return a.Determinant() != 0
+	lu, err := a.LU()
+	return err == nil && !lu.IsSingular()
 }
 
 func (a Matrix) Invert() (*Matrix, error) {
-	if !a.IsInvertible() {
-		return nil, errors.New("input matrix not invertible")
+	lu, err := a.LU()
+	if err != nil {
+		return nil, err
 	}
+	return lu.Solve(IdentityN(a.height))
+}
 
-	result := matrix(a.width)
-
-	det := a.Determinant()
-
-	for y := 0; y < a.height; y++ {
-		for x := 0; x < a.width; x++ {
-			cofactor := a.Cofactor(y, x)
-			result.set(x, y, cofactor/det)
-		}
+func (a Matrix) Solve(b *Matrix) (*Matrix, error) {
+	lu, err := a.LU()
+	if err != nil {
+		return nil, err
 	}
-
-	return result, nil
+	return lu.Solve(b)
 }
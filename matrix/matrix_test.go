@@ -15,29 +15,36 @@ import (
 
 type variables struct{ name string }
 
-func aMatrix(ctx context.Context, size int32, name string, table *godog.Table) (context.Context, error) {
-	var m *Matrix = nil
-
-	if size == 4 {
-		m = Matrix4()
-	} else if size == 2 {
-		m = Matrix2()
-	} else if size == 3 {
-		m = Matrix3()
-	} else {
-		return ctx, errors.ErrUnsupported
+// fixedSize is set on scenarios run against Mat4, so 4x4 tables build a Mat4
+// rather than a Matrix.
+type fixedSize struct{}
+
+func usesMat4(ctx context.Context) bool {
+	return ctx.Value(fixedSize{}) != nil
+}
+
+func aMatrix(ctx context.Context, rows, cols int, name string, table *godog.Table) (context.Context, error) {
+	if len(table.Rows) != rows || len(table.Rows[0].Cells) != cols {
+		return ctx, fmt.Errorf("expected a %dx%d table, found %dx%d", rows, cols, len(table.Rows), len(table.Rows[0].Cells))
 	}
 
-	width := len(table.Rows[0].Cells)
-	values := make([]float64, len(table.Rows)*width)
+	values := make([]float64, rows*cols)
 
-	for x := 0; x < width; x++ {
-		for y := 0; y < len(table.Rows); y++ {
+	for x := 0; x < cols; x++ {
+		for y := 0; y < rows; y++ {
 			value, _ := strconv.ParseFloat(table.Rows[y].Cells[x].Value, 64)
-			values[y*width+x] = value
+			values[y*cols+x] = value
 		}
 	}
-	m.values = values
+
+	if rows == 4 && cols == 4 && usesMat4(ctx) {
+		return context.WithValue(ctx, variables{name}, Mat4(values)), nil
+	}
+
+	m, err := FromValues(rows, cols, values)
+	if err != nil {
+		return ctx, err
+	}
 
 	return context.WithValue(ctx, variables{name}, m), nil
 }
@@ -53,10 +60,15 @@ func aTuple(ctx context.Context, variable string, x, y, z, w float64) (context.C
 	return context.WithValue(ctx, variables{name: variable}, &t), nil
 }
 
-func assertComponent(ctx context.Context, name string, x, y int, value float64) (context.Context, error) {
-	m := ctx.Value(variables{name}).(*Matrix)
+func at(ctx context.Context, name string, y, x int) float64 {
+	if m, ok := ctx.Value(variables{name}).(Mat4); ok {
+		return m.At(y, x)
+	}
+	return ctx.Value(variables{name}).(*Matrix).At(y, x)
+}
 
-	c := m.At(x, y)
+func assertComponent(ctx context.Context, name string, x, y int, value float64) (context.Context, error) {
+	c := at(ctx, name, x, y)
 
 	if !shared.CompareFloat(c, value) {
 		return ctx, fmt.Errorf("Expected %f found %f", value, c)
@@ -66,9 +78,7 @@ func assertComponent(ctx context.Context, name string, x, y int, value float64)
 }
 
 func assertComponentFrac(ctx context.Context, name string, x, y int, num, den float64) (context.Context, error) {
-	m := ctx.Value(variables{name}).(*Matrix)
-
-	c := m.At(x, y)
+	c := at(ctx, name, x, y)
 	value := num / den
 
 	if !shared.CompareFloat(c, value) {
@@ -78,16 +88,56 @@ func assertComponentFrac(ctx context.Context, name string, x, y int, num, den fl
 	return ctx, nil
 }
 
-func matrixMultiplication(ctx context.Context, destination, aName, bName string) context.Context {
+func matrixMultiplication(ctx context.Context, destination, aName, bName string) (context.Context, error) {
+	if a, ok := ctx.Value(variables{name: aName}).(Mat4); ok {
+		b := ctx.Value(variables{name: bName}).(Mat4)
+		return context.WithValue(ctx, variables{name: destination}, a.Multiply(b)), nil
+	}
+
 	a := ctx.Value(variables{name: aName}).(*Matrix)
 	b := ctx.Value(variables{name: bName}).(*Matrix)
 
-	m := a.Multiply(b)
+	m, err := a.Multiply(b)
+	if err != nil {
+		return ctx, err
+	}
+
+	return context.WithValue(ctx, variables{name: destination}, m), nil
+}
 
-	return context.WithValue(ctx, variables{name: destination}, m)
+func assertMultiplyFails(ctx context.Context, aName, bName, message string) (context.Context, error) {
+	a := ctx.Value(variables{name: aName}).(*Matrix)
+	b := ctx.Value(variables{name: bName}).(*Matrix)
+
+	_, err := a.Multiply(b)
+	if err == nil {
+		return ctx, fmt.Errorf("Expected %s * %s to fail", aName, bName)
+	}
+
+	if !errors.Is(err, ErrDimensionMismatch) || err.Error() != message {
+		return ctx, fmt.Errorf("Expected error %q found %q", message, err.Error())
+	}
+
+	return ctx, nil
+}
+
+func matrixSolve(ctx context.Context, destination, aName, bName string) (context.Context, error) {
+	a := ctx.Value(variables{name: aName}).(*Matrix)
+	b := ctx.Value(variables{name: bName}).(*Matrix)
+
+	m, err := a.Solve(b)
+	if err != nil {
+		return ctx, err
+	}
+
+	return context.WithValue(ctx, variables{name: destination}, m), nil
 }
 
 func matrixTranspose(ctx context.Context, destination, input string) context.Context {
+	if a, ok := ctx.Value(variables{name: input}).(Mat4); ok {
+		return context.WithValue(ctx, variables{name: destination}, a.Transpose())
+	}
+
 	a := ctx.Value(variables{name: input}).(*Matrix)
 
 	m := a.Transpose()
@@ -95,16 +145,32 @@ func matrixTranspose(ctx context.Context, destination, input string) context.Con
 	return context.WithValue(ctx, variables{name: destination}, m)
 }
 
-func matrixTupleMultiplication(ctx context.Context, destination, aName, bName string) context.Context {
-	a := ctx.Value(variables{name: aName}).(*Matrix)
+func matrixTupleMultiplication(ctx context.Context, destination, aName, bName string) (context.Context, error) {
 	b := ctx.Value(variables{name: bName}).(*tuple.Tuple)
 
-	t := a.MultiplyTuple(b)
+	if a, ok := ctx.Value(variables{name: aName}).(Mat4); ok {
+		return context.WithValue(ctx, variables{name: destination}, a.MultiplyTuple(b)), nil
+	}
+
+	a := ctx.Value(variables{name: aName}).(*Matrix)
+
+	t, err := a.MultiplyTuple(b)
+	if err != nil {
+		return ctx, err
+	}
 
-	return context.WithValue(ctx, variables{name: destination}, t)
+	return context.WithValue(ctx, variables{name: destination}, t), nil
 }
 
 func matrixInvert(ctx context.Context, destination, input string) (context.Context, error) {
+	if a, ok := ctx.Value(variables{name: input}).(Mat4); ok {
+		m, err := a.Invert()
+		if err != nil {
+			return ctx, err
+		}
+		return context.WithValue(ctx, variables{name: destination}, m), nil
+	}
+
 	a := ctx.Value(variables{name: input}).(*Matrix)
 	m, e := a.Invert()
 
@@ -116,22 +182,33 @@ func matrixInvert(ctx context.Context, destination, input string) (context.Conte
 }
 
 func assignIdMatrix(ctx context.Context, destination string) context.Context {
+	if usesMat4(ctx) {
+		return context.WithValue(ctx, variables{name: destination}, Identity4)
+	}
 	return context.WithValue(ctx, variables{name: destination}, Identity)
 }
 
 func assertMatrixEquals(ctx context.Context, aName, operator, bName string) (context.Context, error) {
-	a := ctx.Value(variables{name: aName}).(*Matrix)
-	b := ctx.Value(variables{name: bName}).(*Matrix)
+	var equal bool
 
-	if len(a.values) != len(b.values) {
-		return ctx, fmt.Errorf("Left has %d values, right has %d values!", len(a.values), len(b.values))
+	if a, ok := ctx.Value(variables{name: aName}).(Mat4); ok {
+		equal = a.Equals(ctx.Value(variables{name: bName}).(Mat4))
+	} else {
+		a := ctx.Value(variables{name: aName}).(*Matrix)
+		b := ctx.Value(variables{name: bName}).(*Matrix)
+
+		if len(a.values) != len(b.values) {
+			return ctx, fmt.Errorf("Left has %d values, right has %d values!", len(a.values), len(b.values))
+		}
+
+		equal = a.Equals(b)
 	}
 
-	if operator == "=" && !a.Equals(b) {
+	if operator == "=" && !equal {
 		return ctx, fmt.Errorf("Expected %s = %s", aName, bName)
 	}
 
-	if operator == "!=" && a.Equals(b) {
+	if operator == "!=" && equal {
 		return ctx, fmt.Errorf("Expected %s != %s", aName, bName)
 	}
 
@@ -154,10 +231,20 @@ func assertTupleEquals(ctx context.Context, aName, operator, bName string) (cont
 }
 
 func assertDeterminant(ctx context.Context, name string, expectedResult float64) (context.Context, error) {
-	a := ctx.Value(variables{name}).(*Matrix)
+	var actual float64
+
+	if a, ok := ctx.Value(variables{name}).(Mat4); ok {
+		actual = a.Determinant()
+	} else {
+		det, err := ctx.Value(variables{name}).(*Matrix).Determinant()
+		if err != nil {
+			return ctx, err
+		}
+		actual = det
+	}
 
-	if !shared.CompareFloat(a.Determinant(), expectedResult) {
-		return ctx, fmt.Errorf("Error expected %f actual %f!", expectedResult, a.Determinant())
+	if !shared.CompareFloat(actual, expectedResult) {
+		return ctx, fmt.Errorf("Error expected %f actual %f!", expectedResult, actual)
 	}
 
 	return ctx, nil
@@ -166,8 +253,13 @@ func assertDeterminant(ctx context.Context, name string, expectedResult float64)
 func assertMinor(ctx context.Context, name string, y, x int, expectedResult float64) (context.Context, error) {
 	a := ctx.Value(variables{name}).(*Matrix)
 
-	if !shared.CompareFloat(a.Minor(y, x), expectedResult) {
-		return ctx, fmt.Errorf("Error expected %f actual %f!", expectedResult, a.Minor(y, x))
+	actual, err := a.Minor(y, x)
+	if err != nil {
+		return ctx, err
+	}
+
+	if !shared.CompareFloat(actual, expectedResult) {
+		return ctx, fmt.Errorf("Error expected %f actual %f!", expectedResult, actual)
 	}
 
 	return ctx, nil
@@ -176,8 +268,13 @@ func assertMinor(ctx context.Context, name string, y, x int, expectedResult floa
 func assertCofactor(ctx context.Context, name string, y, x int, expectedResult float64) (context.Context, error) {
 	a := ctx.Value(variables{name}).(*Matrix)
 
-	if !shared.CompareFloat(a.Cofactor(y, x), expectedResult) {
-		return ctx, fmt.Errorf("Error expected %f actual %f!", expectedResult, a.Cofactor(y, x))
+	actual, err := a.Cofactor(y, x)
+	if err != nil {
+		return ctx, err
+	}
+
+	if !shared.CompareFloat(actual, expectedResult) {
+		return ctx, fmt.Errorf("Error expected %f actual %f!", expectedResult, actual)
 	}
 
 	return ctx, nil
@@ -195,13 +292,19 @@ func assertSubmatrix(ctx context.Context, name string, row, col int, expectedRes
 }
 
 func assertInvertible(ctx context.Context, name, not string) (context.Context, error) {
-	a := ctx.Value(variables{name}).(*Matrix)
+	var invertible bool
+
+	if a, ok := ctx.Value(variables{name}).(Mat4); ok {
+		invertible = a.IsInvertible()
+	} else {
+		invertible = ctx.Value(variables{name}).(*Matrix).IsInvertible()
+	}
 
-	if not == "" && !a.IsInvertible() {
+	if not == "" && !invertible {
 		return ctx, fmt.Errorf("Expected %s to be invertible", name)
 	}
 
-	if not == "not " && a.IsInvertible() {
+	if not == "not " && invertible {
 		return ctx, fmt.Errorf("Expected %s to not be invertible", name)
 	}
 
@@ -215,7 +318,7 @@ func assignSubmatrix(ctx context.Context, destination, input string, row, col in
 }
 
 func matrixConstructors(ctx *godog.ScenarioContext) {
-	ctx.Step(`^the following (\d+)x(?:\d+) matrix (.+):$`, aMatrix)
+	ctx.Step(`^the following (\d+)x(\d+) matrix (.+):$`, aMatrix)
 	regex := fmt.Sprintf(`^(.+) ← tuple\(%s, %s, %s, %s\)$`, sharedtest.Decimal, sharedtest.Decimal, sharedtest.Decimal, sharedtest.Decimal)
 	ctx.Step(regex, aTuple)
 	regex = fmt.Sprintf(`^%s ← submatrix\(%s, %s, %s\)$`, sharedtest.MatrixVariableName, sharedtest.MatrixVariableName, sharedtest.PosInt, sharedtest.PosInt)
@@ -223,10 +326,10 @@ func matrixConstructors(ctx *godog.ScenarioContext) {
 }
 
 func matrixAssertions(ctx *godog.ScenarioContext) {
-	regex := fmt.Sprintf(`^%s\[%s,%s\] = %s$`, sharedtest.MatrixVariableName, sharedtest.PosInt, sharedtest.PosInt, sharedtest.Decimal)
-	ctx.Step(regex, assertComponent)
-	regex = fmt.Sprintf(`^%s\[%s,%s\] = %s/%s$`, sharedtest.MatrixVariableName, sharedtest.PosInt, sharedtest.PosInt, sharedtest.Decimal, sharedtest.Decimal)
+	regex := fmt.Sprintf(`^%s\[%s,%s\] = %s/%s$`, sharedtest.MatrixVariableName, sharedtest.PosInt, sharedtest.PosInt, sharedtest.Decimal, sharedtest.Decimal)
 	ctx.Step(regex, assertComponentFrac)
+	regex = fmt.Sprintf(`^%s\[%s,%s\] = %s$`, sharedtest.MatrixVariableName, sharedtest.PosInt, sharedtest.PosInt, sharedtest.Decimal)
+	ctx.Step(regex, assertComponent)
 	regex = fmt.Sprintf(`^%s (=|!=) %s$`, sharedtest.MatrixVariableName, sharedtest.MatrixVariableName)
 	ctx.Step(regex, assertMatrixEquals)
 	regex = fmt.Sprintf(`^%s (=|!=) %s$`, sharedtest.TupleVariableName, sharedtest.TupleVariableName)
@@ -243,6 +346,8 @@ func matrixAssertions(ctx *godog.ScenarioContext) {
 	ctx.Step(regex, assertCofactor)
 	regex = fmt.Sprintf(`^%s is (not )?invertible$`, sharedtest.MatrixVariableName)
 	ctx.Step(regex, assertInvertible)
+	regex = fmt.Sprintf(`^%s \* %s fails with "(.+)"$`, sharedtest.MatrixVariableName, sharedtest.MatrixVariableName)
+	ctx.Step(regex, assertMultiplyFails)
 }
 
 func matrixAssignments(ctx *godog.ScenarioContext) {
@@ -253,6 +358,8 @@ func matrixAssignments(ctx *godog.ScenarioContext) {
 	ctx.Step(fmt.Sprintf(`^%s = identity_matrix$`, sharedtest.MatrixVariableName), assignIdMatrix)
 	regex = fmt.Sprintf(`^%s ← inverse\(%s\)$`, sharedtest.MatrixVariableName, sharedtest.MatrixVariableName)
 	ctx.Step(regex, matrixInvert)
+	regex = fmt.Sprintf(`^%s ← solve\(%s, %s\)$`, sharedtest.MatrixVariableName, sharedtest.MatrixVariableName, sharedtest.MatrixVariableName)
+	ctx.Step(regex, matrixSolve)
 }
 
 func InitializeScenario(ctx *godog.ScenarioContext) {
@@ -261,6 +368,13 @@ func InitializeScenario(ctx *godog.ScenarioContext) {
 	matrixAssertions(ctx)
 }
 
+func InitializeMat4Scenario(ctx *godog.ScenarioContext) {
+	ctx.Before(func(ctx context.Context, sc *godog.Scenario) (context.Context, error) {
+		return context.WithValue(ctx, fixedSize{}, true), nil
+	})
+	InitializeScenario(ctx)
+}
+
 func TestFeatures(t *testing.T) {
 	suite := godog.TestSuite{
 		ScenarioInitializer: InitializeScenario,
@@ -275,3 +389,19 @@ func TestFeatures(t *testing.T) {
 		t.Fatal("non-zero exit status")
 	}
 }
+
+func TestMat4Features(t *testing.T) {
+	suite := godog.TestSuite{
+		ScenarioInitializer: InitializeMat4Scenario,
+		Options: &godog.Options{
+			Format:   "pretty",
+			Paths:    []string{"features/matrices.feature"},
+			Tags:     "@mat4",
+			TestingT: t,
+		},
+	}
+
+	if suite.Run() != 0 {
+		t.Fatal("non-zero exit status")
+	}
+}
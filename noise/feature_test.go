@@ -0,0 +1,116 @@
+package noise
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"rtt/shared"
+	"rtt/sharedtest"
+	"rtt/tuple"
+	"rtt/tupletest"
+	"testing"
+
+	"github.com/cucumber/godog"
+)
+
+func getPoint(ctx context.Context, variable string) tuple.Point {
+	return ctx.Value(sharedtest.Variables{Name: variable}).(tuple.Point)
+}
+
+// grid calls f with every point of a size by size by size grid of points
+// spacing apart, offset from the lattice so that the noise is not simply 0.
+func grid(size int, spacing float64, f func(tuple.Point) error) error {
+	for i := 0; i < size; i++ {
+		for j := 0; j < size; j++ {
+			for k := 0; k < size; k++ {
+				p := tuple.NewPoint(0.05+float64(i)*spacing, 0.07+float64(j)*spacing, 0.11+float64(k)*spacing)
+				if err := f(p); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func assertPerlin(ctx context.Context, variable string, expected float64) error {
+	actual := Perlin(getPoint(ctx, variable))
+	if !shared.CompareFloat(actual, expected) {
+		return fmt.Errorf("Error perlin %g != %g!", actual, expected)
+	}
+	return nil
+}
+
+func assertSamePerlin(ctx context.Context, a, b string) error {
+	pa, pb := Perlin(getPoint(ctx, a)), Perlin(getPoint(ctx, b))
+	if !shared.CompareFloat(pa, pb) {
+		return fmt.Errorf("Error perlin %g != %g!", pa, pb)
+	}
+	return nil
+}
+
+func assertPerlinBounded(ctx context.Context, lower, upper float64, size int, spacing float64) error {
+	return grid(size, spacing, func(p tuple.Point) error {
+		if n := Perlin(p); n < lower || n > upper {
+			return fmt.Errorf("Error perlin at %+v is %g!", p, n)
+		}
+		return nil
+	})
+}
+
+func assertPerlinSmooth(ctx context.Context, limit, step float64) error {
+	return grid(20, 0.13, func(p tuple.Point) error {
+		q := tuple.NewPoint(p.X+step, p.Y+step, p.Z+step)
+		if change := math.Abs(Perlin(q) - Perlin(p)); change >= limit {
+			return fmt.Errorf("Error perlin changes by %g from %+v to %+v!", change, p, q)
+		}
+		return nil
+	})
+}
+
+func assertTurbulence(ctx context.Context, variable string, octaves int, expected float64) error {
+	actual := Turbulence(getPoint(ctx, variable), octaves)
+	if !shared.CompareFloat(actual, expected) {
+		return fmt.Errorf("Error turbulence %g != %g!", actual, expected)
+	}
+	return nil
+}
+
+func assertTurbulenceOctaves(ctx context.Context, variable string, octaves int, first string, firstOctaves int, second string, secondOctaves int) error {
+	actual := Turbulence(getPoint(ctx, variable), octaves)
+	expected := Turbulence(getPoint(ctx, first), firstOctaves) + Turbulence(getPoint(ctx, second), secondOctaves)/2
+	if !shared.CompareFloat(actual, expected) {
+		return fmt.Errorf("Error turbulence %g != %g!", actual, expected)
+	}
+	return nil
+}
+
+func InitializeScenario(sc *godog.ScenarioContext) {
+	v := sharedtest.TupleVariableName
+	d := sharedtest.Decimal
+	n := sharedtest.PosInt
+
+	tupletest.AddConstructPoint(sc)
+
+	sc.Step(fmt.Sprintf(`^perlin\(%s\) = %s$`, v, d), assertPerlin)
+	sc.Step(fmt.Sprintf(`^perlin\(%s\) = perlin\(%s\)$`, v, v), assertSamePerlin)
+	sc.Step(fmt.Sprintf(`^perlin stays between %s and %s over a grid of %s(?:x\d+)* points %s apart$`, d, d, n, d), assertPerlinBounded)
+	sc.Step(fmt.Sprintf(`^perlin changes by less than %s between points %s apart over the same grid$`, d, d), assertPerlinSmooth)
+	sc.Step(fmt.Sprintf(`^turbulence\(%s, %s\) = %s$`, v, n, d), assertTurbulence)
+	sc.Step(fmt.Sprintf(`^turbulence\(%s, %s\) = turbulence\(%s, %s\) \+ turbulence\(%s, %s\) / 2$`, v, n, v, n, v, n), assertTurbulenceOctaves)
+}
+
+func TestFeatures(t *testing.T) {
+	suite := godog.TestSuite{
+		ScenarioInitializer: InitializeScenario,
+		Options: &godog.Options{
+			Format:   "pretty",
+			Paths:    []string{"features/noise.feature"},
+			TestingT: t,
+		},
+	}
+
+	if suite.Run() != 0 {
+		t.Fatal("non-zero exit status")
+	}
+}
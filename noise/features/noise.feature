@@ -0,0 +1,34 @@
+Feature: Noise
+
+Scenario: Perlin noise matches the reference implementation
+  Given p ← point(3.14, 42, 7)
+  Then perlin(p) = 0.13692
+
+Scenario Outline: Perlin noise is 0 on the lattice
+  Given p ← point(<x>, <y>, <z>)
+  Then perlin(p) = 0
+
+  Examples:
+    | x   | y   | z  |
+    | 0   | 0   | 0  |
+    | 1   | 2   | 3  |
+    | -4  | 7   | -1 |
+    | 256 | 512 | 0  |
+
+Scenario: Perlin noise repeats every 256 units
+  Given p ← point(0.3, 1.7, 2.2)
+    And q ← point(256.3, 1.7, -253.8)
+  Then perlin(p) = perlin(q)
+
+Scenario: Perlin noise stays between -1 and 1 and changes smoothly
+  Then perlin stays between -1 and 1 over a grid of 20x20x20 points 0.13 apart
+    And perlin changes by less than 0.01 between points 0.001 apart over the same grid
+
+Scenario: Turbulence of one octave is the size of the noise
+  Given p ← point(3.14, 42, 7)
+  Then turbulence(p, 1) = 0.13692
+
+Scenario: Each octave of turbulence adds detail at half the size
+  Given p ← point(0.3, 1.7, 2.2)
+    And q ← point(0.6, 3.4, 4.4)
+  Then turbulence(p, 2) = turbulence(p, 1) + turbulence(q, 1) / 2
@@ -0,0 +1,170 @@
+package quaternion
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"rtt/matrix"
+	"rtt/shared"
+	"rtt/sharedtest"
+	"rtt/tuple"
+	"rtt/tupletest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/cucumber/godog"
+)
+
+func getQuaternion(ctx context.Context, variable string) Quaternion {
+	return ctx.Value(sharedtest.Variables{Name: variable}).(Quaternion)
+}
+
+func parseComponents(ws, xs, ys, zs string) (Quaternion, error) {
+	w, err := sharedtest.ParseDecimal(ws)
+	if err != nil {
+		return Quaternion{}, err
+	}
+
+	x, y, z, err := sharedtest.ParseXYZ(xs, ys, zs)
+	if err != nil {
+		return Quaternion{}, err
+	}
+
+	return New(w, x, y, z), nil
+}
+
+func aQuaternion(ctx context.Context, variable, w, x, y, z string) (context.Context, error) {
+	q, err := parseComponents(w, x, y, z)
+	if err != nil {
+		return ctx, err
+	}
+	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, q), nil
+}
+
+func anAxisAngle(ctx context.Context, variable, xs, ys, zs string, divisor float64) (context.Context, error) {
+	x, y, z, err := sharedtest.ParseXYZ(xs, ys, zs)
+	if err != nil {
+		return ctx, err
+	}
+
+	q := FromAxisAngle(tuple.NewVector(x, y, z), math.Pi/divisor)
+	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, q), nil
+}
+
+func aRotation(ctx context.Context, variable, quaternionVariable, vectorVariable string) (context.Context, error) {
+	q := getQuaternion(ctx, quaternionVariable)
+	v := ctx.Value(sharedtest.Variables{Name: vectorVariable}).(tuple.Vector)
+	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, q.Rotate(v)), nil
+}
+
+func aRotationMatrix(ctx context.Context, variable, quaternionVariable string) (context.Context, error) {
+	m := getQuaternion(ctx, quaternionVariable).Matrix()
+	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, m), nil
+}
+
+func aQuaternionFromMatrix(ctx context.Context, variable, matrixVariable string) (context.Context, error) {
+	m := ctx.Value(sharedtest.Variables{Name: matrixVariable}).(matrix.Mat4)
+	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, FromMatrix(m)), nil
+}
+
+func aProduct(ctx context.Context, variable, left, right string) (context.Context, error) {
+	q := getQuaternion(ctx, left).Multiply(getQuaternion(ctx, right))
+	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, q), nil
+}
+
+func aSlerp(ctx context.Context, variable, a, b string, t float64) (context.Context, error) {
+	q := Slerp(getQuaternion(ctx, a), getQuaternion(ctx, b), t)
+	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, q), nil
+}
+
+func assertQuaternion(ctx context.Context, variable, w, x, y, z string) error {
+	expected, err := parseComponents(w, x, y, z)
+	if err != nil {
+		return err
+	}
+
+	actual := getQuaternion(ctx, variable)
+	if !actual.Equals(expected) {
+		return fmt.Errorf("Error %+v != %+v!", actual, expected)
+	}
+	return nil
+}
+
+func assertSameQuaternion(ctx context.Context, variable, other string) error {
+	actual := getQuaternion(ctx, variable)
+	expected := getQuaternion(ctx, other)
+
+	if !actual.Equals(expected) {
+		return fmt.Errorf("Error %+v != %+v!", actual, expected)
+	}
+	return nil
+}
+
+func assertMagnitude(ctx context.Context, variable string, expected float64) error {
+	actual := getQuaternion(ctx, variable).Magnitude()
+	if !shared.CompareFloat(actual, expected) {
+		return fmt.Errorf("Error %f != %f!", actual, expected)
+	}
+	return nil
+}
+
+func assertMatrix(ctx context.Context, variable string, table *godog.Table) error {
+	values := []float64{}
+
+	for _, row := range table.Rows {
+		for _, cell := range row.Cells {
+			value, err := strconv.ParseFloat(strings.TrimSpace(cell.Value), 64)
+			if err != nil {
+				return err
+			}
+			values = append(values, value)
+		}
+	}
+
+	if len(values) != 16 {
+		return fmt.Errorf("expected a 4x4 matrix, found %d values", len(values))
+	}
+
+	actual := ctx.Value(sharedtest.Variables{Name: variable}).(matrix.Mat4)
+	if !actual.Equals(matrix.Mat4(values)) {
+		return fmt.Errorf("Error %+v != %+v!", actual, values)
+	}
+	return nil
+}
+
+func InitializeScenario(sc *godog.ScenarioContext) {
+	v := sharedtest.TupleVariableName
+	d := sharedtest.Decimal
+
+	tupletest.AddConstructVector(sc)
+	tupletest.AddCompareVector(sc)
+
+	sc.Step(fmt.Sprintf(`^%s ← quaternion\(%s, %s, %s, %s\)$`, v, d, d, d, d), aQuaternion)
+	sc.Step(fmt.Sprintf(`^%s ← axis_angle\(vector\(%s, %s, %s\), π / %s\)$`, v, d, d, d, sharedtest.PosInt), anAxisAngle)
+	sc.Step(fmt.Sprintf(`^%s ← rotate\(%s, %s\)$`, v, v, v), aRotation)
+	sc.Step(fmt.Sprintf(`^%s ← rotation_matrix\(%s\)$`, v, v), aRotationMatrix)
+	sc.Step(fmt.Sprintf(`^%s ← from_matrix\(%s\)$`, v, v), aQuaternionFromMatrix)
+	sc.Step(fmt.Sprintf(`^%s ← %s \* %s$`, v, v, v), aProduct)
+	sc.Step(fmt.Sprintf(`^%s ← slerp\(%s, %s, %s\)$`, v, v, v, d), aSlerp)
+
+	sc.Step(fmt.Sprintf(`^%s = quaternion\(%s, %s, %s, %s\)$`, v, d, d, d, d), assertQuaternion)
+	sc.Step(fmt.Sprintf(`^%s = %s$`, v, v), assertSameQuaternion)
+	sc.Step(fmt.Sprintf(`^magnitude\(%s\) = %s$`, v, d), assertMagnitude)
+	sc.Step(fmt.Sprintf(`^%s is the following 4x4 matrix:$`, v), assertMatrix)
+}
+
+func TestFeatures(t *testing.T) {
+	suite := godog.TestSuite{
+		ScenarioInitializer: InitializeScenario,
+		Options: &godog.Options{
+			Format:   "pretty",
+			Paths:    []string{"features/quaternions.feature"},
+			TestingT: t,
+		},
+	}
+
+	if suite.Run() != 0 {
+		t.Fatal("non-zero exit status")
+	}
+}
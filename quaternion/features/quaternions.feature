@@ -0,0 +1,82 @@
+Feature: Quaternions
+
+Scenario: The identity quaternion does not rotate
+  Given q ← quaternion(1, 0, 0, 0)
+    And v ← vector(1, 2, 3)
+  When r ← rotate(q, v)
+  Then r = vector(1, 2, 3)
+
+Scenario: A quaternion from an axis and angle
+  Given q ← axis_angle(vector(0, 0, 2), π / 2)
+  Then q = quaternion(√2/2, 0, 0, √2/2)
+    And magnitude(q) = 1
+
+Scenario: Rotating a vector with a quaternion
+  Given q ← axis_angle(vector(0, 1, 0), π / 2)
+    And v ← vector(0, 0, 1)
+  When r ← rotate(q, v)
+  Then r = vector(1, 0, 0)
+
+Scenario: Converting a quaternion to a rotation matrix
+  Given q ← axis_angle(vector(1, 0, 0), π / 2)
+  When m ← rotation_matrix(q)
+  Then m is the following 4x4 matrix:
+    | 1 | 0 |  0 | 0 |
+    | 0 | 0 | -1 | 0 |
+    | 0 | 1 |  0 | 0 |
+    | 0 | 0 |  0 | 1 |
+
+Scenario Outline: Converting a rotation matrix to a quaternion
+  Given q ← axis_angle(vector(<x>, <y>, <z>), π / <divisor>)
+  When m ← rotation_matrix(q)
+    And r ← from_matrix(m)
+  Then r = q
+
+  Examples:
+    | x  | y  | z  | divisor |
+    | 1  | 0  | 0  | 4       |
+    | 0  | 1  | 0  | 1       |
+    | 0  | 0  | 1  | 1       |
+    | 1  | 0  | 0  | 1       |
+    | 1  | 1  | 1  | 3       |
+    | -2 | 1  | 3  | 6       |
+
+Scenario: A quaternion and its negation are the same rotation
+  Given q ← quaternion(√2/2, 0, √2/2, 0)
+  Then q = quaternion(-√2/2, 0, -√2/2, 0)
+
+Scenario: Multiplying quaternions combines their rotations
+  Given a ← axis_angle(vector(1, 0, 0), π / 2)
+    And b ← axis_angle(vector(0, 0, 1), π / 2)
+    And v ← vector(0, 0, 1)
+  When q ← b * a
+    And r ← rotate(q, v)
+  Then r = vector(1, 0, 0)
+
+Scenario: Slerp at the ends returns the end rotations
+  Given a ← axis_angle(vector(0, 1, 0), π / 4)
+    And b ← axis_angle(vector(1, 0, 0), π / 2)
+  When q0 ← slerp(a, b, 0)
+    And q1 ← slerp(a, b, 1)
+  Then q0 = a
+    And q1 = b
+
+Scenario: Slerp halfway rotates by half the angle
+  Given a ← quaternion(1, 0, 0, 0)
+    And b ← axis_angle(vector(0, 0, 1), π / 2)
+  When q ← slerp(a, b, 0.5)
+  Then q = quaternion(0.92388, 0, 0, 0.38268)
+    And magnitude(q) = 1
+
+Scenario: Slerp takes the shorter way around
+  Given a ← quaternion(1, 0, 0, 0)
+    And b ← quaternion(-0.70711, 0, 0, -0.70711)
+  When q ← slerp(a, b, 0.5)
+  Then q = quaternion(0.92388, 0, 0, 0.38268)
+
+Scenario: Slerp between nearly identical rotations
+  Given a ← quaternion(1, 0, 0, 0)
+    And b ← quaternion(1, 0, 0, 0.000001)
+  When q ← slerp(a, b, 0.5)
+  Then q = quaternion(1, 0, 0, 0)
+    And magnitude(q) = 1
@@ -0,0 +1,147 @@
+package quaternion
+
+import (
+	"math"
+	"rtt/matrix"
+	"rtt/shared"
+	"rtt/tuple"
+)
+
+// Quaternion represents a rotation as W + Xi + Yj + Zk. Rotations are unit
+// quaternions, and q and -q describe the same rotation.
+type Quaternion struct {
+	W float64
+	X float64
+	Y float64
+	Z float64
+}
+
+var Identity = Quaternion{W: 1}
+
+func New(w, x, y, z float64) Quaternion {
+	return Quaternion{W: w, X: x, Y: y, Z: z}
+}
+
+// FromAxisAngle returns the rotation by angle radians around axis, matching
+// transformations.RotationAxisAngle.
+func FromAxisAngle(axis tuple.Vector, angle float64) Quaternion {
+	a := axis.Normalize()
+	s := math.Sin(angle / 2)
+	return Quaternion{W: math.Cos(angle / 2), X: a.X * s, Y: a.Y * s, Z: a.Z * s}
+}
+
+// FromMatrix returns the rotation in the upper 3x3 of m, which must be a
+// pure rotation without scaling or shearing.
+func FromMatrix(m matrix.Mat4) Quaternion {
+	trace := m[0] + m[5] + m[10]
+
+	var q Quaternion
+
+	switch {
+	case trace > 0:
+		s := 2 * math.Sqrt(trace+1)
+		q = Quaternion{W: s / 4, X: (m[9] - m[6]) / s, Y: (m[2] - m[8]) / s, Z: (m[4] - m[1]) / s}
+	case m[0] > m[5] && m[0] > m[10]:
+		s := 2 * math.Sqrt(1+m[0]-m[5]-m[10])
+		q = Quaternion{W: (m[9] - m[6]) / s, X: s / 4, Y: (m[1] + m[4]) / s, Z: (m[2] + m[8]) / s}
+	case m[5] > m[10]:
+		s := 2 * math.Sqrt(1+m[5]-m[0]-m[10])
+		q = Quaternion{W: (m[2] - m[8]) / s, X: (m[1] + m[4]) / s, Y: s / 4, Z: (m[6] + m[9]) / s}
+	default:
+		s := 2 * math.Sqrt(1+m[10]-m[0]-m[5])
+		q = Quaternion{W: (m[4] - m[1]) / s, X: (m[2] + m[8]) / s, Y: (m[6] + m[9]) / s, Z: s / 4}
+	}
+
+	return q.Normalize()
+}
+
+func (q Quaternion) Matrix() matrix.Mat4 {
+	xx, yy, zz := q.X*q.X, q.Y*q.Y, q.Z*q.Z
+	xy, xz, yz := q.X*q.Y, q.X*q.Z, q.Y*q.Z
+	wx, wy, wz := q.W*q.X, q.W*q.Y, q.W*q.Z
+
+	return matrix.Mat4{
+		1 - 2*(yy+zz), 2 * (xy - wz), 2 * (xz + wy), 0,
+		2 * (xy + wz), 1 - 2*(xx+zz), 2 * (yz - wx), 0,
+		2 * (xz - wy), 2 * (yz + wx), 1 - 2*(xx+yy), 0,
+		0, 0, 0, 1,
+	}
+}
+
+// Multiply returns the rotation that applies b first and then q.
+func (q Quaternion) Multiply(b Quaternion) Quaternion {
+	return Quaternion{
+		W: q.W*b.W - q.X*b.X - q.Y*b.Y - q.Z*b.Z,
+		X: q.W*b.X + q.X*b.W + q.Y*b.Z - q.Z*b.Y,
+		Y: q.W*b.Y - q.X*b.Z + q.Y*b.W + q.Z*b.X,
+		Z: q.W*b.Z + q.X*b.Y - q.Y*b.X + q.Z*b.W,
+	}
+}
+
+func (q Quaternion) Conjugate() Quaternion {
+	return Quaternion{W: q.W, X: -q.X, Y: -q.Y, Z: -q.Z}
+}
+
+func (q Quaternion) Negate() Quaternion {
+	return Quaternion{W: -q.W, X: -q.X, Y: -q.Y, Z: -q.Z}
+}
+
+func (q Quaternion) Dot(b Quaternion) float64 {
+	return q.W*b.W + q.X*b.X + q.Y*b.Y + q.Z*b.Z
+}
+
+func (q Quaternion) Magnitude() float64 {
+	return math.Sqrt(q.Dot(q))
+}
+
+func (q Quaternion) Normalize() Quaternion {
+	mag := q.Magnitude()
+	return Quaternion{W: q.W / mag, X: q.X / mag, Y: q.Y / mag, Z: q.Z / mag}
+}
+
+func (q Quaternion) Rotate(v tuple.Vector) tuple.Vector {
+	r := q.Multiply(Quaternion{X: v.X, Y: v.Y, Z: v.Z}).Multiply(q.Conjugate())
+	return tuple.NewVector(r.X, r.Y, r.Z)
+}
+
+// Slerp interpolates between the rotations a and b at a constant angular
+// speed, taking the shorter way around. t = 0 gives a and t = 1 gives b.
+func Slerp(a, b Quaternion, t float64) Quaternion {
+	dot := a.Dot(b)
+	if dot < 0 {
+		b = b.Negate()
+		dot = -dot
+	}
+
+	// Nearly identical rotations would divide by a tiny sine, and a straight
+	// line between them is indistinguishable from the arc.
+	if dot > 1-shared.Epsilon {
+		return Quaternion{
+			W: a.W + (b.W-a.W)*t,
+			X: a.X + (b.X-a.X)*t,
+			Y: a.Y + (b.Y-a.Y)*t,
+			Z: a.Z + (b.Z-a.Z)*t,
+		}.Normalize()
+	}
+
+	theta := math.Acos(dot)
+	sin := math.Sin(theta)
+	wa := math.Sin((1-t)*theta) / sin
+	wb := math.Sin(t*theta) / sin
+
+	return Quaternion{
+		W: a.W*wa + b.W*wb,
+		X: a.X*wa + b.X*wb,
+		Y: a.Y*wa + b.Y*wb,
+		Z: a.Z*wa + b.Z*wb,
+	}
+}
+
+func (q Quaternion) sameComponents(b Quaternion) bool {
+	return shared.CompareFloat(q.W, b.W) && shared.CompareFloat(q.X, b.X) && shared.CompareFloat(q.Y, b.Y) && shared.CompareFloat(q.Z, b.Z)
+}
+
+// Equals compares rotations, so q and -q are equal.
+func (q Quaternion) Equals(b Quaternion) bool {
+	return q.sameComponents(b) || q.sameComponents(b.Negate())
+}
@@ -0,0 +1,79 @@
+package ray
+
+import (
+	"math/rand"
+	"rtt/tuple"
+)
+
+// AreaLight is a rectangle of USteps by VSteps cells, shaded with a sample
+// from each. JitterBy, if set, places the samples within their cells in
+// place of the rng they are sampled with.
+type AreaLight struct {
+	Corner    tuple.Point
+	UVec      tuple.Vector
+	USteps    int
+	VVec      tuple.Vector
+	VSteps    int
+	Samples   int
+	Position  tuple.Point
+	Intensity tuple.Color
+	JitterBy  func() float64
+}
+
+func NewAreaLight(corner tuple.Point, fullUVec tuple.Vector, usteps int, fullVVec tuple.Vector, vsteps int, intensity tuple.Color) *AreaLight {
+	uvec := fullUVec.ScalarDiv(float64(usteps))
+	vvec := fullVVec.ScalarDiv(float64(vsteps))
+	position := corner.Add(fullUVec.ScalarDiv(2)).Add(fullVVec.ScalarDiv(2))
+
+	return &AreaLight{
+		Corner:    corner,
+		UVec:      uvec,
+		USteps:    usteps,
+		VVec:      vvec,
+		VSteps:    vsteps,
+		Samples:   usteps * vsteps,
+		Position:  position,
+		Intensity: intensity,
+	}
+}
+
+// Sequence returns the values in turn, over and over, to make the jitter of a
+// light predictable in tests. It is not safe for concurrent use.
+func Sequence(values ...float64) func() float64 {
+	index := 0
+	return func() float64 {
+		value := values[index]
+		index = (index + 1) % len(values)
+		return value
+	}
+}
+
+func (l *AreaLight) PointOnLight(u, v int, rng *rand.Rand) tuple.Point {
+	return l.Corner.
+		Add(l.UVec.ScalarMultiply(float64(u) + Jitter(l.JitterBy, rng))).
+		Add(l.VVec.ScalarMultiply(float64(v) + Jitter(l.JitterBy, rng)))
+}
+
+func (l *AreaLight) DirectionFrom(point tuple.Point) tuple.Vector {
+	return l.Position.Subtract(point).Normalize()
+}
+
+func (l *AreaLight) DistanceFrom(point tuple.Point) float64 {
+	return l.Position.Subtract(point).Magnitude()
+}
+
+func (l *AreaLight) IntensityAt(point tuple.Point) tuple.Color {
+	return l.Intensity
+}
+
+func (l *AreaLight) Sample(rng *rand.Rand) []Light {
+	samples := make([]Light, 0, l.Samples)
+
+	for v := 0; v < l.VSteps; v++ {
+		for u := 0; u < l.USteps; u++ {
+			samples = append(samples, NewPointLight(l.PointOnLight(u, v, rng), l.Intensity))
+		}
+	}
+
+	return samples
+}
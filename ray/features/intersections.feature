@@ -158,3 +158,90 @@ Then i = i4
 #   When i ← intersection_with_uv(3.5, s, 0.2, 0.4)
 #   Then i.u = 0.2
 #     And i.v = 0.4
+
+Scenario: Intersections are kept sorted by t
+  Given s ← sphere()
+    And i1 ← intersection(5, s)
+    And i2 ← intersection(-3, s)
+    And i3 ← intersection(2, s)
+  When xs ← intersections(i1, i2, i3)
+  Then xs.count = 3
+    And xs[0].t = -3
+    And xs[1].t = 2
+    And xs[2].t = 5
+
+Scenario: Merging the intersections of several objects
+  Given a ← sphere()
+    And b ← sphere()
+    And i1 ← intersection(1, a)
+    And i2 ← intersection(4, a)
+    And i3 ← intersection(2, b)
+    And i4 ← intersection(3, b)
+    And i5 ← intersection(-1, b)
+    And xs ← intersections(i1, i2)
+    And ys ← intersections(i3, i4)
+    And zs ← intersections(i5)
+  When all ← merge(xs, ys, zs)
+  Then all.count = 5
+    And all[0].t = -1
+    And all[1].t = 1
+    And all[1].object = a
+    And all[2].t = 2
+    And all[2].object = b
+    And all[3].t = 3
+    And all[4].t = 4
+
+Scenario: Merging a single list gives it back
+  Given s ← sphere()
+    And i1 ← intersection(1, s)
+    And xs ← intersections(i1)
+  When ys ← merge(xs)
+  Then ys.count = 1
+    And ys[0].t = 1
+
+Scenario: Merging keeps intersections found in both lists
+  Given s ← sphere()
+    And i1 ← intersection(1, s)
+    And xs ← intersections(i1)
+    And ys ← merge(xs)
+  When all ← merge(ys, xs)
+  Then all.count = 2
+
+Scenario: Keeping the intersections closer than a light
+  Given s ← sphere()
+    And i1 ← intersection(-1, s)
+    And i2 ← intersection(0.5, s)
+    And i3 ← intersection(2, s)
+    And i4 ← intersection(7, s)
+    And xs ← intersections(i4, i3, i2, i1)
+  When ys ← within(xs, 0, 5)
+  Then ys.count = 2
+    And ys[0].t = 0.5
+    And ys[1].t = 2
+
+Scenario: Nothing is within an empty range
+  Given s ← sphere()
+    And i1 ← intersection(1, s)
+    And xs ← intersections(i1)
+  When ys ← within(xs, 2, 0)
+  Then ys.count = 0
+
+Scenario: Filtering intersections by object
+  Given a ← sphere()
+    And b ← sphere()
+    And i1 ← intersection(1, a)
+    And i2 ← intersection(2, b)
+    And i3 ← intersection(3, a)
+    And xs ← intersections(i1, i2, i3)
+  When ys ← filter(xs, object = a)
+  Then ys.count = 2
+    And ys[0].t = 1
+    And ys[1].t = 3
+
+Scenario: The hit is an entry of the collection
+  Given s ← sphere()
+    And i1 ← intersection(3, s)
+    And i2 ← intersection(1, s)
+    And xs ← intersections(i1, i2)
+  When i ← hit(xs)
+  Then i = xs[0]
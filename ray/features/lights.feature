@@ -6,3 +6,182 @@ Scenario: A point light has a position and intensity
   When light ← point_light(position, intensity)
   Then light.position = position
     And light.intensity = intensity
+
+Scenario: Creating an area light
+  Given corner ← point(0, 0, 0)
+    And v1 ← vector(2, 0, 0)
+    And v2 ← vector(0, 0, 1)
+  When light ← area_light(corner, v1, 4, v2, 2, color(1, 1, 1))
+  Then light.corner = point(0, 0, 0)
+    And light.uvec = vector(0.5, 0, 0)
+    And light.usteps = 4
+    And light.vvec = vector(0, 0, 0.5)
+    And light.vsteps = 2
+    And light.samples = 8
+    And light.position = point(1, 0, 0.5)
+
+Scenario Outline: Finding a single point on a regular area light
+  Given corner ← point(0, 0, 0)
+    And v1 ← vector(2, 0, 0)
+    And v2 ← vector(0, 0, 1)
+    And light ← area_light(corner, v1, 4, v2, 2, color(1, 1, 1))
+    And light.jitter_by ← sequence(0.5)
+  When pt ← point_on_light(light, <u>, <v>)
+  Then pt = <result>
+
+  Examples:
+    | u | v | result               |
+    | 0 | 0 | point(0.25, 0, 0.25) |
+    | 1 | 0 | point(0.75, 0, 0.25) |
+    | 0 | 1 | point(0.25, 0, 0.75) |
+    | 2 | 0 | point(1.25, 0, 0.25) |
+    | 3 | 1 | point(1.75, 0, 0.75) |
+
+Scenario Outline: Finding a single point on a jittered area light
+  Given corner ← point(0, 0, 0)
+    And v1 ← vector(2, 0, 0)
+    And v2 ← vector(0, 0, 1)
+    And light ← area_light(corner, v1, 4, v2, 2, color(1, 1, 1))
+    And light.jitter_by ← sequence(0.3, 0.7)
+  When pt ← point_on_light(light, <u>, <v>)
+  Then pt = <result>
+
+  Examples:
+    | u | v | result               |
+    | 0 | 0 | point(0.15, 0, 0.35) |
+    | 1 | 0 | point(0.65, 0, 0.35) |
+    | 0 | 1 | point(0.15, 0, 0.85) |
+    | 2 | 0 | point(1.15, 0, 0.35) |
+    | 3 | 1 | point(1.65, 0, 0.85) |
+
+Scenario Outline: lighting() samples the area light
+  Given corner ← point(-0.5, -0.5, -5)
+    And v1 ← vector(1, 0, 0)
+    And v2 ← vector(0, 1, 0)
+    And light ← area_light(corner, v1, 2, v2, 2, color(1, 1, 1))
+    And light.jitter_by ← sequence(0.5)
+    And m ← material()
+    And m.ambient ← 0.1
+    And m.diffuse ← 0.9
+    And m.specular ← 0
+    And eye ← point(0, 0, -5)
+    And pt ← <point>
+    And eyev ← normalize(eye - pt)
+    And normalv ← vector(pt.x, pt.y, pt.z)
+  When result ← lighting(m, light, pt, eyev, normalv, 1.0)
+  Then result = <result>
+
+  Examples:
+    | point                      | result                        |
+    | point(0, 0, -1)            | color(0.9965, 0.9965, 0.9965) |
+    | point(0, 0.7071, -0.7071)  | color(0.62318, 0.62318, 0.62318) |
+
+Scenario: A point light shines from its position
+  Given light ← point_light(point(0, 0, 10), color(1, 1, 1))
+    And pt ← point(0, 0, 2)
+  When lightv ← direction_to_light(light, pt)
+    And distance ← distance_to_light(light, pt)
+    And intensity ← intensity_at(light, pt)
+  Then lightv = vector(0, 0, 1)
+    And distance = 8
+    And intensity = color(1, 1, 1)
+
+Scenario: A directional light has a direction but no position
+  Given light ← directional_light(vector(0, -2, 0), color(1, 0.9, 0.8))
+    And pt ← point(3, 4, 5)
+  When lightv ← direction_to_light(light, pt)
+    And distance ← distance_to_light(light, pt)
+    And intensity ← intensity_at(light, pt)
+  Then lightv = vector(0, 1, 0)
+    And distance is infinite
+    And intensity = color(1, 0.9, 0.8)
+
+Scenario: A spot light shines from its position
+  Given light ← spot_light(point(0, 10, 0), vector(0, -1, 0), color(1, 1, 1), π/4, π/8)
+    And pt ← point(0, 4, 0)
+  When lightv ← direction_to_light(light, pt)
+    And distance ← distance_to_light(light, pt)
+  Then lightv = vector(0, 1, 0)
+    And distance = 6
+
+Scenario Outline: A spot light fades out towards the edge of its cone
+  Given light ← spot_light(point(0, 10, 0), vector(0, -1, 0), color(1, 1, 1), π/4, π/8)
+    And pt ← <point>
+  When intensity ← intensity_at(light, pt)
+  Then intensity = <result>
+
+  Examples:
+    | point                    | result               |
+    | point(0, 0, 0)           | color(1, 1, 1)       |
+    | point(1, 0, 0)           | color(1, 1, 1)       |
+    | point(0, 0, 6.681786379) | color(0.5, 0.5, 0.5) |
+    | point(20, 0, 0)          | color(0, 0, 0)       |
+    | point(0, 20, 0)          | color(0, 0, 0)       |
+
+Scenario: Making a light from a shape
+  Given s ← sphere()
+  When light ← shape_light(s, 4, 2, color(1, 1, 1))
+  Then light.usteps = 4
+    And light.vsteps = 2
+    And light.samples = 8
+
+Scenario: A shape light shines from the centre of its shape
+  Given s ← sphere()
+    And light ← shape_light(s, 4, 2, color(1, 1, 1))
+    And m ← translation(0, 0, 10)
+    And set_transform(s, m)
+    And pt ← point(0, 0, 2)
+  When lightv ← direction_to_light(light, pt)
+    And distance ← distance_to_light(light, pt)
+  Then lightv = vector(0, 0, 1)
+    And distance = 8
+
+Scenario Outline: Sampling points on the surface of a sphere
+  Given s ← sphere()
+  When sample ← sample_surface(s, <u>, <v>)
+  Then sample.point = <point>
+    And sample.normal = <normal>
+    And sample.area = 12.56637
+
+  Examples:
+    | u    | v    | point             | normal             |
+    | 0    | 0.5  | point(1, 0, 0)    | vector(1, 0, 0)    |
+    | 0.25 | 0.5  | point(0, 1, 0)    | vector(0, 1, 0)    |
+    | 0.5  | 0.5  | point(-1, 0, 0)   | vector(-1, 0, 0)   |
+    | 0    | 0    | point(0, 0, 1)    | vector(0, 0, 1)    |
+    | 0    | 1    | point(0, 0, -1)   | vector(0, 0, -1)   |
+
+Scenario Outline: Points on a stretched sphere stand for more or less of its surface
+  Given s ← sphere()
+    And m ← scaling(1, 2, 1)
+    And set_transform(s, m)
+  When sample ← sample_surface(s, <u>, <v>)
+  Then sample.point = <point>
+    And sample.normal = <normal>
+    And sample.area = <area>
+
+  Examples:
+    | u    | v   | point          | normal          | area     |
+    | 0    | 0.5 | point(1, 0, 0) | vector(1, 0, 0) | 25.13274 |
+    | 0.25 | 0.5 | point(0, 2, 0) | vector(0, 1, 0) | 12.56637 |
+
+Scenario Outline: The samples of a shape light cover its surface
+  Given s ← sphere()
+    And m ← scaling(<x>, <y>, <z>)
+    And set_transform(s, m)
+    And light ← shape_light(s, 32, 32, color(1, 1, 1))
+    And light.jitter_by ← sequence(0.5)
+  Then the samples of light cover an area of <area> within 0.01
+
+  Examples:
+    | x | y | z | area     |
+    | 1 | 1 | 1 | 12.56637 |
+    | 2 | 2 | 2 | 50.26548 |
+    | 1 | 2 | 1 | 21.47842 |
+
+Scenario: A shape light shines like a sphere of the same radiance
+  Given s ← sphere()
+    And light ← shape_light(s, 64, 64, color(1, 1, 1))
+    And light.jitter_by ← sequence(0.5)
+  Then the samples of light shine on point(0, 0, -3) facing vector(0, 0, 1) with a mean intensity of 0.11111 within 0.001
+    And the samples of light shine on point(0, 0, -2) facing vector(0, 0, 1) with a mean intensity of 0.25 within 0.001
@@ -11,58 +11,62 @@ Scenario: The default material
     And m.diffuse = 0.9
     And m.specular = 0.9
     And m.shininess = 200.0
+    And m.casts_shadow = true
+    And m.model = phong
+    And m.metallic = 0
+    And m.roughness = 0.5
 
-# Scenario: Reflectivity for the default material
-#   Given m ← material()
-#   Then m.reflective = 0.0
+Scenario: Reflectivity for the default material
+  Given m ← material()
+  Then m.reflective = 0.0
 
 # Scenario: Transparency and Refractive Index for the default material
 #   Given m ← material()
 #   Then m.transparency = 0.0
 #     And m.refractive_index = 1.0
 
-# Scenario: Lighting with the eye between the light and the surface
-#   Given eyev ← vector(0, 0, -1)
-#     And normalv ← vector(0, 0, -1)
-#     And light ← point_light(point(0, 0, -10), color(1, 1, 1))
-#   When result ← lighting(m, light, position, eyev, normalv)
-#   Then result = color(1.9, 1.9, 1.9)
+Scenario: Lighting with the eye between the light and the surface
+  Given eyev ← vector(0, 0, -1)
+    And normalv ← vector(0, 0, -1)
+    And light ← point_light(point(0, 0, -10), color(1, 1, 1))
+  When result ← lighting(m, light, position, eyev, normalv)
+  Then result = color(1.9, 1.9, 1.9)
 
-# Scenario: Lighting with the eye between light and surface, eye offset 45°
-#   Given eyev ← vector(0, √2/2, -√2/2)
-#     And normalv ← vector(0, 0, -1)
-#     And light ← point_light(point(0, 0, -10), color(1, 1, 1))
-#   When result ← lighting(m, light, position, eyev, normalv)
-#   Then result = color(1.0, 1.0, 1.0)
+Scenario: Lighting with the eye between light and surface, eye offset 45°
+  Given eyev ← vector(0, √2/2, -√2/2)
+    And normalv ← vector(0, 0, -1)
+    And light ← point_light(point(0, 0, -10), color(1, 1, 1))
+  When result ← lighting(m, light, position, eyev, normalv)
+  Then result = color(1.0, 1.0, 1.0)
 
-# Scenario: Lighting with eye opposite surface, light offset 45°
-#   Given eyev ← vector(0, 0, -1)
-#     And normalv ← vector(0, 0, -1)
-#     And light ← point_light(point(0, 10, -10), color(1, 1, 1))
-#   When result ← lighting(m, light, position, eyev, normalv)
-#   Then result = color(0.7364, 0.7364, 0.7364)
+Scenario: Lighting with eye opposite surface, light offset 45°
+  Given eyev ← vector(0, 0, -1)
+    And normalv ← vector(0, 0, -1)
+    And light ← point_light(point(0, 10, -10), color(1, 1, 1))
+  When result ← lighting(m, light, position, eyev, normalv)
+  Then result = color(0.7364, 0.7364, 0.7364)
 
-# Scenario: Lighting with eye in the path of the reflection vector
-#   Given eyev ← vector(0, -√2/2, -√2/2)
-#     And normalv ← vector(0, 0, -1)
-#     And light ← point_light(point(0, 10, -10), color(1, 1, 1))
-#   When result ← lighting(m, light, position, eyev, normalv)
-#   Then result = color(1.6364, 1.6364, 1.6364)
+Scenario: Lighting with eye in the path of the reflection vector
+  Given eyev ← vector(0, -√2/2, -√2/2)
+    And normalv ← vector(0, 0, -1)
+    And light ← point_light(point(0, 10, -10), color(1, 1, 1))
+  When result ← lighting(m, light, position, eyev, normalv)
+  Then result = color(1.6364, 1.6364, 1.6364)
 
-# Scenario: Lighting with the light behind the surface
-#   Given eyev ← vector(0, 0, -1)
-#     And normalv ← vector(0, 0, -1)
-#     And light ← point_light(point(0, 0, 10), color(1, 1, 1))
-#   When result ← lighting(m, light, position, eyev, normalv)
-#   Then result = color(0.1, 0.1, 0.1)
+Scenario: Lighting with the light behind the surface
+  Given eyev ← vector(0, 0, -1)
+    And normalv ← vector(0, 0, -1)
+    And light ← point_light(point(0, 0, 10), color(1, 1, 1))
+  When result ← lighting(m, light, position, eyev, normalv)
+  Then result = color(0.1, 0.1, 0.1)
 
-# Scenario: Lighting with the surface in shadow
-#   Given eyev ← vector(0, 0, -1)
-#     And normalv ← vector(0, 0, -1)
-#     And light ← point_light(point(0, 0, -10), color(1, 1, 1))
-#     And in_shadow ← true
-#   When result ← lighting(m, light, position, eyev, normalv, in_shadow)
-#   Then result = color(0.1, 0.1, 0.1)
+Scenario: Lighting with the surface in shadow
+  Given eyev ← vector(0, 0, -1)
+    And normalv ← vector(0, 0, -1)
+    And light ← point_light(point(0, 0, -10), color(1, 1, 1))
+    And in_shadow ← true
+  When result ← lighting(m, light, position, eyev, normalv, in_shadow)
+  Then result = color(0.1, 0.1, 0.1)
 
 # Scenario: Lighting with a pattern applied
 #   Given m.pattern ← stripe_pattern(color(1, 1, 1), color(0, 0, 0))
@@ -76,3 +80,127 @@ Scenario: The default material
 #     And c2 ← lighting(m, light, point(1.1, 0, 0), eyev, normalv, false)
 #   Then c1 = color(1, 1, 1)
 #     And c2 = color(0, 0, 0)
+
+Scenario Outline: lighting() uses light intensity to attenuate color
+  Given m.ambient ← 0.1
+    And m.diffuse ← 0.9
+    And m.specular ← 0
+    And pt ← point(0, 0, -1)
+    And eyev ← vector(0, 0, -1)
+    And normalv ← vector(0, 0, -1)
+    And light ← point_light(point(0, 0, -10), color(1, 1, 1))
+  When result ← lighting(m, light, pt, eyev, normalv, <intensity>)
+  Then result = <result>
+
+  Examples:
+    | intensity | result                  |
+    | 1.0       | color(1, 1, 1)          |
+    | 0.5       | color(0.55, 0.55, 0.55) |
+    | 0.0       | color(0.1, 0.1, 0.1)    |
+
+Scenario: Lighting with a directional light behind the eye
+  Given eyev ← vector(0, 0, -1)
+    And normalv ← vector(0, 0, -1)
+    And light ← directional_light(vector(0, 0, 1), color(1, 1, 1))
+  When result ← lighting(m, light, position, eyev, normalv)
+  Then result = color(1.9, 1.9, 1.9)
+
+Scenario: Lighting with the surface inside a spot light's cone
+  Given eyev ← vector(0, 0, -1)
+    And normalv ← vector(0, 0, -1)
+    And light ← spot_light(point(0, 0, -10), vector(0, 0, 1), color(1, 1, 1), π/4, π/8)
+  When result ← lighting(m, light, position, eyev, normalv)
+  Then result = color(1.9, 1.9, 1.9)
+
+Scenario: Lighting with the surface outside a spot light's cone
+  Given eyev ← vector(0, 0, -1)
+    And normalv ← vector(0, 0, -1)
+    And light ← spot_light(point(0, 0, -10), vector(0, 1, 0), color(1, 1, 1), π/4, π/8)
+  When result ← lighting(m, light, position, eyev, normalv)
+  Then result = color(0, 0, 0)
+
+Scenario: Parsing an unknown material model
+  Then parsing the material model "lambert" fails with "unknown material model \"lambert\", expected phong or metallic-roughness"
+
+Scenario: Converting a Phong material to metallic-roughness
+  Given m.color ← color(1, 0.5, 0)
+    And m.shininess ← 10
+  When pbr ← metallic_roughness(m)
+  Then pbr.model = metallic-roughness
+    And pbr.color = color(0.9, 0.45, 0)
+    And pbr.metallic = 0
+    And pbr.roughness = 0.46714
+    And pbr.ambient = 0.1
+    And m.model = phong
+
+Scenario: Shinier Phong materials convert to smoother ones
+  Given m.shininess ← 200
+  When pbr ← metallic_roughness(m)
+  Then pbr.roughness = 0.22347
+
+Scenario: Lighting a rough dielectric head on
+  Given m.model ← metallic-roughness
+    And eyev ← vector(0, 0, -1)
+    And normalv ← vector(0, 0, -1)
+    And light ← point_light(point(0, 0, -10), color(1, 1, 1))
+  When result ← lighting(m, light, position, eyev, normalv)
+  Then result = color(1.22, 1.22, 1.22)
+
+Scenario: Metals tint their highlights and have no diffuse light
+  Given m.model ← metallic-roughness
+    And m.color ← color(1, 0.5, 0)
+    And m.metallic ← 1
+    And m.ambient ← 0
+    And eyev ← vector(0, 0, -1)
+    And normalv ← vector(0, 0, -1)
+    And light ← point_light(point(0, 0, -10), color(1, 1, 1))
+  When result ← lighting(m, light, position, eyev, normalv)
+  Then result = color(4, 2, 0)
+
+Scenario: Away from its highlight a smooth surface shows only diffuse light
+  Given m.model ← metallic-roughness
+    And m.roughness ← 0.2
+    And m.ambient ← 0
+    And eyev ← vector(0, √2/2, -√2/2)
+    And normalv ← vector(0, 0, -1)
+    And light ← point_light(point(0, 0, -10), color(1, 1, 1))
+  When result ← lighting(m, light, position, eyev, normalv)
+  Then result = color(0.95897, 0.95897, 0.95897)
+
+Scenario: A metallic-roughness surface lit from behind gets only ambient light
+  Given m.model ← metallic-roughness
+    And eyev ← vector(0, 0, -1)
+    And normalv ← vector(0, 0, -1)
+    And light ← point_light(point(0, 0, 10), color(1, 1, 1))
+  When result ← lighting(m, light, position, eyev, normalv)
+  Then result = color(0.1, 0.1, 0.1)
+
+Scenario Outline: Metallic-roughness materials conserve energy
+  Given m.model ← metallic-roughness
+    And m.metallic ← <metallic>
+    And m.roughness ← <roughness>
+    And eyev ← <eyev>
+    And normalv ← vector(0, 0, -1)
+  Then the reflectance of m along eyev around normalv is at most 1
+    And 40000 bounces sampled from m along eyev around normalv average to its reflectance
+
+  Examples:
+    | metallic | roughness | eyev                     |
+    | 0        | 1         | vector(0, 0, -1)         |
+    | 0        | 0.5       | vector(0, 0, -1)         |
+    | 0        | 0.3       | vector(0, √2/2, -√2/2)   |
+    | 1        | 1         | vector(0, 0, -1)         |
+    | 1        | 0.5       | vector(0, 0.8, -0.6)     |
+    | 1        | 0.3       | vector(0, 0, -1)         |
+    | 0.5      | 0.6       | vector(0, 0.96, -0.28)   |
+
+Scenario: Phong materials bounce like Lambertian surfaces
+  Given eyev ← vector(0, 0.6, -0.8)
+    And normalv ← vector(0, 0, -1)
+  Then the reflectance of m along eyev around normalv is at most 1
+    And 1000 bounces sampled from m along eyev around normalv average to its reflectance
+
+Scenario: Cosine weighted samples favour the normal
+  Then 10000 cosine samples around vector(0, 1, 0) lie in its hemisphere with a mean cosine of 0.66667
+    And 10000 cosine samples around vector(1, 0, 0) lie in its hemisphere with a mean cosine of 0.66667
+    And 10000 cosine samples around vector(0, 0, -1) lie in its hemisphere with a mean cosine of 0.66667
@@ -0,0 +1,93 @@
+Feature: Patterns
+
+Scenario Outline: Stripes alternate along the x axis
+  Given p ← stripes(color(1, 1, 1), color(0, 0, 0))
+  Then pattern_at(p, point(<x>, <y>, <z>)) = color(<c>, <c>, <c>)
+
+  Examples:
+    | x    | y | z | c |
+    | 0    | 0 | 0 | 1 |
+    | 0.9  | 0 | 0 | 1 |
+    | 1    | 0 | 0 | 0 |
+    | -0.1 | 0 | 0 | 0 |
+    | -1.1 | 0 | 0 | 1 |
+    | 0    | 2 | 3 | 1 |
+
+Scenario Outline: Marble without turbulence is bands across the x axis
+  Given m ← marble(color(1, 1, 1), color(0, 0, 0))
+    And m.turbulence ← 0
+  Then pattern_at(m, point(<x>, <y>, <z>)) = color(<c>, <c>, <c>)
+
+  Examples:
+    | x    | y | z  | c   |
+    | -0.5 | 0 | 0  | 1   |
+    | 0    | 0 | 0  | 0.5 |
+    | 0.5  | 0 | 0  | 0   |
+    | 0.5  | 3 | -2 | 0   |
+    | 1.5  | 0 | 0  | 1   |
+
+Scenario: The scale of marble sets how close its bands are
+  Given m ← marble(color(1, 1, 1), color(0, 0, 0))
+    And m.turbulence ← 0
+    And m.scale ← 2
+  Then pattern_at(m, point(0.25, 0, 0)) = color(0, 0, 0)
+
+Scenario: Turbulence twists the veins of marble
+  Given m ← marble(color(1, 1, 1), color(0, 0, 0))
+  Then pattern_at(m, point(0.3, 0.2, 0.1)) = color(0.49181, 0.49181, 0.49181)
+
+Scenario Outline: Wood without turbulence is rings around the y axis
+  Given w ← wood(color(1, 1, 1), color(0, 0, 0))
+    And w.turbulence ← 0
+  Then pattern_at(w, point(<x>, <y>, <z>)) = color(<c>, <c>, <c>)
+
+  Examples:
+    | x     | y | z   | c   |
+    | 0     | 0 | 0   | 1   |
+    | 0     | 5 | 0   | 1   |
+    | 0.125 | 0 | 0   | 0.5 |
+    | 0     | 2 | 0.2 | 0.2 |
+    | 0.3   | 0 | 0.4 | 1   |
+
+Scenario: Turbulence makes the rings of wood wobble
+  Given w ← wood(color(1, 1, 1), color(0, 0, 0))
+  Then pattern_at(w, point(0.3, 0.2, 0.1)) = color(0.70035, 0.70035, 0.70035)
+
+Scenario: A turbulence pattern is A where the noise is still
+  Given t ← turbulence(color(1, 1, 1), color(0, 0, 0))
+  Then pattern_at(t, point(1, 2, 3)) = color(1, 1, 1)
+    And pattern_at(t, point(0.3, 0.2, 0.1)) = color(0.65261, 0.65261, 0.65261)
+
+Scenario: Perturbing a pattern by nothing leaves it as it was
+  Given m ← marble(color(1, 1, 1), color(0, 0, 0))
+    And m.turbulence ← 0
+    And p ← perturb(m, 0)
+  Then pattern_at(p, point(0.3, 0.2, 0.1)) = pattern_at(m, point(0.3, 0.2, 0.1))
+
+Scenario: Perturbing a pattern moves the points it is given
+  Given m ← marble(color(1, 1, 1), color(0, 0, 0))
+    And m.turbulence ← 0
+    And p ← perturb(m, 0.5)
+  Then pattern_at(p, point(0.3, 0.2, 0.1)) = color(0.03676, 0.03676, 0.03676)
+    And pattern_at(p, point(0.3, 0.2, 0.1)) ≠ pattern_at(m, point(0.3, 0.2, 0.1))
+
+Scenario: A pattern colours a shape in the space of the shape
+  Given s ← sphere()
+    And m ← marble(color(1, 1, 1), color(0, 0, 0))
+    And m.turbulence ← 0
+    And t ← scaling(2, 2, 2)
+    And set_transform(s, t)
+    And s.material.pattern ← m
+  When n ← material_at(s, point(1, 0, 0))
+  Then n.color = color(0, 0, 0)
+
+Scenario: Converting a material with a pattern to metallic-roughness keeps its look
+  Given m ← material()
+    And p ← marble(color(1, 1, 1), color(0, 0, 0))
+    And p.turbulence ← 0
+    And m.pattern ← p
+    And m.diffuse ← 0.5
+  When n ← metallic_roughness(m)
+    And q ← n.pattern
+  Then pattern_at(q, point(-0.5, 0, 0)) = color(0.5, 0.5, 0.5)
+    And pattern_at(q, point(0.5, 0, 0)) = color(0, 0, 0)
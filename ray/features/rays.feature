@@ -33,3 +33,14 @@ Scenario: Scaling a ray
     And direction ← vector(0, 3, 0)
   Then r2.origin = origin
     And r2.direction = direction
+
+Scenario: A ray is cast at time 0 by default
+  Given r ← ray(point(1, 2, 3), vector(0, 1, 0))
+  Then r.time = 0
+
+Scenario: Transforming a ray keeps its time
+  Given r ← ray(point(1, 2, 3), vector(0, 1, 0))
+    And r.time ← 0.25
+    And m ← translation(3, 4, 5)
+  When r2 ← transform(r, m)
+  Then r2.time = 0.25
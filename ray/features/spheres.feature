@@ -46,6 +46,11 @@ Scenario: Intersect sets the object on the intersection
     And xs[0].object = s
     And xs[1].object = s
 
+Scenario: Spheres created concurrently are told apart
+  Given r ← ray(point(0, 0, -5), vector(0, 0, 1))
+  When spheres ← 50 spheres created concurrently
+  Then each of spheres is the object of its intersections with r
+
 Scenario: A sphere's default transformation
   Given s ← sphere()
   Then s.transform = id
@@ -132,3 +137,110 @@ Scenario: Computing the normal on a transformed sphere
 #   Then s.transform = identity_matrix
 #     And s.material.transparency = 1.0
 #     And s.material.refractive_index = 1.5
+
+Scenario Outline: Checking for any hit closer than a distance
+  Given r ← ray(point(0, 0, <z>), vector(0, 0, 1))
+    And s ← sphere()
+  Then any_hit(s, r, <max>) is <result>
+
+  Examples:
+    | z  | max | result |
+    | -5 | 10  | true   |
+    | -5 | 4   | false  |
+    | -5 | 4.5 | true   |
+    | 0  | 0.5 | false  |
+    | 0  | 2   | true   |
+    | 5  | 10  | false  |
+
+Scenario: A ray that misses has no hit
+  Given r ← ray(point(0, 2, -5), vector(0, 0, 1))
+    And s ← sphere()
+  Then any_hit(s, r, 100) is false
+
+Scenario: A moving sphere starts at its first transform
+  Given s ← sphere()
+    And a ← translation(0, 0, 0)
+    And b ← translation(2, 0, 0)
+  When set_motion(s, a, b)
+  Then s.transform = a
+    And transform_at(s, 0) = a
+    And transform_at(s, 1) = b
+
+Scenario: A moving sphere is halfway along its motion at time 0.5
+  Given s ← sphere()
+    And a ← scaling(1, 1, 1)
+    And b ← scaling(3, 3, 3)
+    And m ← scaling(2, 2, 2)
+  When set_motion(s, a, b)
+  Then transform_at(s, 0.5) = m
+
+Scenario Outline: Intersecting a moving sphere at different times
+  Given r ← ray(point(2, 0, -5), vector(0, 0, 1))
+    And r.time ← <time>
+    And s ← sphere()
+    And a ← translation(0, 0, 0)
+    And b ← translation(2, 0, 0)
+  When set_motion(s, a, b)
+    And xs ← intersect(s, r)
+  Then xs.count = <count>
+
+  Examples:
+    | time | count |
+    | 0    | 0     |
+    | 0.5  | 2     |
+    | 1    | 2     |
+
+Scenario: Intersecting a moving sphere where it ends
+  Given r ← ray(point(2, 0, -5), vector(0, 0, 1))
+    And r.time ← 1
+    And s ← sphere()
+    And a ← translation(0, 0, 0)
+    And b ← translation(2, 0, 0)
+  When set_motion(s, a, b)
+    And xs ← intersect(s, r)
+  Then xs[0].t = 4
+    And xs[1].t = 6
+
+Scenario: Intersecting a sphere that turns and stretches as it moves
+  Given r ← ray(point(0.5, 1, -5), vector(0, 0, 1))
+    And r.time ← 0.5
+    And s ← sphere()
+    And a ← scaling(1, 1, 1)
+    And c ← rotation_z(π/2)
+    And d ← scaling(3, 1, 1)
+    And b ← c * d
+  When set_motion(s, a, b)
+    And xs ← intersect(s, r)
+  Then xs.count = 2
+    And xs[0].t = 4.22945
+    And xs[1].t = 5.77055
+
+Scenario: The normal on a moving sphere follows it
+  Given s ← sphere()
+    And a ← translation(0, 0, 0)
+    And b ← translation(2, 0, 0)
+  When set_motion(s, a, b)
+    And n ← normal_at(s, point(1, 1, 0), 0.5)
+  Then n = vector(0, 1, 0)
+
+Scenario: A sphere set moving to where it already is stays there
+  Given s ← sphere()
+    And a ← translation(2, 0, 0)
+  When set_motion(s, a, a)
+  Then s.transform = a
+    And transform_at(s, 0.5) = a
+
+Scenario: Setting a transform stops a sphere moving
+  Given s ← sphere()
+    And a ← translation(0, 0, 0)
+    And b ← translation(2, 0, 0)
+    And t ← translation(5, 0, 0)
+  When set_motion(s, a, b)
+    And set_transform(s, t)
+  Then transform_at(s, 1) = t
+
+Scenario: A sphere cannot turn inside out as it moves
+  Given s ← sphere()
+    And a ← scaling(-1, 1, 1)
+    And b ← scaling(1, 1, 1)
+  Then set_motion(s, a, b) fails with "a moving shape cannot be mirrored at one end of its motion and not the other"
@@ -0,0 +1,99 @@
+package ray
+
+import (
+	"sort"
+)
+
+// Intersections is always sorted by t, so the hit can be found by binary
+// search and the intersections of several objects combined by merging.
+// Build them with NewIntersections rather than as a literal, which nothing
+// sorts; the methods here all keep them sorted.
+type Intersections []Intersection
+
+// NewIntersections sorts xs by t, keeping the order of those with equal t.
+func NewIntersections(xs ...Intersection) Intersections {
+	sorted := append(Intersections{}, xs...)
+
+	// Shapes mostly find their intersections in order already, so the sort
+	// is left out when it has nothing to do.
+	for i := 1; i < len(sorted); i++ {
+		if sorted[i].T < sorted[i-1].T {
+			sort.SliceStable(sorted, func(i, j int) bool {
+				return sorted[i].T < sorted[j].T
+			})
+			break
+		}
+	}
+	return sorted
+}
+
+// Merge combines two sorted collections in a single pass.
+func (xs Intersections) Merge(other Intersections) Intersections {
+	result := make(Intersections, 0, len(xs)+len(other))
+
+	i, j := 0, 0
+	for i < len(xs) && j < len(other) {
+		if other[j].T < xs[i].T {
+			result = append(result, other[j])
+			j++
+		} else {
+			result = append(result, xs[i])
+			i++
+		}
+	}
+
+	result = append(result, xs[i:]...)
+	return append(result, other[j:]...)
+}
+
+// MergeAll merges the intersections of many objects, pairing them off so
+// that each intersection is copied once per halving rather than once per
+// object.
+func MergeAll(lists ...Intersections) Intersections {
+	switch len(lists) {
+	case 0:
+		return Intersections{}
+	case 1:
+		return lists[0]
+	}
+
+	half := len(lists) / 2
+	return MergeAll(lists[:half]...).Merge(MergeAll(lists[half:]...))
+}
+
+// Hit returns the intersection with the lowest non-negative t, or nil if
+// every intersection is behind the ray.
+func (xs Intersections) Hit() *Intersection {
+	i := xs.search(0)
+	if i == len(xs) {
+		return nil
+	}
+	return &xs[i]
+}
+
+// Within returns the intersections with min <= t < max, such as the ones
+// between a point and a light.
+func (xs Intersections) Within(min, max float64) Intersections {
+	lo, hi := xs.search(min), xs.search(max)
+	if hi < lo {
+		hi = lo
+	}
+	return xs[lo:hi]
+}
+
+func (xs Intersections) Filter(keep func(Intersection) bool) Intersections {
+	result := Intersections{}
+	for _, x := range xs {
+		if keep(x) {
+			result = append(result, x)
+		}
+	}
+	return result
+}
+
+// search returns the index of the first intersection with t >= min.
+func (xs Intersections) search(min float64) int {
+	return sort.Search(len(xs), func(i int) bool {
+		return xs[i].T >= min
+	})
+}
@@ -0,0 +1,136 @@
+package ray
+
+import (
+	"math"
+	"math/rand"
+	"rtt/tuple"
+)
+
+// Light is anything the shading code can illuminate a point with. Sample
+// breaks lights covering an area down into the lights to shade with,
+// jittering them with rng.
+type Light interface {
+	DirectionFrom(point tuple.Point) tuple.Vector
+	DistanceFrom(point tuple.Point) float64
+	IntensityAt(point tuple.Point) tuple.Color
+	Sample(rng *rand.Rand) []Light
+}
+
+// Jitter is how far into its cell a sample of a light falls. It comes from
+// jitterBy if that is set, and otherwise from rng, so that a render seeded
+// the same way samples its lights the same way. Without either it comes
+// from the shared source.
+func Jitter(jitterBy func() float64, rng *rand.Rand) float64 {
+	switch {
+	case jitterBy != nil:
+		return jitterBy()
+	case rng != nil:
+		return rng.Float64()
+	default:
+		return rand.Float64()
+	}
+}
+
+type PointLight struct {
+	Position  tuple.Point
+	Intensity tuple.Color
+}
+
+func NewPointLight(position tuple.Point, intensity tuple.Color) *PointLight {
+	return &PointLight{
+		Intensity: intensity,
+		Position:  position,
+	}
+}
+
+func (l *PointLight) DirectionFrom(point tuple.Point) tuple.Vector {
+	return l.Position.Subtract(point).Normalize()
+}
+
+func (l *PointLight) DistanceFrom(point tuple.Point) float64 {
+	return l.Position.Subtract(point).Magnitude()
+}
+
+func (l *PointLight) IntensityAt(point tuple.Point) tuple.Color {
+	return l.Intensity
+}
+
+func (l *PointLight) Sample(rng *rand.Rand) []Light {
+	return []Light{l}
+}
+
+type DirectionalLight struct {
+	Direction tuple.Vector
+	Intensity tuple.Color
+}
+
+func NewDirectionalLight(direction tuple.Vector, intensity tuple.Color) *DirectionalLight {
+	return &DirectionalLight{
+		Direction: direction.Normalize(),
+		Intensity: intensity,
+	}
+}
+
+func (l *DirectionalLight) DirectionFrom(point tuple.Point) tuple.Vector {
+	return l.Direction.Negate()
+}
+
+func (l *DirectionalLight) DistanceFrom(point tuple.Point) float64 {
+	return math.Inf(1)
+}
+
+func (l *DirectionalLight) IntensityAt(point tuple.Point) tuple.Color {
+	return l.Intensity
+}
+
+func (l *DirectionalLight) Sample(rng *rand.Rand) []Light {
+	return []Light{l}
+}
+
+// SpotLight shines a cone of light along Direction. Angle is the half angle
+// of the cone in radians, and the light fades out linearly over the last
+// Falloff radians towards its edge.
+type SpotLight struct {
+	Position  tuple.Point
+	Direction tuple.Vector
+	Intensity tuple.Color
+	Angle     float64
+	Falloff   float64
+}
+
+func NewSpotLight(position tuple.Point, direction tuple.Vector, intensity tuple.Color, angle, falloff float64) *SpotLight {
+	return &SpotLight{
+		Position:  position,
+		Direction: direction.Normalize(),
+		Intensity: intensity,
+		Angle:     angle,
+		Falloff:   falloff,
+	}
+}
+
+func (l *SpotLight) DirectionFrom(point tuple.Point) tuple.Vector {
+	return l.Position.Subtract(point).Normalize()
+}
+
+func (l *SpotLight) DistanceFrom(point tuple.Point) float64 {
+	return l.Position.Subtract(point).Magnitude()
+}
+
+func (l *SpotLight) IntensityAt(point tuple.Point) tuple.Color {
+	toPoint := point.Subtract(l.Position).Normalize()
+	angle := math.Acos(math.Max(-1, math.Min(1, toPoint.Dot(l.Direction))))
+
+	if angle > l.Angle {
+		return tuple.Black
+	}
+
+	if l.Falloff <= 0 || angle <= l.Angle-l.Falloff {
+		return l.Intensity
+	}
+
+	return l.Intensity.ScalarMultiply((l.Angle - angle) / l.Falloff)
+}
+
+func (l *SpotLight) Sample(rng *rand.Rand) []Light {
+	return []Light{l}
+}
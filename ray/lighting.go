@@ -1,31 +1,135 @@
 package ray
 
-import "rtt/tuple"
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"rtt/tuple"
+)
 
-type PointLight struct {
-	Position  tuple.Tuple
-	Intensity tuple.Tuple
+// Model is the way a material reflects the light that reaches it.
+type Model int
+
+const (
+	// Phong shades with Diffuse, Specular and Shininess.
+	Phong Model = iota
+	// MetallicRoughness shades with a Lambertian diffuse and a GGX
+	// microfacet specular lobe, set by Metallic and Roughness. It never
+	// reflects more light than it receives.
+	MetallicRoughness
+)
+
+var modelNames = []string{"phong", "metallic-roughness"}
+
+func (m Model) String() string {
+	if m < 0 || int(m) >= len(modelNames) {
+		return fmt.Sprintf("Model(%d)", int(m))
+	}
+	return modelNames[m]
+}
+
+func ParseModel(name string) (Model, error) {
+	for i, n := range modelNames {
+		if n == name {
+			return Model(i), nil
+		}
+	}
+	return Phong, fmt.Errorf("unknown material model %q, expected phong or metallic-roughness", name)
 }
 
 type Material struct {
-	Color     tuple.Tuple
-	Diffuse   float64
-	Specular  float64
-	Shininess float64
+	Model       Model
+	Color       tuple.Color
+	Pattern     Pattern // if set, colours the material in place of Color
+	Ambient     float64
+	Diffuse     float64
+	Specular    float64
+	Shininess   float64
+	Metallic    float64
+	Roughness   float64
+	Reflective  float64
+	Emission    tuple.Color
+	CastsShadow bool
 }
 
 func NewMaterial() *Material {
 	return &Material{
-		Color:     *tuple.White,
-		Diffuse:   0.9,
-		Specular:  0.9,
-		Shininess: 200,
+		Color:       tuple.White,
+		Ambient:     0.1,
+		Diffuse:     0.9,
+		Specular:    0.9,
+		Shininess:   200,
+		Metallic:    0,
+		Roughness:   0.5,
+		Reflective:  0,
+		Emission:    tuple.Black,
+		CastsShadow: true,
+	}
+}
+
+// MetallicRoughness converts a Phong material to the metallic-roughness
+// model. The diffuse colour, or pattern, becomes the base colour, and the
+// shininess the roughness whose highlight has about the same width: a Phong
+// lobe with exponent n is close to a Blinn-Phong lobe with 4n, which matches
+// a GGX lobe with alpha² = 2 / (4n + 2), and roughness is the square root of
+// alpha. Phong materials have no notion of metal, so the result is a
+// dielectric, and Specular is dropped since the strength of a dielectric's
+// highlight follows from its Fresnel reflectance.
+func (m Material) MetallicRoughness() Material {
+	if m.Model == MetallicRoughness {
+		return m
+	}
+
+	m.Model = MetallicRoughness
+	m.Color = m.Color.ScalarMultiply(m.Diffuse)
+	if m.Pattern != nil {
+		m.Pattern = &scaled{pattern: m.Pattern, factor: m.Diffuse}
+	}
+	m.Metallic = 0
+	m.Roughness = math.Pow(2/(4*m.Shininess+2), 0.25)
+	return m
+}
+
+func contribution(material *Material, light Light, point tuple.Point, eyev, normalv tuple.Vector) tuple.Color {
+	lightIntensity := light.IntensityAt(point)
+
+	if material.Model == MetallicRoughness {
+		return microfacetContribution(material, lightIntensity, light.DirectionFrom(point), eyev, normalv)
+	}
+	effectiveColor := material.Color.Hadamard(lightIntensity)
+
+	lightv := light.DirectionFrom(point)
+	lightDotNormal := lightv.Dot(normalv)
+
+	if lightDotNormal < 0 {
+		return tuple.Black
+	}
+
+	diffuse := effectiveColor.ScalarMultiply(material.Diffuse * lightDotNormal)
+
+	reflectv := lightv.Negate().Reflect(normalv)
+	reflectDotEye := reflectv.Dot(eyev)
+
+	if reflectDotEye <= 0 {
+		return diffuse
 	}
+
+	factor := math.Pow(reflectDotEye, material.Shininess)
+	specular := lightIntensity.ScalarMultiply(material.Specular * factor)
+
+	return diffuse.Add(specular)
 }
 
-func NewPointLight(position, intensity tuple.Tuple) *PointLight {
-	return &PointLight{
-		Intensity: intensity,
-		Position:  position,
+func Lighting(material *Material, light Light, point tuple.Point, eyev, normalv tuple.Vector, intensity float64, rng *rand.Rand) tuple.Color {
+	effectiveColor := material.Color.Hadamard(light.IntensityAt(point))
+	ambient := effectiveColor.ScalarMultiply(material.Ambient)
+
+	samples := light.Sample(rng)
+	sum := tuple.Black
+
+	for _, sample := range samples {
+		sum = sum.Add(contribution(material, sample, point, eyev, normalv))
 	}
+
+	return ambient.Add(sum.ScalarDiv(float64(len(samples))).ScalarMultiply(intensity))
 }
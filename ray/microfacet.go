@@ -0,0 +1,171 @@
+package ray
+
+import (
+	"math"
+	"math/rand"
+	"rtt/tuple"
+)
+
+// dielectricReflectance is the reflectance at normal incidence of the
+// non-metallic part of a metallic-roughness material, which is about 4% for
+// most dielectrics.
+const dielectricReflectance = 0.04
+
+// minAlpha keeps a roughness of 0 from turning the GGX lobe into a spike
+// that no sample or light would ever line up with.
+const minAlpha = 0.002
+
+// microfacetContribution is the light a metallic-roughness material sends
+// along eyev from a light of the given intensity in the direction lightv.
+// Light intensities are taken as the irradiance they give a surface facing
+// them over π, so a white Lambertian surface lit head on is as bright as the
+// light.
+func microfacetContribution(material *Material, intensity tuple.Color, lightv, eyev, normalv tuple.Vector) tuple.Color {
+	lightDotNormal := lightv.Dot(normalv)
+	if lightDotNormal <= 0 {
+		return tuple.Black
+	}
+
+	f := material.BRDF(lightv, eyev, normalv)
+	return f.Hadamard(intensity).ScalarMultiply(math.Pi * lightDotNormal)
+}
+
+// BRDF returns how much of the light arriving from lightv a material
+// reflects towards eyev. Phong materials are treated as Lambertian with an
+// albedo of Color times Diffuse, which is how the path tracer bounces off
+// them.
+//
+// A metallic-roughness material is a blend of a metal, which only has the
+// specular lobe, and a dielectric whose diffuse light is what the Fresnel
+// reflectance lets into the surface on the way in and out again. Both
+// reflect at most the light they receive, and so does the blend.
+func (m *Material) BRDF(lightv, eyev, normalv tuple.Vector) tuple.Color {
+	lightDotNormal := lightv.Dot(normalv)
+	eyeDotNormal := eyev.Dot(normalv)
+	if lightDotNormal <= 0 || eyeDotNormal <= 0 {
+		return tuple.Black
+	}
+
+	if m.Model != MetallicRoughness {
+		return m.Color.ScalarMultiply(m.Diffuse / math.Pi)
+	}
+
+	alpha := m.alpha()
+	halfway := lightv.Add(eyev).Normalize()
+
+	fresnel := m.fresnel(eyev.Dot(halfway))
+	d := ggxDistribution(halfway.Dot(normalv), alpha)
+	g := smithMasking(lightDotNormal, alpha) * smithMasking(eyeDotNormal, alpha)
+	specular := fresnel.ScalarMultiply(d * g / (4 * lightDotNormal * eyeDotNormal))
+
+	transmitted := (1 - schlick(dielectricReflectance, lightDotNormal)) * (1 - schlick(dielectricReflectance, eyeDotNormal)) / (1 - dielectricReflectance)
+	diffuse := m.Color.ScalarMultiply(transmitted * (1 - m.Metallic) / math.Pi)
+
+	return diffuse.Add(specular)
+}
+
+// SampleBounce picks a direction for a path to continue in after reaching
+// the material along eyev, and returns it with the weight to multiply the
+// path's throughput by: the BRDF times the cosine to the normal over the
+// probability of picking the direction. The weight is black when the
+// direction is below the surface and the path should end.
+//
+// Phong materials bounce in a cosine-weighted direction. Metallic-roughness
+// materials pick between that and a direction reflected about a half vector
+// drawn from the GGX distribution, favouring the second the more metallic
+// they are, and weigh either by the probability of both strategies.
+func (m *Material) SampleBounce(eyev, normalv tuple.Vector, rng *rand.Rand) (tuple.Vector, tuple.Color) {
+	if m.Model != MetallicRoughness {
+		return cosineHemisphere(normalv, rng.Float64(), rng.Float64()), m.Color.ScalarMultiply(m.Diffuse)
+	}
+
+	alpha := m.alpha()
+	specularChance := 0.25 + 0.75*m.Metallic
+
+	var lightv tuple.Vector
+	if rng.Float64() < specularChance {
+		halfway := ggxHalfway(normalv, alpha, rng.Float64(), rng.Float64())
+		lightv = eyev.Negate().Reflect(halfway)
+	} else {
+		lightv = cosineHemisphere(normalv, rng.Float64(), rng.Float64())
+	}
+
+	lightDotNormal := lightv.Dot(normalv)
+	if lightDotNormal <= 0 {
+		return lightv, tuple.Black
+	}
+
+	halfway := lightv.Add(eyev).Normalize()
+	halfwayDotNormal := halfway.Dot(normalv)
+	specularPdf := ggxDistribution(halfwayDotNormal, alpha) * halfwayDotNormal / (4 * eyev.Dot(halfway))
+	diffusePdf := lightDotNormal / math.Pi
+	pdf := specularChance*specularPdf + (1-specularChance)*diffusePdf
+
+	return lightv, m.BRDF(lightv, eyev, normalv).ScalarMultiply(lightDotNormal / pdf)
+}
+
+func (m *Material) alpha() float64 {
+	return math.Max(m.Roughness*m.Roughness, minAlpha)
+}
+
+// fresnel is Schlick's approximation of the reflectance at an angle with
+// the given cosine. Metals reflect their own colour, dielectrics a little
+// white light.
+func (m *Material) fresnel(cosine float64) tuple.Color {
+	f0 := m.Color.ScalarMultiply(m.Metallic).Add(tuple.White.ScalarMultiply(dielectricReflectance * (1 - m.Metallic)))
+	return tuple.NewColor(schlick(f0.R, cosine), schlick(f0.G, cosine), schlick(f0.B, cosine))
+}
+
+func schlick(f0, cosine float64) float64 {
+	return f0 + (1-f0)*math.Pow(1-cosine, 5)
+}
+
+// ggxDistribution is the density of microfacets facing the half vector.
+func ggxDistribution(halfwayDotNormal, alpha float64) float64 {
+	if halfwayDotNormal <= 0 {
+		return 0
+	}
+	a2 := alpha * alpha
+	d := halfwayDotNormal*halfwayDotNormal*(a2-1) + 1
+	return a2 / (math.Pi * d * d)
+}
+
+// smithMasking is the fraction of microfacets seen from a direction with
+// the given cosine to the normal that no other microfacet hides.
+func smithMasking(cosine, alpha float64) float64 {
+	a2 := alpha * alpha
+	return 2 * cosine / (cosine + math.Sqrt(a2+(1-a2)*cosine*cosine))
+}
+
+// ggxHalfway turns u and v in [0, 1) into a half vector around normal,
+// with density ggxDistribution times its cosine to the normal.
+func ggxHalfway(normal tuple.Vector, alpha, u, v float64) tuple.Vector {
+	phi := 2 * math.Pi * u
+	cosTheta := math.Sqrt((1 - v) / (1 + (alpha*alpha-1)*v))
+	sinTheta := math.Sqrt(1 - cosTheta*cosTheta)
+	return aroundNormal(normal, sinTheta*math.Cos(phi), sinTheta*math.Sin(phi), cosTheta)
+}
+
+// cosineHemisphere turns u and v in [0, 1) into a direction on the
+// hemisphere around normal, with directions close to the normal as much
+// more likely as the cosine of their angle to it. For a diffuse surface
+// this cancels the cosine in the rendering equation, so each bounce only
+// needs to be weighted by the surface's albedo.
+func cosineHemisphere(normal tuple.Vector, u, v float64) tuple.Vector {
+	phi := 2 * math.Pi * u
+	radius := math.Sqrt(v)
+	return aroundNormal(normal, radius*math.Cos(phi), radius*math.Sin(phi), math.Sqrt(1-v))
+}
+
+// aroundNormal turns coordinates in a frame whose z axis is normal into a
+// vector.
+func aroundNormal(normal tuple.Vector, x, y, z float64) tuple.Vector {
+	axis := tuple.NewVector(1, 0, 0)
+	if math.Abs(normal.X) > 0.9 {
+		axis = tuple.NewVector(0, 1, 0)
+	}
+	tangent := normal.Cross(axis).Normalize()
+	bitangent := normal.Cross(tangent)
+
+	return tangent.ScalarMultiply(x).Add(bitangent.ScalarMultiply(y)).Add(normal.ScalarMultiply(z))
+}
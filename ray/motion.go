@@ -0,0 +1,93 @@
+package ray
+
+import (
+	"errors"
+	"rtt/matrix"
+	"rtt/quaternion"
+	"rtt/transformations"
+	"rtt/tuple"
+)
+
+var ErrTurnsInsideOut = errors.New("a moving shape cannot be mirrored at one end of its motion and not the other")
+
+// motion is how a shape moves while the camera's shutter is open. Its
+// transform at time 0 blends into the one at time 1, moving and scaling in
+// straight lines and rotating along the shortest arc, like
+// transformations.Interpolate. The ends are decomposed and inverted once,
+// rather than for every ray, and rays cast at either end use them as they
+// are.
+type motion struct {
+	start, end                       matrix.Mat4
+	startInverse, endInverse         matrix.Mat4
+	startTranslation, endTranslation tuple.Vector
+	startRotation, endRotation       quaternion.Quaternion
+	startScale, endScale             tuple.Vector
+}
+
+func newMotion(start, end matrix.Mat4) (*motion, error) {
+	m := &motion{start: start, end: end}
+	var err error
+
+	if m.startInverse, err = start.Invert(); err != nil {
+		return nil, err
+	}
+	if m.endInverse, err = end.Invert(); err != nil {
+		return nil, err
+	}
+	if m.startTranslation, m.startRotation, m.startScale, err = transformations.Decompose(start); err != nil {
+		return nil, err
+	}
+	if m.endTranslation, m.endRotation, m.endScale, err = transformations.Decompose(end); err != nil {
+		return nil, err
+	}
+
+	// A scale that changes sign passes through 0 on the way, where the
+	// transform cannot be inverted.
+	if m.startScale.X*m.endScale.X < 0 {
+		return nil, ErrTurnsInsideOut
+	}
+
+	return m, nil
+}
+
+// parts blends the translation, rotation and scale of the ends at time.
+func (m *motion) parts(time float64) (tuple.Vector, quaternion.Quaternion, tuple.Vector) {
+	translation := m.startTranslation.Add(m.endTranslation.Subtract(m.startTranslation).ScalarMultiply(time))
+	rotation := quaternion.Slerp(m.startRotation, m.endRotation, time)
+	scale := m.startScale.Add(m.endScale.Subtract(m.startScale).ScalarMultiply(time))
+	return translation, rotation, scale
+}
+
+func (m *motion) transformAt(time float64) matrix.Mat4 {
+	switch time {
+	case 0:
+		return m.start
+	case 1:
+		return m.end
+	}
+	return transformations.Compose(m.parts(time))
+}
+
+// inverseAt is needed for every ray, so it is written out from the parts
+// rather than multiplied together: the rows of the inverse rotation divided
+// by the scale, and the translation undone through them.
+func (m *motion) inverseAt(time float64) matrix.Mat4 {
+	switch time {
+	case 0:
+		return m.startInverse
+	case 1:
+		return m.endInverse
+	}
+
+	translation, rotation, scale := m.parts(time)
+	r := rotation.Conjugate().Matrix()
+	scales := [3]float64{scale.X, scale.Y, scale.Z}
+
+	inverse := matrix.Identity4
+	for row, s := range scales {
+		x, y, z := r[row*4]/s, r[row*4+1]/s, r[row*4+2]/s
+		inverse[row*4], inverse[row*4+1], inverse[row*4+2] = x, y, z
+		inverse[row*4+3] = -(x*translation.X + y*translation.Y + z*translation.Z)
+	}
+	return inverse
+}
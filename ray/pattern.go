@@ -0,0 +1,138 @@
+package ray
+
+import (
+	"math"
+	"rtt/noise"
+	"rtt/tuple"
+)
+
+// Pattern colours a surface point by point. It is given points in the
+// space of the shape, so that it moves, turns and scales with the shape.
+type Pattern interface {
+	ColorAt(point tuple.Point) tuple.Color
+}
+
+// blend goes from a at t = 0 to b at t = 1.
+func blend(a, b tuple.Color, t float64) tuple.Color {
+	return a.Add(b.Subtract(a).ScalarMultiply(t))
+}
+
+func scale(point tuple.Point, s float64) tuple.Point {
+	return tuple.NewPoint(point.X*s, point.Y*s, point.Z*s)
+}
+
+// Stripes alternates between A and B every unit along the x axis, with A
+// from x = 0 to 1.
+type Stripes struct {
+	A tuple.Color
+	B tuple.Color
+}
+
+func NewStripes(a, b tuple.Color) *Stripes {
+	return &Stripes{A: a, B: b}
+}
+
+func (s *Stripes) ColorAt(point tuple.Point) tuple.Color {
+	if math.Mod(math.Floor(point.X), 2) == 0 {
+		return s.A
+	}
+	return s.B
+}
+
+// Perturb distorts another pattern by moving each point it is given by up
+// to Amount in a direction that follows Perlin noise, so that straight
+// edges wander. Scale is how many times the noise changes per unit.
+type Perturb struct {
+	Pattern Pattern
+	Amount  float64
+	Scale   float64
+}
+
+func NewPerturb(pattern Pattern, amount float64) *Perturb {
+	return &Perturb{Pattern: pattern, Amount: amount, Scale: 1}
+}
+
+func (p *Perturb) ColorAt(point tuple.Point) tuple.Color {
+	s := scale(point, p.Scale)
+
+	// The three components come from far apart parts of the noise, so that
+	// they do not move together.
+	offset := tuple.NewVector(
+		noise.Perlin(s),
+		noise.Perlin(s.Add(tuple.NewVector(31.4, 0, 0))),
+		noise.Perlin(s.Add(tuple.NewVector(0, 0, 27.1))),
+	)
+
+	return p.Pattern.ColorAt(point.Add(offset.ScalarMultiply(p.Amount)))
+}
+
+// Turbulence blends from A to B as the turbulence of the noise rises from 0
+// to 1, over Octaves octaves, like clouds or smoke.
+type Turbulence struct {
+	A       tuple.Color
+	B       tuple.Color
+	Scale   float64
+	Octaves int
+}
+
+func NewTurbulence(a, b tuple.Color) *Turbulence {
+	return &Turbulence{A: a, B: b, Scale: 1, Octaves: 4}
+}
+
+func (t *Turbulence) ColorAt(point tuple.Point) tuple.Color {
+	value := noise.Turbulence(scale(point, t.Scale), t.Octaves)
+	return blend(t.A, t.B, math.Min(1, value))
+}
+
+// Marble is veins of B in A, running across the x axis Scale times per
+// unit and twisted by Turbulence times the turbulence of the noise. With
+// no turbulence they are smooth bands, with A at x = -0.5 / Scale and B at
+// x = 0.5 / Scale.
+type Marble struct {
+	A          tuple.Color
+	B          tuple.Color
+	Scale      float64
+	Turbulence float64
+	Octaves    int
+}
+
+func NewMarble(a, b tuple.Color) *Marble {
+	return &Marble{A: a, B: b, Scale: 1, Turbulence: 2, Octaves: 4}
+}
+
+func (m *Marble) ColorAt(point tuple.Point) tuple.Color {
+	s := scale(point, m.Scale)
+	phase := s.X + m.Turbulence*noise.Turbulence(s, m.Octaves)
+	return blend(m.A, m.B, (1+math.Sin(math.Pi*phase))/2)
+}
+
+// Wood is rings around the y axis, Rings of them per unit, each going from
+// A on the inside to B on the outside and wobbled by Turbulence times the
+// turbulence of the noise.
+type Wood struct {
+	A          tuple.Color
+	B          tuple.Color
+	Rings      float64
+	Turbulence float64
+	Octaves    int
+}
+
+func NewWood(a, b tuple.Color) *Wood {
+	return &Wood{A: a, B: b, Rings: 4, Turbulence: 0.1, Octaves: 4}
+}
+
+func (w *Wood) ColorAt(point tuple.Point) tuple.Color {
+	radius := math.Hypot(point.X, point.Z) * w.Rings
+	radius += w.Turbulence * noise.Turbulence(point, w.Octaves)
+	return blend(w.A, w.B, radius-math.Floor(radius))
+}
+
+// scaled darkens or brightens another pattern by factor.
+type scaled struct {
+	pattern Pattern
+	factor  float64
+}
+
+func (s *scaled) ColorAt(point tuple.Point) tuple.Color {
+	return s.pattern.ColorAt(point).ScalarMultiply(s.factor)
+}
@@ -7,109 +7,196 @@ import (
 )
 
 type Ray struct {
-	Origin    tuple.Tuple
-	Direction tuple.Tuple
+	Origin    tuple.Point
+	Direction tuple.Vector
+	// Time is when the ray is cast, from 0 at the start of the motion of
+	// moving shapes to 1 at its end.
+	Time float64
 }
 
 type Sphere struct {
-	Id                int
-	transformation    matrix.Matrix
-	transformationInv matrix.Matrix
+	Material          Material
+	transformation    matrix.Mat4
+	transformationInv matrix.Mat4
+	// motion is set for a sphere that moves, in which case transformation
+	// is where it starts.
+	motion *motion
 }
 
+// Intersection records where a ray meets an object. Objects are told apart
+// by pointer, so they need no id.
 type Intersection struct {
 	T      float64
-	Object int
+	Object *Sphere
 }
 
-var objectCounter = 0
-
 func NewSphere() *Sphere {
-	objectCounter += 1
 	return &Sphere{
-		Id:                objectCounter,
-		transformation:    *matrix.Identity,
-		transformationInv: *matrix.Identity,
+		Material:          *NewMaterial(),
+		transformation:    matrix.Identity4,
+		transformationInv: matrix.Identity4,
 	}
 }
 
-func (s *Sphere) SetTransform(transform *matrix.Matrix) error {
+func (s *Sphere) SetTransform(transform matrix.Mat4) error {
 	inverse, err := transform.Invert()
 
 	if err != nil {
 		return err
 	}
 
-	s.transformation = *transform
-	s.transformationInv = *inverse
+	s.transformation = transform
+	s.transformationInv = inverse
+	s.motion = nil
+	return nil
+}
+
+// SetMotion makes the sphere move from start at time 0 to end at time 1,
+// so that rays cast at different times see it in different places. Both
+// transforms must be made of translation, rotation and scaling alone. A
+// sphere whose ends are the same is simply given that transform.
+func (s *Sphere) SetMotion(start, end matrix.Mat4) error {
+	if start == end {
+		return s.SetTransform(start)
+	}
+
+	m, err := newMotion(start, end)
+	if err != nil {
+		return err
+	}
+
+	s.transformation = start
+	s.transformationInv = m.startInverse
+	s.motion = m
 	return nil
 }
 
-func (s *Sphere) NormalAt(worldPoint tuple.Tuple) *tuple.Tuple {
-	objectPoint := s.transformationInv.MultiplyTuple(&worldPoint)
+// TransformAt returns the transform of the sphere at time.
+func (s *Sphere) TransformAt(time float64) matrix.Mat4 {
+	if s.motion == nil {
+		return s.transformation
+	}
+	return s.motion.transformAt(time)
+}
+
+func (s *Sphere) inverseAt(time float64) matrix.Mat4 {
+	if s.motion == nil {
+		return s.transformationInv
+	}
+	return s.motion.inverseAt(time)
+}
+
+// NormalAt returns the normal at a point on the sphere as it is at time.
+func (s *Sphere) NormalAt(worldPoint tuple.Point, time float64) tuple.Vector {
+	inverse := s.inverseAt(time)
+	objectPoint := inverse.MultiplyPoint(worldPoint)
 	objectNormal := objectPoint.Subtract(tuple.ZeroPoint)
-	worldNormal := s.transformationInv.Transpose().MultiplyTuple(objectNormal)
-	worldNormal.W = 0
+	worldNormal := inverse.Transpose().MultiplyVector(objectNormal)
 	return worldNormal.Normalize()
 }
 
-func (s *Sphere) Intersect(ray *Ray) []Intersection {
-	ray2 := ray.Transform(&s.transformationInv)
+// MaterialAt returns the material of the sphere at a point on it as it is
+// at time, coloured by its pattern there if it has one.
+func (s *Sphere) MaterialAt(worldPoint tuple.Point, time float64) Material {
+	m := s.Material
+	if m.Pattern != nil {
+		m.Color = m.Pattern.ColorAt(s.inverseAt(time).MultiplyPoint(worldPoint))
+	}
+	return m
+}
+
+// SampleSurface picks points uniformly over the unit sphere, so the area
+// each stands for is 4π scaled by how much the transformation stretches the
+// surface around it. A moving sphere is sampled where it starts.
+func (s *Sphere) SampleSurface(u, v float64) (tuple.Point, tuple.Vector, float64) {
+	z := 1 - 2*v
+	radius := math.Sqrt(math.Max(0, 1-z*z))
+	phi := 2 * math.Pi * u
+	objectNormal := tuple.NewVector(radius*math.Cos(phi), radius*math.Sin(phi), z)
+
+	point := s.transformation.MultiplyPoint(tuple.ZeroPoint.Add(objectNormal))
+	normal := s.transformationInv.Transpose().MultiplyVector(objectNormal)
+	area := 4 * math.Pi * math.Abs(s.transformation.Determinant()) * normal.Magnitude()
+
+	return point, normal.Normalize(), area
+}
+
+// Centre is the centre of the sphere, or where it starts if it moves.
+func (s *Sphere) Centre() tuple.Point {
+	return s.transformation.MultiplyPoint(tuple.ZeroPoint)
+}
+
+func (s *Sphere) Intersect(ray *Ray) Intersections {
+	ray2 := ray.Transform(s.inverseAt(ray.Time))
 
 	sphereToRay := ray2.Origin.Subtract(tuple.ZeroPoint)
 
-	a := ray2.Direction.Dot(&ray2.Direction)
+	a := ray2.Direction.Dot(ray2.Direction)
 	b := 2 * ray2.Direction.Dot(sphereToRay)
 	c := sphereToRay.Dot(sphereToRay) - 1
 
 	discriminant := math.Pow(b, 2) - 4*a*c
 
 	if discriminant < 0 {
-		return []Intersection{}
+		return NewIntersections()
 	} else {
 		t1 := (-b - math.Sqrt(discriminant)) / (2 * a)
 		t2 := (-b + math.Sqrt(discriminant)) / (2 * a)
-		return []Intersection{*s.Intersection(t1), *s.Intersection(t2)}
+		return NewIntersections(*s.Intersection(t1), *s.Intersection(t2))
 	}
 }
 
-func Hit(intersections []Intersection) *Intersection {
-	var hit *Intersection
+// AnyHit reports whether ray meets the sphere at some t with 0 <= t < maxT,
+// without working out the intersections it does not need.
+func (s *Sphere) AnyHit(ray *Ray, maxT float64) bool {
+	ray2 := ray.Transform(s.inverseAt(ray.Time))
 
-	for _, intersection := range intersections {
-		if intersection.T < 0 {
-			continue
-		}
+	sphereToRay := ray2.Origin.Subtract(tuple.ZeroPoint)
 
-		if hit == nil || intersection.T < hit.T {
-			hit = &intersection
-		}
+	a := ray2.Direction.Dot(ray2.Direction)
+	b := 2 * ray2.Direction.Dot(sphereToRay)
+	c := sphereToRay.Dot(sphereToRay) - 1
+
+	discriminant := b*b - 4*a*c
+	if discriminant < 0 {
+		return false
 	}
 
-	return hit
+	root := math.Sqrt(discriminant)
+	for _, t := range [2]float64{(-b - root) / (2 * a), (-b + root) / (2 * a)} {
+		if t >= 0 && t < maxT {
+			return true
+		}
+	}
+	return false
 }
 
 func (s *Sphere) Intersection(t float64) *Intersection {
 	return &Intersection{
 		T:      t,
-		Object: s.Id,
+		Object: s,
 	}
 }
 
-func NewRay(origin tuple.Tuple, direction tuple.Tuple) *Ray {
+func NewRay(origin tuple.Point, direction tuple.Vector) *Ray {
 	return &Ray{
 		Origin:    origin,
 		Direction: direction,
 	}
 }
 
-func (r *Ray) Position(t float64) *tuple.Tuple {
+func (r *Ray) Position(t float64) tuple.Point {
 	return r.Origin.Add(r.Direction.ScalarMultiply(t))
 }
 
-func (r *Ray) Transform(m *matrix.Matrix) *Ray {
-	return &Ray{
-		Origin:    *m.MultiplyTuple(&r.Origin),
-		Direction: *m.MultiplyTuple(&r.Direction),
+func (r *Ray) Transform(m matrix.Mat4) Ray {
+	return Ray{
+		Origin:    m.MultiplyPoint(r.Origin),
+		Direction: m.MultiplyVector(r.Direction),
+		Time:      r.Time,
 	}
 }
+
+func (s *Sphere) Transform() matrix.Mat4 {
+	return s.transformation
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"math/rand"
 	"reflect"
 	"rtt/matrix"
 	"rtt/shared"
@@ -12,6 +13,9 @@ import (
 	"rtt/transformations"
 	"rtt/tuple"
 	"rtt/tupletest"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/cucumber/godog"
@@ -30,8 +34,8 @@ func aRotation(ctx context.Context, variable, over string, value float64) (conte
 }
 
 func aMatrixMul(ctx context.Context, variable, m1Var, m2Var string) (context.Context, error) {
-	m1 := ctx.Value(sharedtest.Variables{Name: m1Var}).(*matrix.Matrix)
-	m2 := ctx.Value(sharedtest.Variables{Name: m2Var}).(*matrix.Matrix)
+	m1 := ctx.Value(sharedtest.Variables{Name: m1Var}).(matrix.Mat4)
+	m2 := ctx.Value(sharedtest.Variables{Name: m2Var}).(matrix.Mat4)
 
 	result := m1.Multiply(m2)
 
@@ -39,34 +43,74 @@ func aMatrixMul(ctx context.Context, variable, m1Var, m2Var string) (context.Con
 }
 
 func aRayFromVariables(ctx context.Context, variable, originVariable, directionVariable string) (context.Context, error) {
-	origin := ctx.Value(sharedtest.Variables{Name: originVariable}).(*tuple.Tuple)
-	direction := ctx.Value(sharedtest.Variables{Name: directionVariable}).(*tuple.Tuple)
+	origin := ctx.Value(sharedtest.Variables{Name: originVariable}).(tuple.Point)
+	direction := ctx.Value(sharedtest.Variables{Name: directionVariable}).(tuple.Vector)
 
-	ray := NewRay(*origin, *direction)
+	ray := NewRay(origin, direction)
 
 	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, ray), nil
 }
 
 func aPointLightFromVariables(ctx context.Context, variable, positionVariable, intensityVariable string) (context.Context, error) {
-	position := ctx.Value(sharedtest.Variables{Name: positionVariable}).(*tuple.Tuple)
-	intensity := ctx.Value(sharedtest.Variables{Name: intensityVariable}).(*tuple.Tuple)
+	position := ctx.Value(sharedtest.Variables{Name: positionVariable}).(tuple.Point)
+	intensity := ctx.Value(sharedtest.Variables{Name: intensityVariable}).(tuple.Color)
 
-	pointLight := NewPointLight(*position, *intensity)
+	pointLight := NewPointLight(position, intensity)
 
 	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, pointLight), nil
 }
 
-func aMaterial(ctx context.Context, variable, positionVariable, intensityVariable string) (context.Context, error) {
+func aConvertedMaterial(ctx context.Context, variable, materialVariable string) (context.Context, error) {
+	material := ctx.Value(sharedtest.Variables{Name: materialVariable}).(*Material)
+	converted := material.MetallicRoughness()
+
+	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, &converted), nil
+}
+
+func aMaterial(ctx context.Context, variable string) (context.Context, error) {
 	material := NewMaterial()
 
 	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, material), nil
 }
 
+func aPattern(ctx context.Context, variable, kind string, ar, ag, ab, br, bg, bb float64) (context.Context, error) {
+	a, b := tuple.NewColor(ar, ag, ab), tuple.NewColor(br, bg, bb)
+
+	var pattern Pattern
+	switch kind {
+	case "stripes":
+		pattern = NewStripes(a, b)
+	case "marble":
+		pattern = NewMarble(a, b)
+	case "wood":
+		pattern = NewWood(a, b)
+	default:
+		pattern = NewTurbulence(a, b)
+	}
+	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, pattern), nil
+}
+
+func aPerturbedPattern(ctx context.Context, variable, patternVariable string, amount float64) (context.Context, error) {
+	pattern := ctx.Value(sharedtest.Variables{Name: patternVariable}).(Pattern)
+	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, NewPerturb(pattern, amount)), nil
+}
+
+func aMaterialPattern(ctx context.Context, variable, materialVariable string) (context.Context, error) {
+	material := ctx.Value(sharedtest.Variables{Name: materialVariable}).(*Material)
+	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, material.Pattern), nil
+}
+
+func aMaterialAt(ctx context.Context, variable, sphereVariable string, x, y, z float64) (context.Context, error) {
+	sphere := ctx.Value(sharedtest.Variables{Name: sphereVariable}).(*Sphere)
+	material := sphere.MaterialAt(tuple.NewPoint(x, y, z), 0)
+	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, &material), nil
+}
+
 func aRayFromValues(ctx context.Context, variable string, originX, originY, originZ, directionX, directionY, directionZ float64) (context.Context, error) {
-	origin := tuple.Point(originX, originY, originZ)
-	direction := tuple.Vector(directionX, directionY, directionZ)
+	origin := tuple.NewPoint(originX, originY, originZ)
+	direction := tuple.NewVector(directionX, directionY, directionZ)
 
-	ray := NewRay(*origin, *direction)
+	ray := NewRay(origin, direction)
 
 	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, ray), nil
 }
@@ -76,6 +120,22 @@ func aSphere(ctx context.Context, variable string) (context.Context, error) {
 	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, sphere), nil
 }
 
+func someConcurrentSpheres(ctx context.Context, variable string, count int) (context.Context, error) {
+	spheres := make([]*Sphere, count)
+
+	var wg sync.WaitGroup
+	for i := range spheres {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			spheres[i] = NewSphere()
+		}()
+	}
+	wg.Wait()
+
+	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, spheres), nil
+}
+
 func aIntersect(ctx context.Context, variable, sphereVariable, rayVariable string) (context.Context, error) {
 	sphere := ctx.Value(sharedtest.Variables{Name: sphereVariable}).(*Sphere)
 	ray := ctx.Value(sharedtest.Variables{Name: rayVariable}).(*Ray)
@@ -104,32 +164,50 @@ func aScalingMatrix(ctx context.Context, variable string, x, y, z float64) (cont
 }
 
 func aTransform(ctx context.Context, variable, rayVariable, matrixVariable string) (context.Context, error) {
-	matrix := ctx.Value(sharedtest.Variables{Name: matrixVariable}).(*matrix.Matrix)
+	matrix := ctx.Value(sharedtest.Variables{Name: matrixVariable}).(matrix.Mat4)
 	ray := ctx.Value(sharedtest.Variables{Name: rayVariable}).(*Ray)
-	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, ray.Transform(matrix)), nil
+	transformed := ray.Transform(matrix)
+	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, &transformed), nil
 }
 
-func aIntersections2(ctx context.Context, variable, i1Variable, i2Variable string) (context.Context, error) {
-	i1 := ctx.Value(sharedtest.Variables{Name: i1Variable}).(*Intersection)
-	i2 := ctx.Value(sharedtest.Variables{Name: i2Variable}).(*Intersection)
-	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, []Intersection{*i1, *i2}), nil
+func someIntersections(ctx context.Context, variable, list string) (context.Context, error) {
+	xs := []Intersection{}
+	for _, name := range strings.Split(list, ", ") {
+		xs = append(xs, *ctx.Value(sharedtest.Variables{Name: name}).(*Intersection))
+	}
+	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, NewIntersections(xs...)), nil
 }
 
-func aIntersections4(ctx context.Context, variable, i1Variable, i2Variable, i3Variable, i4Variable string) (context.Context, error) {
-	i1 := ctx.Value(sharedtest.Variables{Name: i1Variable}).(*Intersection)
-	i2 := ctx.Value(sharedtest.Variables{Name: i2Variable}).(*Intersection)
-	i3 := ctx.Value(sharedtest.Variables{Name: i3Variable}).(*Intersection)
-	i4 := ctx.Value(sharedtest.Variables{Name: i4Variable}).(*Intersection)
-	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, []Intersection{*i1, *i2, *i3, *i4}), nil
+func aMerge(ctx context.Context, variable, list string) (context.Context, error) {
+	lists := []Intersections{}
+	for _, name := range strings.Split(list, ", ") {
+		lists = append(lists, ctx.Value(sharedtest.Variables{Name: name}).(Intersections))
+	}
+	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, MergeAll(lists...)), nil
+}
+
+func aWithin(ctx context.Context, variable, intersectionsVariable string, min, max float64) (context.Context, error) {
+	xs := ctx.Value(sharedtest.Variables{Name: intersectionsVariable}).(Intersections)
+	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, xs.Within(min, max)), nil
+}
+
+func aFilter(ctx context.Context, variable, intersectionsVariable, objectVariable string) (context.Context, error) {
+	xs := ctx.Value(sharedtest.Variables{Name: intersectionsVariable}).(Intersections)
+	object := ctx.Value(sharedtest.Variables{Name: objectVariable}).(*Sphere)
+
+	result := xs.Filter(func(i Intersection) bool {
+		return i.Object == object
+	})
+	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, result), nil
 }
 
 func aHit(ctx context.Context, variable, intersectionsVariable string) (context.Context, error) {
-	intersections := ctx.Value(sharedtest.Variables{Name: intersectionsVariable}).([]Intersection)
-	result := Hit(intersections)
+	intersections := ctx.Value(sharedtest.Variables{Name: intersectionsVariable}).(Intersections)
+	result := intersections.Hit()
 	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, result), nil
 }
 
-func aNormalAt(ctx context.Context, variable, sphereVariable, xStr, yStr, zStr string) (context.Context, error) {
+func aNormalAt(ctx context.Context, variable, sphereVariable, xStr, yStr, zStr, timeStr string) (context.Context, error) {
 	sphere := ctx.Value(sharedtest.Variables{Name: sphereVariable}).(*Sphere)
 	x, y, z, err := sharedtest.ParseXYZ(xStr, yStr, zStr)
 
@@ -137,13 +215,44 @@ func aNormalAt(ctx context.Context, variable, sphereVariable, xStr, yStr, zStr s
 		return ctx, err
 	}
 
-	result := sphere.NormalAt(*tuple.Point(x, y, z))
+	time := 0.0
+	if timeStr != "" {
+		if time, err = strconv.ParseFloat(timeStr, 64); err != nil {
+			return ctx, err
+		}
+	}
+
+	result := sphere.NormalAt(tuple.NewPoint(x, y, z), time)
 	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, result), nil
 }
 
+func setMotion(ctx context.Context, sphereVariable, startVariable, endVariable string) (context.Context, error) {
+	sphere := ctx.Value(sharedtest.Variables{Name: sphereVariable}).(*Sphere)
+	start := ctx.Value(sharedtest.Variables{Name: startVariable}).(matrix.Mat4)
+	end := ctx.Value(sharedtest.Variables{Name: endVariable}).(matrix.Mat4)
+	return ctx, sphere.SetMotion(start, end)
+}
+
+func assertSetMotionFails(ctx context.Context, sphereVariable, startVariable, endVariable, expected string) error {
+	sphere := ctx.Value(sharedtest.Variables{Name: sphereVariable}).(*Sphere)
+	start := ctx.Value(sharedtest.Variables{Name: startVariable}).(matrix.Mat4)
+	end := ctx.Value(sharedtest.Variables{Name: endVariable}).(matrix.Mat4)
+
+	err := sphere.SetMotion(start, end)
+	if err == nil || err.Error() != expected {
+		return fmt.Errorf("expected error %q, got %v", expected, err)
+	}
+	return nil
+}
+
+func setRayTime(ctx context.Context, rayVariable string, time float64) (context.Context, error) {
+	ctx.Value(sharedtest.Variables{Name: rayVariable}).(*Ray).Time = time
+	return ctx, nil
+}
+
 func setTransform(ctx context.Context, sphereVariable, matrixVariable string) (context.Context, error) {
 	sphere := ctx.Value(sharedtest.Variables{Name: sphereVariable}).(*Sphere)
-	matrix := ctx.Value(sharedtest.Variables{Name: matrixVariable}).(*matrix.Matrix)
+	matrix := ctx.Value(sharedtest.Variables{Name: matrixVariable}).(matrix.Mat4)
 	err := sphere.SetTransform(matrix)
 
 	if err != nil {
@@ -155,19 +264,19 @@ func setTransform(ctx context.Context, sphereVariable, matrixVariable string) (c
 
 func assertRayComponent(ctx context.Context, rayVariable, component, tupleVariable string) (context.Context, error) {
 	ray := ctx.Value(sharedtest.Variables{Name: rayVariable}).(*Ray)
-	t := ctx.Value(sharedtest.Variables{Name: tupleVariable}).(*tuple.Tuple)
+	t, _ := tupletest.AsTuple(ctx, tupleVariable)
 
-	var expected tuple.Tuple
+	var expected *tuple.Tuple
 
 	if component == "origin" {
-		expected = ray.Origin
+		expected = ray.Origin.Tuple()
 	} else if component == "direction" {
-		expected = ray.Direction
+		expected = ray.Direction.Tuple()
 	} else {
 		return ctx, fmt.Errorf("unknown component %s", component)
 	}
 
-	if !tuple.CompareTuple(t, &expected) {
+	if !tuple.CompareTuple(t, expected) {
 		return ctx, fmt.Errorf("Error %+v != %+v!", t, expected)
 	}
 
@@ -176,48 +285,570 @@ func assertRayComponent(ctx context.Context, rayVariable, component, tupleVariab
 
 func assertPointLightComponent(ctx context.Context, pointLightVariable, component, tupleVariable string) (context.Context, error) {
 	pointLight := ctx.Value(sharedtest.Variables{Name: pointLightVariable}).(*PointLight)
-	t := ctx.Value(sharedtest.Variables{Name: tupleVariable}).(*tuple.Tuple)
+	t, _ := tupletest.AsTuple(ctx, tupleVariable)
 
-	var expected tuple.Tuple
+	var expected *tuple.Tuple
 
 	if component == "position" {
-		expected = pointLight.Position
+		expected = pointLight.Position.Tuple()
 	} else if component == "intensity" {
-		expected = pointLight.Intensity
+		expected = pointLight.Intensity.Tuple()
 	} else {
 		return ctx, fmt.Errorf("unknown component %s", component)
 	}
 
-	if !tuple.CompareTuple(t, &expected) {
+	if !tuple.CompareTuple(t, expected) {
 		return ctx, fmt.Errorf("Error %+v != %+v!", t, expected)
 	}
 
 	return ctx, nil
 }
 
-func assertMaterialComponent(ctx context.Context, pointLightVariable, component, tupleVariable string) (context.Context, error) {
-	pointLight := ctx.Value(sharedtest.Variables{Name: pointLightVariable}).(*PointLight)
-	t := ctx.Value(sharedtest.Variables{Name: tupleVariable}).(*tuple.Tuple)
+func assertMaterialComponent(ctx context.Context, materialVariable, component string, expected float64) (context.Context, error) {
+	material := ctx.Value(sharedtest.Variables{Name: materialVariable}).(*Material)
+
+	var actual float64
+
+	switch component {
+	case "ambient":
+		actual = material.Ambient
+	case "diffuse":
+		actual = material.Diffuse
+	case "specular":
+		actual = material.Specular
+	case "shininess":
+		actual = material.Shininess
+	case "metallic":
+		actual = material.Metallic
+	case "roughness":
+		actual = material.Roughness
+	case "reflective":
+		actual = material.Reflective
+	default:
+		return ctx, fmt.Errorf("unknown component %s", component)
+	}
 
-	var expected tuple.Tuple
+	if !shared.CompareFloat(actual, expected) {
+		return ctx, fmt.Errorf("Error %f != %f!", actual, expected)
+	}
 
-	if component == "position" {
-		expected = pointLight.Position
-	} else if component == "intensity" {
-		expected = pointLight.Intensity
-	} else {
+	return ctx, nil
+}
+
+func assertMaterialModel(ctx context.Context, materialVariable, expected string) (context.Context, error) {
+	material := ctx.Value(sharedtest.Variables{Name: materialVariable}).(*Material)
+
+	if material.Model.String() != expected {
+		return ctx, fmt.Errorf("Error model %s != %s!", material.Model, expected)
+	}
+
+	return ctx, nil
+}
+
+func assertUnknownModel(ctx context.Context, name, expected string) (context.Context, error) {
+	_, err := ParseModel(name)
+	if err == nil {
+		return ctx, fmt.Errorf("model %q was parsed", name)
+	}
+
+	expected = strings.ReplaceAll(expected, `\"`, `"`)
+	if err.Error() != expected {
+		return ctx, fmt.Errorf("Error %q != %q!", err.Error(), expected)
+	}
+
+	return ctx, nil
+}
+
+// reflectance integrates the BRDF of a material times the cosine to the
+// normal over a fine grid of cosine-weighted directions.
+func reflectance(material *Material, eyev, normalv tuple.Vector) tuple.Color {
+	const side = 400
+	sum := tuple.Black
+
+	for i := 0; i < side; i++ {
+		for j := 0; j < side; j++ {
+			lightv := cosineHemisphere(normalv, (float64(i)+0.5)/side, (float64(j)+0.5)/side)
+			sum = sum.Add(material.BRDF(lightv, eyev, normalv))
+		}
+	}
+
+	return sum.ScalarMultiply(math.Pi / (side * side))
+}
+
+func assertReflectanceAtMostOne(ctx context.Context, materialVariable, eyeVariable, normalVariable string) (context.Context, error) {
+	material := ctx.Value(sharedtest.Variables{Name: materialVariable}).(*Material)
+	eyev := ctx.Value(sharedtest.Variables{Name: eyeVariable}).(tuple.Vector)
+	normalv := ctx.Value(sharedtest.Variables{Name: normalVariable}).(tuple.Vector)
+
+	if r := reflectance(material, eyev, normalv); r.R > 1 || r.G > 1 || r.B > 1 {
+		return ctx, fmt.Errorf("Error reflectance %+v is more than 1!", r)
+	}
+
+	return ctx, nil
+}
+
+func assertSampledReflectance(ctx context.Context, count int, materialVariable, eyeVariable, normalVariable string) (context.Context, error) {
+	material := ctx.Value(sharedtest.Variables{Name: materialVariable}).(*Material)
+	eyev := ctx.Value(sharedtest.Variables{Name: eyeVariable}).(tuple.Vector)
+	normalv := ctx.Value(sharedtest.Variables{Name: normalVariable}).(tuple.Vector)
+
+	rng := rand.New(rand.NewSource(1))
+	sum := tuple.Black
+	for i := 0; i < count; i++ {
+		lightv, weight := material.SampleBounce(eyev, normalv, rng)
+		if weight != tuple.Black && lightv.Dot(normalv) <= 0 {
+			return ctx, fmt.Errorf("Error sample %+v is below the surface!", lightv)
+		}
+		sum = sum.Add(weight)
+	}
+
+	expected := reflectance(material, eyev, normalv)
+	tolerance := shared.Tolerance{Absolute: 0.01}
+	if actual := sum.ScalarDiv(float64(count)); !tuple.CompareTupleWithin(actual.Tuple(), expected.Tuple(), tolerance) {
+		return ctx, fmt.Errorf("Error sampled reflectance %+v != %+v!", actual, expected)
+	}
+
+	return ctx, nil
+}
+
+func assertCosineSamples(ctx context.Context, count int, x, y, z, mean float64) (context.Context, error) {
+	normal := tuple.NewVector(x, y, z)
+	side := int(math.Sqrt(float64(count)))
+	sum := 0.0
+
+	for i := 0; i < side; i++ {
+		for j := 0; j < side; j++ {
+			d := cosineHemisphere(normal, (float64(i)+0.5)/float64(side), (float64(j)+0.5)/float64(side))
+
+			if !shared.CompareFloat(d.Magnitude(), 1) {
+				return ctx, fmt.Errorf("Error sample %+v is not a unit vector!", d)
+			}
+			if d.Dot(normal) < 0 {
+				return ctx, fmt.Errorf("Error sample %+v points away from %+v!", d, normal)
+			}
+			sum += d.Dot(normal)
+		}
+	}
+
+	if actual := sum / float64(side*side); math.Abs(actual-mean) > 0.001 {
+		return ctx, fmt.Errorf("Error mean cosine %f != %f!", actual, mean)
+	}
+
+	return ctx, nil
+}
+
+func assertCastsShadow(ctx context.Context, materialVariable, expected string) (context.Context, error) {
+	material := ctx.Value(sharedtest.Variables{Name: materialVariable}).(*Material)
+
+	if material.CastsShadow != (expected == "true") {
+		return ctx, fmt.Errorf("Error casts_shadow was %t!", material.CastsShadow)
+	}
+
+	return ctx, nil
+}
+
+func assertAnyHit(ctx context.Context, sphereVariable, rayVariable string, maxT float64, expected string) (context.Context, error) {
+	sphere := ctx.Value(sharedtest.Variables{Name: sphereVariable}).(*Sphere)
+	ray := ctx.Value(sharedtest.Variables{Name: rayVariable}).(*Ray)
+
+	if actual := sphere.AnyHit(ray, maxT); actual != (expected == "true") {
+		return ctx, fmt.Errorf("Error any_hit was %t!", actual)
+	}
+
+	return ctx, nil
+}
+
+func assertPatternAt(ctx context.Context, patternVariable string, x, y, z, r, g, b float64) error {
+	pattern := ctx.Value(sharedtest.Variables{Name: patternVariable}).(Pattern)
+	actual := pattern.ColorAt(tuple.NewPoint(x, y, z))
+	expected := tuple.NewColor(r, g, b)
+
+	if !actual.Equals(expected) {
+		return fmt.Errorf("Error %+v != %+v!", actual, expected)
+	}
+	return nil
+}
+
+func assertSamePatternAt(ctx context.Context, aVariable string, ax, ay, az float64, bVariable string, bx, by, bz float64) error {
+	a := ctx.Value(sharedtest.Variables{Name: aVariable}).(Pattern).ColorAt(tuple.NewPoint(ax, ay, az))
+	b := ctx.Value(sharedtest.Variables{Name: bVariable}).(Pattern).ColorAt(tuple.NewPoint(bx, by, bz))
+
+	if !a.Equals(b) {
+		return fmt.Errorf("Error %+v != %+v!", a, b)
+	}
+	return nil
+}
+
+func assertDifferentPatternAt(ctx context.Context, aVariable string, ax, ay, az float64, bVariable string, bx, by, bz float64) error {
+	if assertSamePatternAt(ctx, aVariable, ax, ay, az, bVariable, bx, by, bz) == nil {
+		return fmt.Errorf("Error %s and %s are the same!", aVariable, bVariable)
+	}
+	return nil
+}
+
+func assertMaterialColor(ctx context.Context, materialVariable string, r, g, b float64) (context.Context, error) {
+	material := ctx.Value(sharedtest.Variables{Name: materialVariable}).(*Material)
+	expected := tuple.NewColor(r, g, b)
+
+	if !material.Color.Equals(expected) {
+		return ctx, fmt.Errorf("Error %+v != %+v!", material.Color, expected)
+	}
+
+	return ctx, nil
+}
+
+func aPointLightFromValues(ctx context.Context, variable string, x, y, z, r, g, b float64) (context.Context, error) {
+	pointLight := NewPointLight(tuple.NewPoint(x, y, z), tuple.NewColor(r, g, b))
+
+	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, pointLight), nil
+}
+
+func aBoolean(ctx context.Context, variable, value string) (context.Context, error) {
+	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, value == "true"), nil
+}
+
+func aLighting(ctx context.Context, variable, materialVariable, lightVariable, positionVariable, eyeVariable, normalVariable, intensityValue string) (context.Context, error) {
+	material := ctx.Value(sharedtest.Variables{Name: materialVariable}).(*Material)
+	position := ctx.Value(sharedtest.Variables{Name: positionVariable}).(tuple.Point)
+	eyev := ctx.Value(sharedtest.Variables{Name: eyeVariable}).(tuple.Vector)
+	normalv := ctx.Value(sharedtest.Variables{Name: normalVariable}).(tuple.Vector)
+
+	intensity := 1.0
+	if value, err := strconv.ParseFloat(intensityValue, 64); err == nil {
+		intensity = value
+	} else if intensityValue != "" && ctx.Value(sharedtest.Variables{Name: intensityValue}).(bool) {
+		intensity = 0
+	}
+
+	light := ctx.Value(sharedtest.Variables{Name: lightVariable}).(Light)
+	result := Lighting(material, light, position, eyev, normalv, intensity, nil)
+
+	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, result), nil
+}
+
+func aDirectionalLight(ctx context.Context, variable string, x, y, z, r, g, b float64) (context.Context, error) {
+	light := NewDirectionalLight(tuple.NewVector(x, y, z), tuple.NewColor(r, g, b))
+	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, light), nil
+}
+
+func aSpotLight(ctx context.Context, variable string, px, py, pz, dx, dy, dz, r, g, b, angleDivisor, falloffDivisor float64) (context.Context, error) {
+	light := NewSpotLight(tuple.NewPoint(px, py, pz), tuple.NewVector(dx, dy, dz), tuple.NewColor(r, g, b), math.Pi/angleDivisor, math.Pi/falloffDivisor)
+	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, light), nil
+}
+
+func aDirectionToLight(ctx context.Context, variable, lightVariable, pointVariable string) (context.Context, error) {
+	light := ctx.Value(sharedtest.Variables{Name: lightVariable}).(Light)
+	p := ctx.Value(sharedtest.Variables{Name: pointVariable}).(tuple.Point)
+	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, light.DirectionFrom(p)), nil
+}
+
+func aDistanceToLight(ctx context.Context, variable, lightVariable, pointVariable string) (context.Context, error) {
+	light := ctx.Value(sharedtest.Variables{Name: lightVariable}).(Light)
+	p := ctx.Value(sharedtest.Variables{Name: pointVariable}).(tuple.Point)
+	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, light.DistanceFrom(p)), nil
+}
+
+type surfaceSample struct {
+	point  tuple.Point
+	normal tuple.Vector
+	area   float64
+}
+
+func aShapeLight(ctx context.Context, variable, sphereVariable string, usteps, vsteps int, r, g, b float64) (context.Context, error) {
+	sphere := ctx.Value(sharedtest.Variables{Name: sphereVariable}).(*Sphere)
+	light := NewShapeLight(sphere, tuple.NewColor(r, g, b), usteps, vsteps)
+	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, light), nil
+}
+
+func aSurfaceSample(ctx context.Context, variable, sphereVariable string, u, v float64) (context.Context, error) {
+	sphere := ctx.Value(sharedtest.Variables{Name: sphereVariable}).(*Sphere)
+	point, normal, area := sphere.SampleSurface(u, v)
+	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, surfaceSample{point, normal, area}), nil
+}
+
+func assertSurfaceSample(ctx context.Context, variable, component string, x, y, z float64) (context.Context, error) {
+	sample := ctx.Value(sharedtest.Variables{Name: variable}).(surfaceSample)
+
+	actual, expected := sample.point.Tuple(), tuple.NewPoint(x, y, z).Tuple()
+	if component == "normal" {
+		actual, expected = sample.normal.Tuple(), tuple.NewVector(x, y, z).Tuple()
+	}
+
+	if !tuple.CompareTuple(actual, expected) {
+		return ctx, fmt.Errorf("Error %s %+v != %+v!", component, actual, expected)
+	}
+	return ctx, nil
+}
+
+func assertSurfaceSampleArea(ctx context.Context, variable string, expected float64) (context.Context, error) {
+	sample := ctx.Value(sharedtest.Variables{Name: variable}).(surfaceSample)
+
+	if !shared.CompareFloat(sample.area, expected) {
+		return ctx, fmt.Errorf("Error area %f != %f!", sample.area, expected)
+	}
+	return ctx, nil
+}
+
+func assertSampledArea(ctx context.Context, lightVariable string, expected, tolerance float64) (context.Context, error) {
+	light := ctx.Value(sharedtest.Variables{Name: lightVariable}).(*ShapeLight)
+
+	samples := light.Sample(nil)
+	total := 0.0
+	for _, sample := range samples {
+		total += sample.(*surfaceLight).Area
+	}
+
+	if actual := total / float64(len(samples)); math.Abs(actual-expected) > tolerance {
+		return ctx, fmt.Errorf("Error area %f != %f!", actual, expected)
+	}
+	return ctx, nil
+}
+
+func assertMeanSampleIntensity(ctx context.Context, lightVariable string, x, y, z, nx, ny, nz, expected, tolerance float64) (context.Context, error) {
+	light := ctx.Value(sharedtest.Variables{Name: lightVariable}).(Light)
+	point := tuple.NewPoint(x, y, z)
+	normal := tuple.NewVector(nx, ny, nz)
+
+	samples := light.Sample(nil)
+	total := 0.0
+	for _, sample := range samples {
+		total += sample.IntensityAt(point).R * math.Max(0, sample.DirectionFrom(point).Dot(normal))
+	}
+
+	if actual := total / float64(len(samples)); math.Abs(actual-expected) > tolerance {
+		return ctx, fmt.Errorf("Error mean intensity %f != %f!", actual, expected)
+	}
+	return ctx, nil
+}
+
+func anIntensityAt(ctx context.Context, variable, lightVariable, pointVariable string) (context.Context, error) {
+	light := ctx.Value(sharedtest.Variables{Name: lightVariable}).(Light)
+	p := ctx.Value(sharedtest.Variables{Name: pointVariable}).(tuple.Point)
+	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, light.IntensityAt(p)), nil
+}
+
+func assertDistance(ctx context.Context, variable string, expected float64) (context.Context, error) {
+	actual := ctx.Value(sharedtest.Variables{Name: variable}).(float64)
+
+	if !shared.CompareFloat(actual, expected) {
+		return ctx, fmt.Errorf("Error %f != %f!", actual, expected)
+	}
+
+	return ctx, nil
+}
+
+func assertInfinite(ctx context.Context, variable string) (context.Context, error) {
+	actual := ctx.Value(sharedtest.Variables{Name: variable}).(float64)
+
+	if !math.IsInf(actual, 1) {
+		return ctx, fmt.Errorf("Error %f is not infinite!", actual)
+	}
+
+	return ctx, nil
+}
+
+func anAreaLight(ctx context.Context, variable, cornerVariable, uVariable string, usteps int, vVariable string, vsteps int, r, g, b float64) (context.Context, error) {
+	corner := ctx.Value(sharedtest.Variables{Name: cornerVariable}).(tuple.Point)
+	uvec := ctx.Value(sharedtest.Variables{Name: uVariable}).(tuple.Vector)
+	vvec := ctx.Value(sharedtest.Variables{Name: vVariable}).(tuple.Vector)
+
+	light := NewAreaLight(corner, uvec, usteps, vvec, vsteps, tuple.NewColor(r, g, b))
+
+	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, light), nil
+}
+
+func aPointOnLight(ctx context.Context, variable, lightVariable string, u, v int) (context.Context, error) {
+	light := ctx.Value(sharedtest.Variables{Name: lightVariable}).(*AreaLight)
+	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, light.PointOnLight(u, v, nil)), nil
+}
+
+func aNormalizedDifference(ctx context.Context, variable, leftVariable, rightVariable string) (context.Context, error) {
+	left := ctx.Value(sharedtest.Variables{Name: leftVariable}).(tuple.Point)
+	right := ctx.Value(sharedtest.Variables{Name: rightVariable}).(tuple.Point)
+	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, left.Subtract(right).Normalize()), nil
+}
+
+func aVectorFromPoint(ctx context.Context, variable, pointVariable string) (context.Context, error) {
+	p := ctx.Value(sharedtest.Variables{Name: pointVariable}).(tuple.Point)
+	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, p.Subtract(tuple.ZeroPoint)), nil
+}
+
+func setJitter(ctx context.Context, lightVariable, valuesString string) (context.Context, error) {
+	values := []float64{}
+	for _, s := range strings.Split(valuesString, ", ") {
+		value, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return ctx, err
+		}
+		values = append(values, value)
+	}
+
+	switch light := ctx.Value(sharedtest.Variables{Name: lightVariable}).(type) {
+	case *AreaLight:
+		light.JitterBy = Sequence(values...)
+	case *ShapeLight:
+		light.JitterBy = Sequence(values...)
+	}
+	return ctx, nil
+}
+
+func setMaterialModel(ctx context.Context, materialVariable, name string) (context.Context, error) {
+	material := ctx.Value(sharedtest.Variables{Name: materialVariable}).(*Material)
+
+	model, err := ParseModel(name)
+	material.Model = model
+	return ctx, err
+}
+
+func setMaterialColor(ctx context.Context, materialVariable string, r, g, b float64) (context.Context, error) {
+	material := ctx.Value(sharedtest.Variables{Name: materialVariable}).(*Material)
+	material.Color = tuple.NewColor(r, g, b)
+	return ctx, nil
+}
+
+func setPatternComponent(ctx context.Context, patternVariable, component string, value float64) (context.Context, error) {
+	switch p := ctx.Value(sharedtest.Variables{Name: patternVariable}).(type) {
+	case *Marble:
+		switch component {
+		case "scale":
+			p.Scale = value
+		case "turbulence":
+			p.Turbulence = value
+		case "octaves":
+			p.Octaves = int(value)
+		default:
+			return ctx, fmt.Errorf("marble has no %s", component)
+		}
+	case *Wood:
+		switch component {
+		case "rings":
+			p.Rings = value
+		case "turbulence":
+			p.Turbulence = value
+		case "octaves":
+			p.Octaves = int(value)
+		default:
+			return ctx, fmt.Errorf("wood has no %s", component)
+		}
+	case *Turbulence:
+		switch component {
+		case "scale":
+			p.Scale = value
+		case "octaves":
+			p.Octaves = int(value)
+		default:
+			return ctx, fmt.Errorf("turbulence has no %s", component)
+		}
+	case *Perturb:
+		switch component {
+		case "scale":
+			p.Scale = value
+		default:
+			return ctx, fmt.Errorf("perturb has no %s", component)
+		}
+	}
+	return ctx, nil
+}
+
+func setSpherePattern(ctx context.Context, sphereVariable, patternVariable string) (context.Context, error) {
+	sphere := ctx.Value(sharedtest.Variables{Name: sphereVariable}).(*Sphere)
+	sphere.Material.Pattern = ctx.Value(sharedtest.Variables{Name: patternVariable}).(Pattern)
+	return ctx, nil
+}
+
+func setMaterialPattern(ctx context.Context, materialVariable, patternVariable string) (context.Context, error) {
+	material := ctx.Value(sharedtest.Variables{Name: materialVariable}).(*Material)
+	material.Pattern = ctx.Value(sharedtest.Variables{Name: patternVariable}).(Pattern)
+	return ctx, nil
+}
+
+func setMaterialComponent(ctx context.Context, materialVariable, component string, value float64) (context.Context, error) {
+	material := ctx.Value(sharedtest.Variables{Name: materialVariable}).(*Material)
+
+	switch component {
+	case "ambient":
+		material.Ambient = value
+	case "diffuse":
+		material.Diffuse = value
+	case "specular":
+		material.Specular = value
+	case "shininess":
+		material.Shininess = value
+	case "metallic":
+		material.Metallic = value
+	case "roughness":
+		material.Roughness = value
+	case "reflective":
+		material.Reflective = value
+	default:
 		return ctx, fmt.Errorf("unknown component %s", component)
 	}
 
-	if !tuple.CompareTuple(t, &expected) {
-		return ctx, fmt.Errorf("Error %+v != %+v!", t, expected)
+	return ctx, nil
+}
+
+func assertAreaLightTuple(ctx context.Context, lightVariable, component, kind string, x, y, z float64) (context.Context, error) {
+	light := ctx.Value(sharedtest.Variables{Name: lightVariable}).(*AreaLight)
+
+	var actual *tuple.Tuple
+
+	switch component {
+	case "corner":
+		actual = light.Corner.Tuple()
+	case "uvec":
+		actual = light.UVec.Tuple()
+	case "vvec":
+		actual = light.VVec.Tuple()
+	case "position":
+		actual = light.Position.Tuple()
+	default:
+		return ctx, fmt.Errorf("unknown component %s", component)
+	}
+
+	expected := tuple.NewVector(x, y, z).Tuple()
+	if kind == "point" {
+		expected = tuple.NewPoint(x, y, z).Tuple()
+	}
+
+	if !tuple.CompareTuple(actual, expected) {
+		return ctx, fmt.Errorf("Error %+v != %+v!", actual, expected)
+	}
+
+	return ctx, nil
+}
+
+func assertAreaLightSteps(ctx context.Context, lightVariable, component string, expected int) (context.Context, error) {
+	var usteps, vsteps, samples int
+
+	switch light := ctx.Value(sharedtest.Variables{Name: lightVariable}).(type) {
+	case *AreaLight:
+		usteps, vsteps, samples = light.USteps, light.VSteps, light.Samples
+	case *ShapeLight:
+		usteps, vsteps, samples = light.USteps, light.VSteps, light.Samples
+	}
+
+	var actual int
+
+	switch component {
+	case "usteps":
+		actual = usteps
+	case "vsteps":
+		actual = vsteps
+	case "samples":
+		actual = samples
+	default:
+		return ctx, fmt.Errorf("unknown component %s", component)
+	}
+
+	if actual != expected {
+		return ctx, fmt.Errorf("Error %d != %d!", actual, expected)
 	}
 
 	return ctx, nil
 }
 
 func assertIntersectionsT(ctx context.Context, intersectionVariable string, index int, t float64) (context.Context, error) {
-	intersections := ctx.Value(sharedtest.Variables{Name: intersectionVariable}).([]Intersection)
+	intersections := ctx.Value(sharedtest.Variables{Name: intersectionVariable}).(Intersections)
 	intersection := intersections[index]
 
 	if !shared.CompareFloat(intersection.T, t) {
@@ -228,24 +859,63 @@ func assertIntersectionsT(ctx context.Context, intersectionVariable string, inde
 }
 
 func assertIntersectionsObject(ctx context.Context, intersectionVariable string, index int, objectVariable string) (context.Context, error) {
-	intersections := ctx.Value(sharedtest.Variables{Name: intersectionVariable}).([]Intersection)
+	intersections := ctx.Value(sharedtest.Variables{Name: intersectionVariable}).(Intersections)
 	intersection := intersections[index]
 
 	object := ctx.Value(sharedtest.Variables{Name: objectVariable}).(*Sphere)
 
-	if intersection.Object != object.Id {
-		return ctx, fmt.Errorf("Error %d != %d!", intersection.Object, object.Id)
+	if intersection.Object != object {
+		return ctx, fmt.Errorf("Error %p != %p!", intersection.Object, object)
+	}
+
+	return ctx, nil
+}
+
+func assertOwnIntersections(ctx context.Context, variable, rayVariable string) (context.Context, error) {
+	spheres := ctx.Value(sharedtest.Variables{Name: variable}).([]*Sphere)
+	r := ctx.Value(sharedtest.Variables{Name: rayVariable}).(*Ray)
+
+	for i, s := range spheres {
+		for _, other := range spheres[:i] {
+			if s == other {
+				return ctx, fmt.Errorf("Error sphere %d is not distinct!", i)
+			}
+		}
+
+		for _, intersection := range s.Intersect(r) {
+			if intersection.Object != s {
+				return ctx, fmt.Errorf("Error sphere %d intersected as %p!", i, intersection.Object)
+			}
+		}
 	}
 
 	return ctx, nil
 }
 
+func assertTransformAt(ctx context.Context, sphereVariable string, time float64, matrixVariable string) error {
+	sphere := ctx.Value(sharedtest.Variables{Name: sphereVariable}).(*Sphere)
+	m := ctx.Value(sharedtest.Variables{Name: matrixVariable}).(matrix.Mat4)
+
+	if actual := sphere.TransformAt(time); !actual.Equals(m) {
+		return fmt.Errorf("Error %+v != %+v!", actual, m)
+	}
+	return nil
+}
+
+func assertRayTime(ctx context.Context, rayVariable string, expected float64) error {
+	r := ctx.Value(sharedtest.Variables{Name: rayVariable}).(*Ray)
+	if !shared.CompareFloat(r.Time, expected) {
+		return fmt.Errorf("Error time %g != %g!", r.Time, expected)
+	}
+	return nil
+}
+
 func assertSphereTransform(ctx context.Context, sphereVariable, matrixVariable string) (context.Context, error) {
 	sphere := ctx.Value(sharedtest.Variables{Name: sphereVariable}).(*Sphere)
-	m := matrix.Identity
+	m := matrix.Identity4
 
 	if matrixVariable != "id" {
-		m = ctx.Value(sharedtest.Variables{Name: matrixVariable}).(*matrix.Matrix)
+		m = ctx.Value(sharedtest.Variables{Name: matrixVariable}).(matrix.Mat4)
 	}
 
 	if !sphere.transformation.Equals(m) {
@@ -256,16 +926,16 @@ func assertSphereTransform(ctx context.Context, sphereVariable, matrixVariable s
 }
 
 func assertEqualsVector(ctx context.Context, tupleVariable, xStr, yStr, zStr string) (context.Context, error) {
-	actual := ctx.Value(sharedtest.Variables{Name: tupleVariable}).(*tuple.Tuple)
+	actual := ctx.Value(sharedtest.Variables{Name: tupleVariable}).(tuple.Vector)
 	x, y, z, err := sharedtest.ParseXYZ(xStr, yStr, zStr)
 
 	if err != nil {
 		return ctx, err
 	}
 
-	expected := tuple.Vector(x, y, z)
+	expected := tuple.NewVector(x, y, z)
 
-	if !tuple.CompareTuple(expected, actual) {
+	if !expected.Equals(actual) {
 		return ctx, fmt.Errorf("Error %+v != %+v!", expected, actual)
 	}
 
@@ -286,8 +956,8 @@ func assertIntersectionObject(ctx context.Context, intersectionVariable, objectV
 	intersection := ctx.Value(sharedtest.Variables{Name: intersectionVariable}).(*Intersection)
 	object := ctx.Value(sharedtest.Variables{Name: objectVariable}).(*Sphere)
 
-	if intersection.Object != object.Id {
-		return ctx, fmt.Errorf("Error %d != %d!", intersection.Object, object.Id)
+	if intersection.Object != object {
+		return ctx, fmt.Errorf("Error %p != %p!", intersection.Object, object)
 	}
 
 	return ctx, nil
@@ -331,6 +1001,17 @@ func assertIntersectionEquals(ctx context.Context, i1Variable, i2Variable string
 	return ctx, nil
 }
 
+func assertHitIsEntry(ctx context.Context, variable, intersectionsVariable string, index int) (context.Context, error) {
+	i := ctx.Value(sharedtest.Variables{Name: variable}).(*Intersection)
+	xs := ctx.Value(sharedtest.Variables{Name: intersectionsVariable}).(Intersections)
+
+	if i != &xs[index] {
+		return ctx, fmt.Errorf("Error %p is not entry %d of %s!", i, index, intersectionsVariable)
+	}
+
+	return ctx, nil
+}
+
 func assertIntersectionNothing(ctx context.Context, variable string) (context.Context, error) {
 	i := ctx.Value(sharedtest.Variables{Name: variable}).(*Intersection)
 
@@ -344,10 +1025,10 @@ func assertIntersectionNothing(ctx context.Context, variable string) (context.Co
 func assertRayPosition(ctx context.Context, rayVariable string, t, x, y, z float64) (context.Context, error) {
 	ray := ctx.Value(sharedtest.Variables{Name: rayVariable}).(*Ray)
 
-	expected := tuple.Point(x, y, z)
+	expected := tuple.NewPoint(x, y, z)
 	actual := ray.Position(t)
 
-	if !tuple.CompareTuple(actual, expected) {
+	if !actual.Equals(expected) {
 		return ctx, fmt.Errorf("Error %+v != %+v!", actual, expected)
 	}
 
@@ -361,9 +1042,66 @@ func constructors(ctx *godog.ScenarioContext) {
 	regex := fmt.Sprintf(`^(.+) ← point_light\(%s, %s\)$`, sharedtest.TupleVariableName, sharedtest.TupleVariableName)
 	ctx.Step(regex, aPointLightFromVariables)
 
-	regex = fmt.Sprintf(`^(.+) ← material\(\)$`)
+	regex = fmt.Sprintf(`^(.+) ← point_light\(point\(%s, %s, %s\), color\(%s, %s, %s\)\)$`, sharedtest.Decimal, sharedtest.Decimal, sharedtest.Decimal, sharedtest.Decimal, sharedtest.Decimal, sharedtest.Decimal)
+	ctx.Step(regex, aPointLightFromValues)
+
+	regex = `^(.+) ← material\(\)$`
 	ctx.Step(regex, aMaterial)
 
+	regex = fmt.Sprintf(`^%s ← (stripes|marble|wood|turbulence)\(color\(%s, %s, %s\), color\(%s, %s, %s\)\)$`, sharedtest.TupleVariableName, sharedtest.Decimal, sharedtest.Decimal, sharedtest.Decimal, sharedtest.Decimal, sharedtest.Decimal, sharedtest.Decimal)
+	ctx.Step(regex, aPattern)
+
+	regex = fmt.Sprintf(`^%s ← perturb\(%s, %s\)$`, sharedtest.TupleVariableName, sharedtest.TupleVariableName, sharedtest.Decimal)
+	ctx.Step(regex, aPerturbedPattern)
+
+	regex = fmt.Sprintf(`^%s ← material_at\(%s, point\(%s, %s, %s\)\)$`, sharedtest.TupleVariableName, sharedtest.TupleVariableName, sharedtest.Decimal, sharedtest.Decimal, sharedtest.Decimal)
+	ctx.Step(regex, aMaterialAt)
+
+	regex = fmt.Sprintf(`^%s ← %s.pattern$`, sharedtest.TupleVariableName, sharedtest.TupleVariableName)
+	ctx.Step(regex, aMaterialPattern)
+
+	regex = fmt.Sprintf(`^%s ← metallic_roughness\(%s\)$`, sharedtest.TupleVariableName, sharedtest.TupleVariableName)
+	ctx.Step(regex, aConvertedMaterial)
+
+	regex = `^([a-z_]+) ← (true|false)$`
+	ctx.Step(regex, aBoolean)
+
+	regex = `^([a-z_]+) ← lighting\(([a-z_]+), ([a-z_]+), ([a-z_]+), ([a-z_]+), ([a-z_]+)(?:, ([a-z_]+|[0-9\.]+))?\)$`
+	ctx.Step(regex, aLighting)
+
+	regex = fmt.Sprintf(`^%s ← directional_light\(vector\(%s, %s, %s\), color\(%s, %s, %s\)\)$`, sharedtest.TupleVariableName, sharedtest.Decimal, sharedtest.Decimal, sharedtest.Decimal, sharedtest.Decimal, sharedtest.Decimal, sharedtest.Decimal)
+	ctx.Step(regex, aDirectionalLight)
+
+	regex = fmt.Sprintf(`^%s ← spot_light\(point\(%s, %s, %s\), vector\(%s, %s, %s\), color\(%s, %s, %s\), π/%s, π/%s\)$`, sharedtest.TupleVariableName, sharedtest.Decimal, sharedtest.Decimal, sharedtest.Decimal, sharedtest.Decimal, sharedtest.Decimal, sharedtest.Decimal, sharedtest.Decimal, sharedtest.Decimal, sharedtest.Decimal, sharedtest.PosInt, sharedtest.PosInt)
+	ctx.Step(regex, aSpotLight)
+
+	regex = fmt.Sprintf(`^%s ← direction_to_light\(%s, %s\)$`, sharedtest.TupleVariableName, sharedtest.TupleVariableName, sharedtest.TupleVariableName)
+	ctx.Step(regex, aDirectionToLight)
+
+	regex = fmt.Sprintf(`^%s ← distance_to_light\(%s, %s\)$`, sharedtest.TupleVariableName, sharedtest.TupleVariableName, sharedtest.TupleVariableName)
+	ctx.Step(regex, aDistanceToLight)
+
+	regex = fmt.Sprintf(`^%s ← intensity_at\(%s, %s\)$`, sharedtest.TupleVariableName, sharedtest.TupleVariableName, sharedtest.TupleVariableName)
+	ctx.Step(regex, anIntensityAt)
+
+	regex = fmt.Sprintf(`^%s ← area_light\(%s, %s, %s, %s, %s, color\(%s, %s, %s\)\)$`, sharedtest.TupleVariableName, sharedtest.TupleVariableName, sharedtest.TupleVariableName, sharedtest.PosInt, sharedtest.TupleVariableName, sharedtest.PosInt, sharedtest.Decimal, sharedtest.Decimal, sharedtest.Decimal)
+	ctx.Step(regex, anAreaLight)
+
+	regex = fmt.Sprintf(`^%s ← shape_light\(%s, %s, %s, color\(%s, %s, %s\)\)$`, sharedtest.TupleVariableName, sharedtest.TupleVariableName, sharedtest.PosInt, sharedtest.PosInt, sharedtest.Decimal, sharedtest.Decimal, sharedtest.Decimal)
+	ctx.Step(regex, aShapeLight)
+
+	regex = fmt.Sprintf(`^%s ← sample_surface\(%s, %s, %s\)$`, sharedtest.TupleVariableName, sharedtest.TupleVariableName, sharedtest.Decimal, sharedtest.Decimal)
+	ctx.Step(regex, aSurfaceSample)
+
+	regex = fmt.Sprintf(`^%s ← point_on_light\(%s, %s, %s\)$`, sharedtest.TupleVariableName, sharedtest.TupleVariableName, sharedtest.PosInt, sharedtest.PosInt)
+	ctx.Step(regex, aPointOnLight)
+
+	regex = fmt.Sprintf(`^%s ← normalize\(%s - %s\)$`, sharedtest.TupleVariableName, sharedtest.TupleVariableName, sharedtest.TupleVariableName)
+	ctx.Step(regex, aNormalizedDifference)
+
+	regex = fmt.Sprintf(`^%s ← vector\(%s\.x, [a-z]+\.y, [a-z]+\.z\)$`, sharedtest.TupleVariableName, sharedtest.TupleVariableName)
+	ctx.Step(regex, aVectorFromPoint)
+
 	regex = fmt.Sprintf(`^(.+) ← ray\(%s, %s\)$`, sharedtest.TupleVariableName, sharedtest.TupleVariableName)
 	ctx.Step(regex, aRayFromVariables)
 
@@ -371,6 +1109,7 @@ func constructors(ctx *godog.ScenarioContext) {
 	ctx.Step(regex, aRayFromValues)
 
 	ctx.Step(`^(.+) ← sphere\(\)$`, aSphere)
+	ctx.Step(fmt.Sprintf(`^%s ← %s spheres created concurrently$`, sharedtest.TupleVariableName, sharedtest.PosInt), someConcurrentSpheres)
 
 	regex = fmt.Sprintf(`^(.+) ← intersect\(%s, %s\)$`, sharedtest.TupleVariableName, sharedtest.TupleVariableName)
 	ctx.Step(regex, aIntersect)
@@ -387,16 +1126,22 @@ func constructors(ctx *godog.ScenarioContext) {
 	regex = fmt.Sprintf(`^(.+) ← intersection\(%s, %s\)$`, sharedtest.Decimal, sharedtest.TupleVariableName)
 	ctx.Step(regex, aIntersection)
 
-	regex = fmt.Sprintf(`^(.+) ← intersections\(%s, %s\)$`, sharedtest.TupleVariableName, sharedtest.TupleVariableName)
-	ctx.Step(regex, aIntersections2)
+	regex = fmt.Sprintf(`^%s ← intersections\(([a-z0-9, ]+)\)$`, sharedtest.TupleVariableName)
+	ctx.Step(regex, someIntersections)
+
+	regex = fmt.Sprintf(`^%s ← merge\(([a-z0-9, ]+)\)$`, sharedtest.TupleVariableName)
+	ctx.Step(regex, aMerge)
+
+	regex = fmt.Sprintf(`^%s ← within\(%s, %s, %s\)$`, sharedtest.TupleVariableName, sharedtest.TupleVariableName, sharedtest.Decimal, sharedtest.Decimal)
+	ctx.Step(regex, aWithin)
 
-	regex = fmt.Sprintf(`^(.+) ← intersections\(%s, %s, %s, %s\)$`, sharedtest.TupleVariableName, sharedtest.TupleVariableName, sharedtest.TupleVariableName, sharedtest.TupleVariableName)
-	ctx.Step(regex, aIntersections4)
+	regex = fmt.Sprintf(`^%s ← filter\(%s, object = %s\)$`, sharedtest.TupleVariableName, sharedtest.TupleVariableName, sharedtest.TupleVariableName)
+	ctx.Step(regex, aFilter)
 
 	regex = fmt.Sprintf(`^(.+) ← hit\(%s\)$`, sharedtest.TupleVariableName)
 	ctx.Step(regex, aHit)
 
-	regex = fmt.Sprintf(`^(.+) ← normal_at\(%s, point\(%s, %s, %s\)\)$`, sharedtest.TupleVariableName, sharedtest.Decimal, sharedtest.Decimal, sharedtest.Decimal)
+	regex = fmt.Sprintf(`^(.+) ← normal_at\(%s, point\(%s, %s, %s\)(?:, %s)?\)$`, sharedtest.TupleVariableName, sharedtest.Decimal, sharedtest.Decimal, sharedtest.Decimal, sharedtest.Decimal)
 	ctx.Step(regex, aNormalAt)
 
 	regex = `^(.+) ← rotation_(.)\(π\/(\d+)\)$`
@@ -405,6 +1150,10 @@ func constructors(ctx *godog.ScenarioContext) {
 	regex = fmt.Sprintf(`^(.+) ← %s \* %s$`, sharedtest.TupleVariableName, sharedtest.TupleVariableName)
 	ctx.Step(regex, aMatrixMul)
 
+	// Registered before colours in general so that it is matched first.
+	regex = fmt.Sprintf(`^%s.color ← color\(%s, %s, %s\)$`, sharedtest.TupleVariableName, sharedtest.Decimal, sharedtest.Decimal, sharedtest.Decimal)
+	ctx.Step(regex, setMaterialColor)
+
 	tupletest.AddConstructColor(ctx)
 }
 
@@ -427,24 +1176,105 @@ func assertions(ctx *godog.ScenarioContext) {
 	ctx.Step(regex, assertIntersectionsObject)
 	regex = fmt.Sprintf(`^%s = %s$`, sharedtest.TupleVariableName, sharedtest.TupleVariableName)
 	ctx.Step(regex, assertIntersectionEquals)
+
+	regex = fmt.Sprintf(`^%s = %s\[%s\]$`, sharedtest.TupleVariableName, sharedtest.TupleVariableName, sharedtest.PosInt)
+	ctx.Step(regex, assertHitIsEntry)
 	regex = fmt.Sprintf(`^%s is nothing$`, sharedtest.TupleVariableName)
 	ctx.Step(regex, assertIntersectionNothing)
+	regex = fmt.Sprintf(`^transform_at\(%s, %s\) = %s$`, sharedtest.TupleVariableName, sharedtest.Decimal, sharedtest.TupleVariableName)
+	ctx.Step(regex, assertTransformAt)
+	regex = fmt.Sprintf(`^%s.time = %s$`, sharedtest.TupleVariableName, sharedtest.Decimal)
+	ctx.Step(regex, assertRayTime)
+	regex = fmt.Sprintf(`^set_motion\(%s, %s, %s\) fails with "(.*)"$`, sharedtest.TupleVariableName, sharedtest.TupleVariableName, sharedtest.TupleVariableName)
+	ctx.Step(regex, assertSetMotionFails)
 	regex = fmt.Sprintf(`^%s.transform = %s$`, sharedtest.TupleVariableName, sharedtest.TupleVariableName)
 	ctx.Step(regex, assertSphereTransform)
+	ctx.Step(fmt.Sprintf(`^each of %s is the object of its intersections with %s$`, sharedtest.TupleVariableName, sharedtest.TupleVariableName), assertOwnIntersections)
 
 	regex = fmt.Sprintf(`^%s.(position|intensity) = %s$`, sharedtest.TupleVariableName, sharedtest.TupleVariableName)
 	ctx.Step(regex, assertPointLightComponent)
 
-	regex = fmt.Sprintf(`^%s.(ambient|color|diffuse|shininess|specular) = %s$`, sharedtest.TupleVariableName, sharedtest.TupleVariableName)
+	regex = fmt.Sprintf(`^%s.(ambient|diffuse|shininess|specular|metallic|roughness|reflective) = %s$`, sharedtest.TupleVariableName, sharedtest.Decimal)
 	ctx.Step(regex, assertMaterialComponent)
 
+	regex = fmt.Sprintf(`^%s.model = ([a-z\-]+)$`, sharedtest.TupleVariableName)
+	ctx.Step(regex, assertMaterialModel)
+	ctx.Step(`^parsing the material model "([a-z\-]+)" fails with "(.*)"$`, assertUnknownModel)
+
+	regex = fmt.Sprintf(`^the reflectance of %s along %s around %s is at most 1$`, sharedtest.TupleVariableName, sharedtest.TupleVariableName, sharedtest.TupleVariableName)
+	ctx.Step(regex, assertReflectanceAtMostOne)
+	regex = fmt.Sprintf(`^%s bounces sampled from %s along %s around %s average to its reflectance$`, sharedtest.PosInt, sharedtest.TupleVariableName, sharedtest.TupleVariableName, sharedtest.TupleVariableName)
+	ctx.Step(regex, assertSampledReflectance)
+	regex = fmt.Sprintf(`^%s cosine samples around vector\(%s, %s, %s\) lie in its hemisphere with a mean cosine of %s$`, sharedtest.PosInt, sharedtest.Decimal, sharedtest.Decimal, sharedtest.Decimal, sharedtest.Decimal)
+	ctx.Step(regex, assertCosineSamples)
+
+	regex = fmt.Sprintf(`^%s.casts_shadow = (true|false)$`, sharedtest.TupleVariableName)
+	ctx.Step(regex, assertCastsShadow)
+
+	regex = fmt.Sprintf(`^any_hit\(%s, %s, %s\) is (true|false)$`, sharedtest.TupleVariableName, sharedtest.TupleVariableName, sharedtest.Decimal)
+	ctx.Step(regex, assertAnyHit)
+	regex = fmt.Sprintf(`^pattern_at\(%s, point\(%s, %s, %s\)\) = color\(%s, %s, %s\)$`, sharedtest.TupleVariableName, sharedtest.Decimal, sharedtest.Decimal, sharedtest.Decimal, sharedtest.Decimal, sharedtest.Decimal, sharedtest.Decimal)
+	ctx.Step(regex, assertPatternAt)
+	regex = fmt.Sprintf(`^pattern_at\(%s, point\(%s, %s, %s\)\) = pattern_at\(%s, point\(%s, %s, %s\)\)$`, sharedtest.TupleVariableName, sharedtest.Decimal, sharedtest.Decimal, sharedtest.Decimal, sharedtest.TupleVariableName, sharedtest.Decimal, sharedtest.Decimal, sharedtest.Decimal)
+	ctx.Step(regex, assertSamePatternAt)
+	regex = fmt.Sprintf(`^pattern_at\(%s, point\(%s, %s, %s\)\) ≠ pattern_at\(%s, point\(%s, %s, %s\)\)$`, sharedtest.TupleVariableName, sharedtest.Decimal, sharedtest.Decimal, sharedtest.Decimal, sharedtest.TupleVariableName, sharedtest.Decimal, sharedtest.Decimal, sharedtest.Decimal)
+	ctx.Step(regex, assertDifferentPatternAt)
+
+	regex = fmt.Sprintf(`^%s.color = color\(%s, %s, %s\)$`, sharedtest.TupleVariableName, sharedtest.Decimal, sharedtest.Decimal, sharedtest.Decimal)
+	ctx.Step(regex, assertMaterialColor)
+
+	regex = fmt.Sprintf(`^%s.(point|normal) = (?:point|vector)\(%s, %s, %s\)$`, sharedtest.TupleVariableName, sharedtest.Decimal, sharedtest.Decimal, sharedtest.Decimal)
+	ctx.Step(regex, assertSurfaceSample)
+	regex = fmt.Sprintf(`^%s.area = %s$`, sharedtest.TupleVariableName, sharedtest.Decimal)
+	ctx.Step(regex, assertSurfaceSampleArea)
+	regex = fmt.Sprintf(`^the samples of %s cover an area of %s within %s$`, sharedtest.TupleVariableName, sharedtest.Decimal, sharedtest.Decimal)
+	ctx.Step(regex, assertSampledArea)
+	regex = fmt.Sprintf(`^the samples of %s shine on point\(%s, %s, %s\) facing vector\(%s, %s, %s\) with a mean intensity of %s within %s$`, sharedtest.TupleVariableName, sharedtest.Decimal, sharedtest.Decimal, sharedtest.Decimal, sharedtest.Decimal, sharedtest.Decimal, sharedtest.Decimal, sharedtest.Decimal, sharedtest.Decimal)
+	ctx.Step(regex, assertMeanSampleIntensity)
+
+	regex = fmt.Sprintf(`^%s.(corner|uvec|vvec|position) = (point|vector)\(%s, %s, %s\)$`, sharedtest.TupleVariableName, sharedtest.Decimal, sharedtest.Decimal, sharedtest.Decimal)
+	ctx.Step(regex, assertAreaLightTuple)
+	regex = fmt.Sprintf(`^%s.(usteps|vsteps|samples) = %s$`, sharedtest.TupleVariableName, sharedtest.PosInt)
+	ctx.Step(regex, assertAreaLightSteps)
+
+	regex = fmt.Sprintf(`^%s = %s$`, sharedtest.TupleVariableName, sharedtest.Decimal)
+	ctx.Step(regex, assertDistance)
+	regex = fmt.Sprintf(`^%s is infinite$`, sharedtest.TupleVariableName)
+	ctx.Step(regex, assertInfinite)
+
 	tupletest.AddCompareNormalize(ctx)
 	tupletest.AddCompareVector(ctx)
+	tupletest.AddComparePoint(ctx)
+	tupletest.AddCompareColor(ctx)
 }
 
 func setters(ctx *godog.ScenarioContext) {
 	regex := fmt.Sprintf(`^set_transform\(%s, %s\)$`, sharedtest.TupleVariableName, sharedtest.TupleVariableName)
 	ctx.Step(regex, setTransform)
+
+	regex = fmt.Sprintf(`^set_motion\(%s, %s, %s\)$`, sharedtest.TupleVariableName, sharedtest.TupleVariableName, sharedtest.TupleVariableName)
+	ctx.Step(regex, setMotion)
+
+	regex = fmt.Sprintf(`^%s.time ← %s$`, sharedtest.TupleVariableName, sharedtest.Decimal)
+	ctx.Step(regex, setRayTime)
+
+	regex = fmt.Sprintf(`^%s.jitter_by ← sequence\(([0-9\., ]+)\)$`, sharedtest.TupleVariableName)
+	ctx.Step(regex, setJitter)
+
+	regex = fmt.Sprintf(`^%s.(ambient|diffuse|shininess|specular|metallic|roughness|reflective) ← %s$`, sharedtest.TupleVariableName, sharedtest.Decimal)
+	ctx.Step(regex, setMaterialComponent)
+
+	regex = fmt.Sprintf(`^%s.model ← ([a-z\-]+)$`, sharedtest.TupleVariableName)
+	ctx.Step(regex, setMaterialModel)
+
+	regex = fmt.Sprintf(`^%s.(scale|turbulence|rings|octaves) ← %s$`, sharedtest.TupleVariableName, sharedtest.Decimal)
+	ctx.Step(regex, setPatternComponent)
+
+	regex = fmt.Sprintf(`^%s.material.pattern ← %s$`, sharedtest.TupleVariableName, sharedtest.TupleVariableName)
+	ctx.Step(regex, setSpherePattern)
+
+	regex = fmt.Sprintf(`^%s.pattern ← %s$`, sharedtest.TupleVariableName, sharedtest.TupleVariableName)
+	ctx.Step(regex, setMaterialPattern)
 }
 
 func initializeScenario(ctx *godog.ScenarioContext) {
@@ -458,7 +1288,7 @@ func TestFeatures(t *testing.T) {
 		ScenarioInitializer: initializeScenario,
 		Options: &godog.Options{
 			Format:   "pretty",
-			Paths:    []string{"features/rays.feature", "features/spheres.feature", "features/intersections.feature", "features/lights.feature", "features/materials.feature"},
+			Paths:    []string{"features/rays.feature", "features/spheres.feature", "features/intersections.feature", "features/lights.feature", "features/materials.feature", "features/patterns.feature"},
 			TestingT: t,
 		},
 	}
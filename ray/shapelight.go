@@ -0,0 +1,130 @@
+package ray
+
+import (
+	"math"
+	"math/rand"
+	"rtt/tuple"
+)
+
+// Emitter is a shape that can be made into a light by sampling points on
+// its surface.
+type Emitter interface {
+	// SampleSurface turns u and v in [0, 1) into a point on the surface and
+	// the normal there, along with the area of surface the point stands
+	// for: the reciprocal of the density of points around it.
+	SampleSurface(u, v float64) (tuple.Point, tuple.Vector, float64)
+	// Centre is the middle of the surface, for when a light made from the
+	// shape is treated as a whole rather than by its samples.
+	Centre() tuple.Point
+}
+
+// ShapeLight lights the world from the surface of a shape that glows with
+// Radiance. Like an AreaLight it is shaded with one sample from each cell
+// of a USteps by VSteps grid, here laid over the shape's surface.
+//
+// JitterBy, if set, places the samples within their cells in place of the
+// rng they are sampled with.
+//
+// A shape does not shadow its own light. Only the side of it that faces a
+// point lights it, so this only loses shadows that a shape that is not
+// convex casts on itself.
+type ShapeLight struct {
+	Shape    Emitter
+	USteps   int
+	VSteps   int
+	Samples  int
+	Radiance tuple.Color
+	JitterBy func() float64
+}
+
+func NewShapeLight(shape Emitter, radiance tuple.Color, usteps, vsteps int) *ShapeLight {
+	return &ShapeLight{
+		Shape:    shape,
+		USteps:   usteps,
+		VSteps:   vsteps,
+		Samples:  usteps * vsteps,
+		Radiance: radiance,
+	}
+}
+
+func (l *ShapeLight) DirectionFrom(point tuple.Point) tuple.Vector {
+	return l.Shape.Centre().Subtract(point).Normalize()
+}
+
+func (l *ShapeLight) DistanceFrom(point tuple.Point) float64 {
+	return l.Shape.Centre().Subtract(point).Magnitude()
+}
+
+func (l *ShapeLight) IntensityAt(point tuple.Point) tuple.Color {
+	return l.Radiance
+}
+
+func (l *ShapeLight) Sample(rng *rand.Rand) []Light {
+	samples := make([]Light, 0, l.Samples)
+
+	for v := 0; v < l.VSteps; v++ {
+		for u := 0; u < l.USteps; u++ {
+			point, normal, area := l.Shape.SampleSurface(
+				(float64(u)+Jitter(l.JitterBy, rng))/float64(l.USteps),
+				(float64(v)+Jitter(l.JitterBy, rng))/float64(l.VSteps))
+
+			samples = append(samples, &surfaceLight{
+				Position: point,
+				Normal:   normal,
+				Radiance: l.Radiance,
+				Area:     area,
+				Emitter:  l.Shape,
+			})
+		}
+	}
+
+	return samples
+}
+
+// EmitterOf returns the shape a light was made from, or nil for lights that
+// were not made from a shape.
+func EmitterOf(light Light) Emitter {
+	switch l := light.(type) {
+	case *ShapeLight:
+		return l.Shape
+	case *surfaceLight:
+		return l.Emitter
+	}
+	return nil
+}
+
+// surfaceLight is a patch of a ShapeLight's surface. Its intensity is the
+// irradiance it gives a surface facing it over π, like that of the other
+// lights, and falls off with the square of the distance and with how far
+// the patch is turned away from the point.
+type surfaceLight struct {
+	Position tuple.Point
+	Normal   tuple.Vector
+	Radiance tuple.Color
+	Area     float64
+	Emitter  Emitter
+}
+
+func (l *surfaceLight) DirectionFrom(point tuple.Point) tuple.Vector {
+	return l.Position.Subtract(point).Normalize()
+}
+
+func (l *surfaceLight) DistanceFrom(point tuple.Point) float64 {
+	return l.Position.Subtract(point).Magnitude()
+}
+
+func (l *surfaceLight) IntensityAt(point tuple.Point) tuple.Color {
+	toPoint := point.Subtract(l.Position)
+	distanceSquared := toPoint.Dot(toPoint)
+
+	cosine := toPoint.Dot(l.Normal) / math.Sqrt(distanceSquared)
+	if cosine <= 0 {
+		return tuple.Black
+	}
+
+	return l.Radiance.ScalarMultiply(cosine * l.Area / (math.Pi * distanceSquared))
+}
+
+func (l *surfaceLight) Sample(rng *rand.Rand) []Light {
+	return []Light{l}
+}
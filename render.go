@@ -0,0 +1,159 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"rtt/camera"
+	"rtt/scene"
+	"rtt/shared"
+	"rtt/world"
+	"runtime"
+
+	"github.com/spf13/pflag"
+)
+
+func renderCommand(args []string, stdout, stderr io.Writer) int {
+	flags := pflag.NewFlagSet("render", pflag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: rtt render <scene.yaml> -o <output.png|output.ppm> [flags]")
+		flags.PrintDefaults()
+	}
+
+	output := flags.StringP("output", "o", "", "image file to write (.png or .ppm)")
+	options := addRenderFlags(flags)
+
+	if err := flags.Parse(args); err != nil {
+		if err == pflag.ErrHelp {
+			return exitOK
+		}
+		return exitUsage
+	}
+
+	if flags.NArg() != 1 {
+		fmt.Fprintln(stderr, "rtt render: expected exactly one scene file")
+		flags.Usage()
+		return exitUsage
+	}
+
+	if *output == "" {
+		fmt.Fprintln(stderr, "rtt render: an output file must be given with -o")
+		flags.Usage()
+		return exitUsage
+	}
+
+	if _, err := imageFormat(*output); err != nil {
+		fmt.Fprintf(stderr, "rtt render: %s\n", err)
+		return exitUsage
+	}
+
+	if err := options.validate(); err != nil {
+		fmt.Fprintf(stderr, "rtt render: %s\n", err)
+		return exitUsage
+	}
+
+	s, err := scene.LoadFile(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintf(stderr, "rtt render: %s\n", err)
+		return exitFailure
+	}
+
+	c, err := options.camera(s)
+	if err != nil {
+		fmt.Fprintf(stderr, "rtt render: %s\n", err)
+		return exitUsage
+	}
+
+	image := c.RenderWithWorkers(s.World, options.workers)
+
+	if err := writeImage(*output, image); err != nil {
+		fmt.Fprintf(stderr, "rtt render: %s\n", err)
+		return exitFailure
+	}
+
+	return exitOK
+}
+
+// renderOptions holds the flags shared by every command that renders a scene
+// file.
+type renderOptions struct {
+	flags      *pflag.FlagSet
+	width      int32
+	height     int32
+	workers    int
+	depth      int
+	offset     float64
+	samples    int
+	sampling   string
+	seed       int64
+	integrator string
+}
+
+func addRenderFlags(flags *pflag.FlagSet) *renderOptions {
+	o := &renderOptions{flags: flags}
+
+	flags.Int32Var(&o.width, "width", 0, "image width in pixels (default from the scene camera)")
+	flags.Int32Var(&o.height, "height", 0, "image height in pixels (default from the scene camera)")
+	flags.IntVar(&o.workers, "workers", runtime.NumCPU(), "number of rows rendered concurrently")
+	flags.IntVar(&o.depth, "depth", world.DefaultMaxDepth, "maximum recursion depth for reflected rays")
+	flags.Float64Var(&o.offset, "surface-offset", shared.DefaultSurfaceOffset, "distance secondary rays start from a surface, to avoid shadow acne (default from the scene settings)")
+	flags.IntVar(&o.samples, "samples", 1, "samples per pixel (default from the scene camera)")
+	flags.StringVar(&o.sampling, "sampling", "grid", "where samples are taken in a pixel: grid, jittered or stratified (default from the scene camera)")
+	flags.Int64Var(&o.seed, "seed", 0, "seed for the random sampling patterns")
+	flags.StringVar(&o.integrator, "integrator", "whitted", "how light is gathered: whitted, or path for path tracing")
+
+	return o
+}
+
+func (o *renderOptions) validate() error {
+	if o.width < 0 || o.height < 0 || o.workers < 1 || o.depth < 0 || o.offset < 0 {
+		return errors.New("width, height, depth and surface offset must not be negative and workers must be at least 1")
+	}
+	return nil
+}
+
+// camera returns the scene camera with the flags applied, and sets the
+// recursion depth and integrator of the scene world, and its surface offset
+// if the flag is given.
+func (o *renderOptions) camera(s *scene.Scene) (*camera.Camera, error) {
+	c := s.Camera
+	if o.width != 0 || o.height != 0 {
+		w, h := c.HSize, c.VSize
+		if o.width != 0 {
+			w = o.width
+		}
+		if o.height != 0 {
+			h = o.height
+		}
+		c = c.Resize(w, h)
+	}
+
+	pattern, count := c.Sampling()
+	if o.flags.Changed("sampling") {
+		var err error
+		if pattern, err = camera.ParseSampling(o.sampling); err != nil {
+			return nil, err
+		}
+	}
+	if o.flags.Changed("samples") {
+		count = o.samples
+	}
+	if err := c.SetSampling(pattern, count); err != nil {
+		return nil, err
+	}
+	c.Seed = o.seed
+
+	integrator, err := world.ParseIntegrator(o.integrator)
+	if err != nil {
+		return nil, err
+	}
+
+	s.World.MaxDepth = o.depth
+	if o.flags.Changed("surface-offset") {
+		s.World.SurfaceOffset = o.offset
+	}
+	s.World.Integrator = integrator
+
+	return c, nil
+}
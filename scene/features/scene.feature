@@ -0,0 +1,942 @@
+Feature: Scene files
+
+Scenario: Parsing a camera
+  Given source ← scene file:
+    """
+    - add: camera
+      width: 100
+      height: 50
+      field-of-view: 0.785
+      from: [0, 0, -5]
+      to: [0, 0, 0]
+      up: [0, 1, 0]
+    """
+  When s ← parse_scene(source)
+  Then s.camera.hsize = 100
+    And s.camera.vsize = 50
+    And s.camera.field_of_view = 0.785
+    And s.camera.transform = view_transform(point(0, 0, -5), point(0, 0, 0), vector(0, 1, 0))
+
+Scenario: Parsing a camera with supersampling
+  Given source ← scene file:
+    """
+    - add: camera
+      width: 100
+      height: 50
+      field-of-view: 0.785
+      from: [0, 0, -5]
+      to: [0, 0, 0]
+      up: [0, 1, 0]
+      sampling: stratified
+      samples: 4
+    """
+  When s ← parse_scene(source)
+  Then s.camera.sampling = stratified
+    And s.camera.samples = 4
+
+Scenario: Parsing lights
+  Given source ← scene file:
+    """
+    - add: camera
+      width: 10
+      height: 10
+      field-of-view: 1
+      from: [0, 0, -5]
+      to: [0, 0, 0]
+      up: [0, 1, 0]
+    - add: light
+      at: [-10, 10, -10]
+      intensity: [1, 0.5, 0.25]
+    - add: light
+      at: [10, 10, -10]
+      intensity: [0.2, 0.2, 0.2]
+    """
+  When s ← parse_scene(source)
+  Then s.lights.count = 2
+    And s.lights[0] is a point light
+    And s.lights[0].position = point(-10, 10, -10)
+    And s.lights[0].intensity = color(1, 0.5, 0.25)
+    And s.lights[1].position = point(10, 10, -10)
+
+Scenario: Parsing an area light
+  Given source ← scene file:
+    """
+    - add: camera
+      width: 10
+      height: 10
+      field-of-view: 1
+      from: [0, 0, -5]
+      to: [0, 0, 0]
+      up: [0, 1, 0]
+    - add: light
+      corner: [-1, 2, 4]
+      uvec: [2, 0, 0]
+      usteps: 4
+      vvec: [0, 2, 0]
+      vsteps: 2
+      jitter: false
+      intensity: [1.5, 1.5, 1.5]
+    """
+  When s ← parse_scene(source)
+  Then s.lights.count = 1
+    And s.lights[0] is an area light
+    And s.lights[0].corner = point(-1, 2, 4)
+    And s.lights[0].uvec = vector(0.5, 0, 0)
+    And s.lights[0].vvec = vector(0, 1, 0)
+    And s.lights[0].samples = 8
+    And s.lights[0].intensity = color(1.5, 1.5, 1.5)
+
+Scenario: Parsing directional and spot lights
+  Given source ← scene file:
+    """
+    - add: camera
+      width: 10
+      height: 10
+      field-of-view: 1
+      from: [0, 0, -5]
+      to: [0, 0, 0]
+      up: [0, 1, 0]
+    - add: light
+      direction: [0, -2, 0]
+      intensity: [1, 1, 0.9]
+    - add: light
+      at: [0, 10, 0]
+      direction: [0, -1, 0]
+      angle: 0.5
+      falloff: 0.1
+      intensity: [1, 1, 1]
+    """
+  When s ← parse_scene(source)
+  Then s.lights.count = 2
+    And s.lights[0] is a directional light
+    And s.lights[0].direction = vector(0, -1, 0)
+    And s.lights[0].intensity = color(1, 1, 0.9)
+    And s.lights[1] is a spot light
+    And s.lights[1].position = point(0, 10, 0)
+    And s.lights[1].direction = vector(0, -1, 0)
+    And s.lights[1].angle = 0.5
+    And s.lights[1].falloff = 0.1
+
+Scenario: Parsing a sphere with an inline material and transform
+  Given source ← scene file:
+    """
+    - add: camera
+      width: 10
+      height: 10
+      field-of-view: 1
+      from: [0, 0, -5]
+      to: [0, 0, 0]
+      up: [0, 1, 0]
+    - add: sphere
+      material:
+        color: [1, 0, 0]
+        ambient: 0.2
+        diffuse: 0.7
+        specular: 0.3
+        shininess: 50
+        reflective: 0.5
+      transform:
+        - [scale, 2, 2, 2]
+        - [translate, 1, 0, 0]
+    """
+    And S ← scaling(2, 2, 2)
+    And T ← translation(1, 0, 0)
+  When s ← parse_scene(source)
+  Then s.objects.count = 1
+    And s.objects[0].material.color = color(1, 0, 0)
+    And s.objects[0].material.ambient = 0.2
+    And s.objects[0].material.diffuse = 0.7
+    And s.objects[0].material.specular = 0.3
+    And s.objects[0].material.shininess = 50
+    And s.objects[0].material.reflective = 0.5
+    And s.objects[0].transform = T * S
+
+Scenario: Transform operations map onto the transformations package
+  Given source ← scene file:
+    """
+    - add: camera
+      width: 10
+      height: 10
+      field-of-view: 1
+      from: [0, 0, -5]
+      to: [0, 0, 0]
+      up: [0, 1, 0]
+    - add: sphere
+      transform:
+        - [rotate-x, 0.5]
+        - [rotate-y, 0.25]
+        - [rotate-z, 0.125]
+        - [shear, 1, 0, 0, 0, 0, 0]
+    """
+    And A ← rotation_x(0.5)
+    And B ← rotation_y(0.25)
+    And C ← rotation_z(0.125)
+    And D ← shearing(1, 0, 0, 0, 0, 0)
+  When s ← parse_scene(source)
+  Then s.objects[0].transform = D * C * B * A
+
+Scenario: Definitions can be reused and extended
+  Given source ← scene file:
+    """
+    - define: white-material
+      value:
+        color: [1, 1, 1]
+        diffuse: 0.7
+        ambient: 0.1
+    - define: blue-material
+      extend: white-material
+      value:
+        color: [0.537, 0.831, 0.914]
+    - define: standard-transform
+      value:
+        - [translate, 1, -1, 1]
+        - [scale, 0.5, 0.5, 0.5]
+    - add: camera
+      width: 10
+      height: 10
+      field-of-view: 1
+      from: [0, 0, -5]
+      to: [0, 0, 0]
+      up: [0, 1, 0]
+    - add: sphere
+      material: blue-material
+      transform:
+        - standard-transform
+        - [translate, 4, 0, 0]
+    """
+    And A ← translation(1, -1, 1)
+    And B ← scaling(0.5, 0.5, 0.5)
+    And C ← translation(4, 0, 0)
+  When s ← parse_scene(source)
+  Then s.objects[0].material.color = color(0.537, 0.831, 0.914)
+    And s.objects[0].material.diffuse = 0.7
+    And s.objects[0].transform = C * B * A
+
+Scenario: A scene must have a camera
+  Given source ← scene file:
+    """
+    - add: sphere
+    """
+  When s ← parse_scene(source)
+  Then s fails with "line 1: scene has no camera"
+
+Scenario: Unknown object types are reported with their line
+  Given source ← scene file:
+    """
+    - add: camera
+      width: 10
+      height: 10
+      field-of-view: 1
+      from: [0, 0, -5]
+      to: [0, 0, 0]
+      up: [0, 1, 0]
+    - add: cone
+    """
+  When s ← parse_scene(source)
+  Then s fails with "line 8: unknown object type \"cone\""
+
+Scenario: Unknown attributes are reported with their line
+  Given source ← scene file:
+    """
+    - add: sphere
+      material:
+        colour: [1, 0, 0]
+    """
+  When s ← parse_scene(source)
+  Then s fails with "line 3: unknown material attribute \"colour\""
+
+Scenario: Parsing a material with a noise pattern
+  Given source ← scene file:
+    """
+    - add: camera
+      width: 10
+      height: 10
+      field-of-view: 1
+      from: [0, 0, -5]
+      to: [0, 0, 0]
+      up: [0, 1, 0]
+    - add: sphere
+      material:
+        pattern:
+          type: marble
+          colors: [[1, 1, 1], [0, 0, 0]]
+          scale: 2
+          turbulence: 0
+    - add: sphere
+      material:
+        pattern:
+          type: wood
+          colors: [[1, 1, 1], [0, 0, 0]]
+          rings: 2
+          turbulence: 0
+          octaves: 2
+          perturb:
+            amount: 0.25
+            scale: 3
+    """
+  When s ← parse_scene(source)
+  Then s.objects[0].material.pattern_at(point(0.25, 0, 0)) = color(0, 0, 0)
+    And s.objects[0].material.pattern_at(point(-0.25, 0, 0)) = color(1, 1, 1)
+    And s.objects[1].material.pattern is perturbed by 0.25 at scale 3
+
+Scenario: Any pattern can be perturbed
+  Given source ← scene file:
+    """
+    - add: camera
+      width: 10
+      height: 10
+      field-of-view: 1
+      from: [0, 0, -5]
+      to: [0, 0, 0]
+      up: [0, 1, 0]
+    - add: sphere
+      material:
+        pattern:
+          type: stripes
+          colors: [[1, 1, 1], [0, 0, 0]]
+          perturb:
+            amount: 0.25
+            scale: 2
+    """
+  When s ← parse_scene(source)
+  Then s.objects[0].material.pattern is perturbed by 0.25 at scale 2
+    And s.objects[0].material.pattern_at(point(0.5, 0, 0)) = color(1, 1, 1)
+    And s.objects[0].material.pattern_at(point(1.5, 0, 0)) = color(0, 0, 0)
+
+Scenario: An unknown pattern is reported with its line
+  Given source ← scene file:
+    """
+    - add: sphere
+      material:
+        pattern:
+          type: checkers
+          colors: [[1, 1, 1], [0, 0, 0]]
+    """
+  When s ← parse_scene(source)
+  Then s fails with "line 4: unknown pattern \"checkers\", expected stripes, marble, wood or turbulence"
+
+Scenario: A pattern attribute of another type is reported with its line
+  Given source ← scene file:
+    """
+    - add: sphere
+      material:
+        pattern:
+          type: turbulence
+          colors: [[1, 1, 1], [0, 0, 0]]
+          rings: 3
+    """
+  When s ← parse_scene(source)
+  Then s fails with "line 6: unknown attribute \"rings\""
+
+Scenario: Malformed transforms are reported with their line
+  Given source ← scene file:
+    """
+    - add: sphere
+      transform:
+        - [translate, 1, 2]
+    """
+  When s ← parse_scene(source)
+  Then s fails with "line 3: translate expects 3 arguments, found 2"
+
+Scenario: Transforms that cannot be inverted are reported with their line
+  Given source ← scene file:
+    """
+    - add: sphere
+      transform:
+        - [scale, 1e-300, 1, 1]
+    """
+  When s ← parse_scene(source)
+  Then s fails with "line 3: invalid transform: input matrix not invertible"
+
+Scenario Outline: Invalid spot lights are reported with their line
+  Given source ← scene file:
+    """
+    - add: light
+      at: [0, 10, 0]
+      direction: [0, -1, 0]
+      angle: <angle>
+      falloff: <falloff>
+      intensity: [1, 1, 1]
+    """
+  When s ← parse_scene(source)
+  Then s fails with "<error>"
+
+  Examples:
+    | angle | falloff | error                                                        |
+    | 0     | 1       | line 4: spot light angle must be between 0 and π, found 0    |
+    | -0.5  | 1       | line 4: spot light angle must be between 0 and π, found -0.5 |
+    | 3.2   | 1       | line 4: spot light angle must be between 0 and π, found 3.2  |
+    | 0.5   | -1      | line 5: spot light falloff must not be negative, found -1    |
+
+Scenario: Unknown definitions are reported with their line
+  Given source ← scene file:
+    """
+    - add: sphere
+      material: missing-material
+    """
+  When s ← parse_scene(source)
+  Then s fails with "line 2: unknown definition \"missing-material\""
+
+Scenario Outline: Definitions that refer to themselves are reported with their line
+  Given source ← scene file:
+    """
+    - define: outer
+      value:
+        - inner
+    - define: inner
+      value:
+        - <reference>
+    - add: sphere
+      transform:
+        - outer
+    """
+  When s ← parse_scene(source)
+  Then s fails with "<error>"
+
+  Examples:
+    | reference | error                                     |
+    | inner     | line 6: define \"inner\" refers to itself |
+    | outer     | line 6: define \"outer\" refers to itself |
+
+Scenario: Missing camera attributes are reported
+  Given source ← scene file:
+    """
+    - add: camera
+      width: 10
+      height: ten
+    """
+  When s ← parse_scene(source)
+  Then s fails with "line 1: missing required attribute \"field-of-view\""
+
+Scenario: Non-numeric values are reported with their line
+  Given source ← scene file:
+    """
+    - add: light
+      at: [0, zero, 0]
+      intensity: [1, 1, 1]
+    """
+  When s ← parse_scene(source)
+  Then s fails with "line 2: expected a number, found \"zero\""
+
+Scenario: Parsing a camera with a lens
+  Given source ← scene file:
+    """
+    - add: camera
+      width: 100
+      height: 50
+      field-of-view: 0.785
+      from: [0, 0, -5]
+      to: [0, 0, 0]
+      up: [0, 1, 0]
+      aperture: 0.25
+      focal-distance: 5
+    """
+    And pinhole ← scene file:
+    """
+    - add: camera
+      width: 100
+      height: 50
+      field-of-view: 0.785
+      from: [0, 0, -5]
+      to: [0, 0, 0]
+      up: [0, 1, 0]
+    """
+  When s ← parse_scene(source)
+    And t ← parse_scene(pinhole)
+  Then s.camera.aperture = 0.25
+    And s.camera.focal_distance = 5
+    And t.camera.aperture = 0
+    And t.camera.focal_distance = 1
+
+Scenario: An invalid lens is reported with its line
+  Given source ← scene file:
+    """
+    - add: camera
+      width: 100
+      height: 50
+      field-of-view: 0.785
+      from: [0, 0, -5]
+      to: [0, 0, 0]
+      up: [0, 1, 0]
+      aperture: 0.25
+      focal-distance: 0
+    """
+  When s ← parse_scene(source)
+  Then s fails with "line 1: focal distance must be positive, found 0"
+
+Scenario: Parsing a camera with a shutter
+  Given source ← scene file:
+    """
+    - add: camera
+      width: 100
+      height: 50
+      field-of-view: 0.785
+      from: [0, 0, -5]
+      to: [0, 0, 0]
+      up: [0, 1, 0]
+      shutter: [0, 0.5]
+    """
+  When s ← parse_scene(source)
+  Then s.camera.shutter_open = 0
+    And s.camera.shutter_close = 0.5
+
+Scenario: An invalid shutter is reported with its line
+  Given source ← scene file:
+    """
+    - add: camera
+      width: 100
+      height: 50
+      field-of-view: 0.785
+      from: [0, 0, -5]
+      to: [0, 0, 0]
+      up: [0, 1, 0]
+      shutter: [0.5, 0.25]
+    """
+  When s ← parse_scene(source)
+  Then s fails with "line 8: the shutter must open and then close between 0 and 1, found 0.5 to 0.25"
+
+Scenario: Invalid camera sampling is reported with its line
+  Given source ← scene file:
+    """
+    - add: camera
+      width: 100
+      height: 50
+      field-of-view: 0.785
+      from: [0, 0, -5]
+      to: [0, 0, 0]
+      up: [0, 1, 0]
+      samples: 3
+    """
+  When s ← parse_scene(source)
+  Then s fails with "line 1: grid sampling needs a square number of samples per pixel, found 3"
+
+Scenario: Animating a sphere with keyframes
+  Given source ← scene file:
+    """
+    - add: camera
+      width: 10
+      height: 10
+      field-of-view: 1
+      from: [0, 0, -5]
+      to: [0, 0, 0]
+      up: [0, 1, 0]
+    - add: sphere
+      keyframes:
+        - frame: 0
+          transform:
+            - [translate, 0, 0, 0]
+        - frame: 10
+          transform:
+            - [translate, 4, 0, 0]
+    """
+    And A ← translation(0, 0, 0)
+    And B ← translation(1, 0, 0)
+    And C ← translation(4, 0, 0)
+  When s ← parse_scene(source)
+  Then s.objects[0].transform = A
+  When s is at frame 2.5
+  Then s.objects[0].transform = B
+  When s is at frame 12
+  Then s.objects[0].transform = C
+
+Scenario: Animating the camera with keyframes
+  Given source ← scene file:
+    """
+    - add: camera
+      width: 10
+      height: 10
+      field-of-view: 1
+      from: [0, 0, -5]
+      to: [0, 0, 0]
+      up: [0, 1, 0]
+      keyframes:
+        - frame: 0
+          from: [0, 0, -5]
+          to: [0, 0, 0]
+          up: [0, 1, 0]
+        - frame: 24
+          from: [5, 0, 0]
+          to: [0, 0, 0]
+          up: [0, 1, 0]
+    """
+  When s ← parse_scene(source)
+  Then s.camera.transform = view_transform(point(0, 0, -5), point(0, 0, 0), vector(0, 1, 0))
+  When s is at frame 12
+  Then s.camera.transform = view_transform(point(3.53553, 0, -3.53553), point(0, 0, 0), vector(0, 1, 0))
+  When s is at frame 24
+  Then s.camera.transform = view_transform(point(5, 0, 0), point(0, 0, 0), vector(0, 1, 0))
+
+Scenario: An object cannot have both a transform and keyframes
+  Given source ← scene file:
+    """
+    - add: camera
+      width: 10
+      height: 10
+      field-of-view: 1
+      from: [0, 0, -5]
+      to: [0, 0, 0]
+      up: [0, 1, 0]
+    - add: sphere
+      transform:
+        - [scale, 2, 2, 2]
+      keyframes:
+        - frame: 0
+          transform:
+            - [translate, 1, 0, 0]
+    """
+  When s ← parse_scene(source)
+  Then s fails with "line 12: an object cannot have both a transform and keyframes"
+
+Scenario: Keyframes must be at different frames
+  Given source ← scene file:
+    """
+    - add: camera
+      width: 10
+      height: 10
+      field-of-view: 1
+      from: [0, 0, -5]
+      to: [0, 0, 0]
+      up: [0, 1, 0]
+    - add: sphere
+      keyframes:
+        - frame: 3
+          transform:
+            - [translate, 1, 0, 0]
+        - frame: 3
+          transform:
+            - [translate, 2, 0, 0]
+    """
+  When s ← parse_scene(source)
+  Then s fails with "line 10: two keyframes at frame 3"
+
+Scenario: Materials can be told not to cast shadows
+  Given source ← scene file:
+    """
+    - add: camera
+      width: 10
+      height: 10
+      field-of-view: 1
+      from: [0, 0, -5]
+      to: [0, 0, 0]
+      up: [0, 1, 0]
+    - add: sphere
+    - add: sphere
+      material:
+        casts-shadow: false
+    """
+  When s ← parse_scene(source)
+  Then s.objects[0].material.casts_shadow = true
+    And s.objects[1].material.casts_shadow = false
+
+Scenario: Materials can emit light
+  Given source ← scene file:
+    """
+    - add: camera
+      width: 10
+      height: 10
+      field-of-view: 1
+      from: [0, 0, -5]
+      to: [0, 0, 0]
+      up: [0, 1, 0]
+    - add: sphere
+    - add: sphere
+      material:
+        emission: [4, 3.5, 3]
+    """
+  When s ← parse_scene(source)
+  Then s.objects[0].material.emission = color(0, 0, 0)
+    And s.objects[1].material.emission = color(4, 3.5, 3)
+
+Scenario: Materials can use the metallic-roughness model
+  Given source ← scene file:
+    """
+    - add: camera
+      width: 10
+      height: 10
+      field-of-view: 1
+      from: [0, 0, -5]
+      to: [0, 0, 0]
+      up: [0, 1, 0]
+    - add: sphere
+      material:
+        model: metallic-roughness
+        color: [1, 0.8, 0.3]
+        metallic: 1
+        roughness: 0.25
+    """
+  When s ← parse_scene(source)
+  Then s.objects[0].material.model = metallic-roughness
+    And s.objects[0].material.color = color(1, 0.8, 0.3)
+    And s.objects[0].material.metallic = 1
+    And s.objects[0].material.roughness = 0.25
+
+Scenario: Phong materials switched to metallic-roughness keep their look
+  Given source ← scene file:
+    """
+    - add: camera
+      width: 10
+      height: 10
+      field-of-view: 1
+      from: [0, 0, -5]
+      to: [0, 0, 0]
+      up: [0, 1, 0]
+    - define: red-plastic
+      value:
+        color: [1, 0, 0]
+        diffuse: 0.5
+        shininess: 10
+    - define: red-metallic-roughness
+      extend: red-plastic
+      value:
+        model: metallic-roughness
+    - add: sphere
+      material: red-metallic-roughness
+    """
+  When s ← parse_scene(source)
+  Then s.objects[0].material.model = metallic-roughness
+    And s.objects[0].material.color = color(0.5, 0, 0)
+    And s.objects[0].material.metallic = 0
+    And s.objects[0].material.roughness = 0.46714
+
+Scenario: Parsing an unknown material model
+  Given source ← scene file:
+    """
+    - add: camera
+      width: 10
+      height: 10
+      field-of-view: 1
+      from: [0, 0, -5]
+      to: [0, 0, 0]
+      up: [0, 1, 0]
+    - add: sphere
+      material:
+        model: toon
+    """
+  When s ← parse_scene(source)
+  Then s fails with "line 10: unknown material model \"toon\", expected phong or metallic-roughness"
+
+Scenario: Glowing spheres can light the scene
+  Given source ← scene file:
+    """
+    - add: camera
+      width: 10
+      height: 10
+      field-of-view: 1
+      from: [0, 0, -5]
+      to: [0, 0, 0]
+      up: [0, 1, 0]
+    - add: sphere
+    - add: sphere
+      material:
+        emission: [4, 4, 4]
+      light:
+        usteps: 4
+        vsteps: 2
+        jitter: false
+    """
+  When s ← parse_scene(source)
+  Then s.lights.count = 1
+    And s.lights[0] is a shape light
+    And s.lights[0].samples = 8
+    And s.lights[0] is made from s.objects[1]
+
+Scenario: Only glowing spheres can be lights
+  Given source ← scene file:
+    """
+    - add: camera
+      width: 10
+      height: 10
+      field-of-view: 1
+      from: [0, 0, -5]
+      to: [0, 0, 0]
+      up: [0, 1, 0]
+    - add: sphere
+      light:
+        usteps: 4
+        vsteps: 4
+    """
+  When s ← parse_scene(source)
+  Then s fails with "line 10: only a shape whose material has an emission can be a light"
+
+Scenario: A shape light needs to know how to sample the shape
+  Given source ← scene file:
+    """
+    - add: camera
+      width: 10
+      height: 10
+      field-of-view: 1
+      from: [0, 0, -5]
+      to: [0, 0, 0]
+      up: [0, 1, 0]
+    - add: sphere
+      material:
+        emission: [1, 1, 1]
+      light:
+        usteps: 4
+    """
+  When s ← parse_scene(source)
+  Then s fails with "line 12: missing required attribute \"vsteps\""
+
+Scenario: Casting shadows is true or false
+  Given source ← scene file:
+    """
+    - add: camera
+      width: 10
+      height: 10
+      field-of-view: 1
+      from: [0, 0, -5]
+      to: [0, 0, 0]
+      up: [0, 1, 0]
+    - add: sphere
+      material:
+        casts-shadow: sometimes
+    """
+  When s ← parse_scene(source)
+  Then s fails with "line 10: expected true or false, found \"sometimes\""
+
+Scenario: Parsing the surface offset
+  Given source ← scene file:
+    """
+    - add: camera
+      width: 10
+      height: 10
+      field-of-view: 1
+      from: [0, 0, -5]
+      to: [0, 0, 0]
+      up: [0, 1, 0]
+    - add: settings
+      surface-offset: 0.001
+    """
+  When s ← parse_scene(source)
+  Then s.surface_offset = 0.001
+
+Scenario: A scene without settings keeps the default surface offset
+  Given source ← scene file:
+    """
+    - add: camera
+      width: 10
+      height: 10
+      field-of-view: 1
+      from: [0, 0, -5]
+      to: [0, 0, 0]
+      up: [0, 1, 0]
+    """
+  When s ← parse_scene(source)
+  Then s.surface_offset = 0.00001
+
+Scenario: A negative surface offset is reported with its line
+  Given source ← scene file:
+    """
+    - add: camera
+      width: 10
+      height: 10
+      field-of-view: 1
+      from: [0, 0, -5]
+      to: [0, 0, 0]
+      up: [0, 1, 0]
+    - add: settings
+      surface-offset: -1
+    """
+  When s ← parse_scene(source)
+  Then s fails with "line 9: surface offset must not be negative"
+
+Scenario: Parsing flat and sky backgrounds
+  Given flat ← scene file:
+    """
+    - add: camera
+      width: 10
+      height: 10
+      field-of-view: 1
+      from: [0, 0, -5]
+      to: [0, 0, 0]
+      up: [0, 1, 0]
+    - add: background
+      color: [0.2, 0.3, 0.5]
+    """
+    And sky ← scene file:
+    """
+    - add: camera
+      width: 10
+      height: 10
+      field-of-view: 1
+      from: [0, 0, -5]
+      to: [0, 0, 0]
+      up: [0, 1, 0]
+    - add: background
+      zenith: [0, 0, 1]
+      horizon: [1, 1, 1]
+      ground: [0.5, 0.25, 0]
+    """
+  When s ← parse_scene(flat)
+    And t ← parse_scene(sky)
+  Then s.background_at(vector(0, 1, 0)) = color(0.2, 0.3, 0.5)
+    And t.background_at(vector(0, 1, 0)) = color(0, 0, 1)
+    And t.background_at(vector(1, 0, 0)) = color(1, 1, 1)
+    And t.background_at(vector(0, -1, 0)) = color(0.5, 0.25, 0)
+
+Scenario: Environment maps are loaded relative to the scene file
+  When s ← load_scene("testdata/environment.yaml")
+  Then s.background_at(vector(0, 1, 0)) = color(1, 0, 0)
+    And s.background_at(vector(0, -1, 0)) = color(0, 0, 1)
+    And s.background_at(vector(0, 0, 1)) = color(0.5, 0, 0.5)
+
+Scenario: A missing environment map is reported with its line
+  Given source ← scene file:
+    """
+    - add: camera
+      width: 10
+      height: 10
+      field-of-view: 1
+      from: [0, 0, -5]
+      to: [0, 0, 0]
+      up: [0, 1, 0]
+    - add: background
+      image: testdata/missing.ppm
+    """
+  When s ← parse_scene(source)
+  Then s fails with "line 9: open testdata/missing.ppm: no such file or directory"
+
+Scenario: A scene has at most one background
+  Given source ← scene file:
+    """
+    - add: camera
+      width: 10
+      height: 10
+      field-of-view: 1
+      from: [0, 0, -5]
+      to: [0, 0, 0]
+      up: [0, 1, 0]
+    - add: background
+      color: [0.2, 0.3, 0.5]
+    - add: background
+      color: [1, 1, 1]
+    """
+  When s ← parse_scene(source)
+  Then s fails with "line 10: scene already has a background"
+
+Scenario: An HDR environment map can light the scene
+  When s ← load_scene("testdata/lit.yaml")
+  Then s.background_at(vector(0, 1, 0)) = color(2, 2, 2)
+    And s.background_at(vector(0, -1, 0)) = color(0, 0, 0)
+    And s.lights.count = 1
+    And s.lights[0] is an environment light
+    And s.lights[0].samples = 8
+
+Scenario: Only an environment map background can be a light
+  Given source ← scene file:
+    """
+    - add: camera
+      width: 10
+      height: 10
+      field-of-view: 1
+      from: [0, 0, -5]
+      to: [0, 0, 0]
+      up: [0, 1, 0]
+    - add: background
+      color: [0.2, 0.3, 0.5]
+      light:
+        usteps: 4
+        vsteps: 4
+    """
+  When s ← parse_scene(source)
+  Then s fails with "line 10: unknown attribute \"light\""
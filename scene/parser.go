@@ -0,0 +1,1160 @@
+package scene
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"rtt/animation"
+	"rtt/camera"
+	"rtt/canvas"
+	"rtt/matrix"
+	"rtt/ray"
+	"rtt/transformations"
+	"rtt/tuple"
+	"rtt/world"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+type parser struct {
+	defines map[string]*yaml.Node
+	// resolving holds the names of the transform definitions being followed,
+	// to catch those that refer to themselves.
+	resolving map[string]bool
+	scene     *Scene
+	// dir is the directory that paths in the scene are relative to.
+	dir string
+}
+
+type field struct {
+	key   *yaml.Node
+	value *yaml.Node
+}
+
+func (p *parser) parseRoot(root *yaml.Node) error {
+	if root.Kind != yaml.SequenceNode {
+		return errorAt(root, "scene must be a list of add and define entries")
+	}
+
+	for _, item := range root.Content {
+		if item.Kind != yaml.MappingNode {
+			return errorAt(item, "entry must be a mapping")
+		}
+
+		fields := mappingFields(item)
+		if len(fields) == 0 {
+			return errorAt(item, "entry must not be empty")
+		}
+		kind := fields[0].key.Value
+
+		var err error
+		switch kind {
+		case "add":
+			err = p.parseAdd(item, fields)
+		case "define":
+			err = p.parseDefine(item, fields)
+		default:
+			err = errorAt(fields[0].key, "entry must start with add or define, found %q", kind)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func mappingFields(node *yaml.Node) []field {
+	fields := []field{}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		fields = append(fields, field{key: node.Content[i], value: node.Content[i+1]})
+	}
+
+	return fields
+}
+
+func lookup(fields []field, key string) *yaml.Node {
+	for _, f := range fields {
+		if f.key.Value == key {
+			return f.value
+		}
+	}
+	return nil
+}
+
+func checkKeys(fields []field, allowed ...string) error {
+	for _, f := range fields {
+		known := false
+		for _, a := range allowed {
+			if f.key.Value == a {
+				known = true
+				break
+			}
+		}
+
+		if !known {
+			return errorAt(f.key, "unknown attribute %q", f.key.Value)
+		}
+	}
+	return nil
+}
+
+func require(node *yaml.Node, fields []field, key string) (*yaml.Node, error) {
+	value := lookup(fields, key)
+
+	if value == nil {
+		return nil, errorAt(node, "missing required attribute %q", key)
+	}
+
+	return value, nil
+}
+
+func parseFloat(node *yaml.Node) (float64, error) {
+	if node.Kind != yaml.ScalarNode {
+		return 0, errorAt(node, "expected a number")
+	}
+
+	f, err := strconv.ParseFloat(node.Value, 64)
+
+	if err != nil {
+		return 0, errorAt(node, "expected a number, found %q", node.Value)
+	}
+
+	return f, nil
+}
+
+func parseInt(node *yaml.Node) (int32, error) {
+	if node.Kind != yaml.ScalarNode {
+		return 0, errorAt(node, "expected an integer")
+	}
+
+	i, err := strconv.ParseInt(node.Value, 10, 32)
+
+	if err != nil || i <= 0 {
+		return 0, errorAt(node, "expected a positive integer, found %q", node.Value)
+	}
+
+	return int32(i), nil
+}
+
+func parseBool(node *yaml.Node) (bool, error) {
+	var b bool
+	if node.Kind != yaml.ScalarNode || node.Decode(&b) != nil {
+		return false, errorAt(node, "expected true or false, found %q", node.Value)
+	}
+	return b, nil
+}
+
+func parseFloats(node *yaml.Node, count int) ([]float64, error) {
+	if node.Kind != yaml.SequenceNode || len(node.Content) != count {
+		return nil, errorAt(node, "expected a list of %d numbers", count)
+	}
+
+	result := make([]float64, count)
+
+	for i, n := range node.Content {
+		f, err := parseFloat(n)
+		if err != nil {
+			return nil, err
+		}
+		result[i] = f
+	}
+
+	return result, nil
+}
+
+func parsePoint(node *yaml.Node) (tuple.Point, error) {
+	v, err := parseFloats(node, 3)
+	if err != nil {
+		return tuple.Point{}, err
+	}
+	return tuple.NewPoint(v[0], v[1], v[2]), nil
+}
+
+func parseVector(node *yaml.Node) (tuple.Vector, error) {
+	v, err := parseFloats(node, 3)
+	if err != nil {
+		return tuple.Vector{}, err
+	}
+	return tuple.NewVector(v[0], v[1], v[2]), nil
+}
+
+func parseColor(node *yaml.Node) (tuple.Color, error) {
+	v, err := parseFloats(node, 3)
+	if err != nil {
+		return tuple.Color{}, err
+	}
+	return tuple.NewColor(v[0], v[1], v[2]), nil
+}
+
+func (p *parser) parseDefine(node *yaml.Node, fields []field) error {
+	if err := checkKeys(fields, "define", "extend", "value"); err != nil {
+		return err
+	}
+
+	name := fields[0].value
+	if name.Kind != yaml.ScalarNode || name.Value == "" {
+		return errorAt(name, "define must be given a name")
+	}
+
+	value, err := require(node, fields, "value")
+	if err != nil {
+		return err
+	}
+
+	if extend := lookup(fields, "extend"); extend != nil {
+		parent, ok := p.defines[extend.Value]
+
+		if !ok {
+			return errorAt(extend, "unknown definition %q", extend.Value)
+		}
+
+		if parent.Kind != yaml.MappingNode || value.Kind != yaml.MappingNode {
+			return errorAt(extend, "only material definitions can be extended")
+		}
+
+		merged := *value
+		merged.Content = append(append([]*yaml.Node{}, parent.Content...), value.Content...)
+		value = &merged
+	}
+
+	p.defines[name.Value] = value
+	return nil
+}
+
+func (p *parser) parseAdd(node *yaml.Node, fields []field) error {
+	kind := fields[0].value
+
+	switch kind.Value {
+	case "camera":
+		return p.parseCamera(node, fields)
+	case "light":
+		return p.parseLight(node, fields)
+	case "sphere":
+		return p.parseSphere(node, fields)
+	case "background":
+		return p.parseBackground(node, fields)
+	case "settings":
+		return p.parseSettings(node, fields)
+	default:
+		return errorAt(kind, "unknown object type %q", kind.Value)
+	}
+}
+
+func (p *parser) parseCamera(node *yaml.Node, fields []field) error {
+	if err := checkKeys(fields, "add", "width", "height", "field-of-view", "from", "to", "up", "samples", "sampling", "aperture", "focal-distance", "shutter", "keyframes"); err != nil {
+		return err
+	}
+
+	values := map[string]*yaml.Node{}
+	for _, key := range []string{"width", "height", "field-of-view", "from", "to", "up"} {
+		value, err := require(node, fields, key)
+		if err != nil {
+			return err
+		}
+		values[key] = value
+	}
+
+	width, err := parseInt(values["width"])
+	if err != nil {
+		return err
+	}
+	height, err := parseInt(values["height"])
+	if err != nil {
+		return err
+	}
+	fieldOfView, err := parseFloat(values["field-of-view"])
+	if err != nil {
+		return err
+	}
+	from, err := parsePoint(values["from"])
+	if err != nil {
+		return err
+	}
+	to, err := parsePoint(values["to"])
+	if err != nil {
+		return err
+	}
+	up, err := parseVector(values["up"])
+	if err != nil {
+		return err
+	}
+
+	c := camera.NewCamera(width, height, fieldOfView)
+	if err := c.SetTransform(transformations.ViewTransform(from, to, up)); err != nil {
+		return errorAt(node, "invalid camera orientation: %s", err)
+	}
+
+	if err := parseSampling(c, node, fields); err != nil {
+		return err
+	}
+	if err := parseLens(c, node, fields); err != nil {
+		return err
+	}
+	if err := parseShutter(c, fields); err != nil {
+		return err
+	}
+
+	if value := lookup(fields, "keyframes"); value != nil {
+		track, err := p.parseKeyframes(value, parseCameraKeyframe)
+		if err != nil {
+			return err
+		}
+		p.scene.Animation.Add(c, track)
+	}
+
+	p.scene.Camera = c
+	return nil
+}
+
+func parseCameraKeyframe(p *parser, node *yaml.Node, fields []field) (matrix.Mat4, error) {
+	if err := checkKeys(fields, "frame", "from", "to", "up"); err != nil {
+		return matrix.Mat4{}, err
+	}
+
+	values := map[string]*yaml.Node{}
+	for _, key := range []string{"from", "to", "up"} {
+		value, err := require(node, fields, key)
+		if err != nil {
+			return matrix.Mat4{}, err
+		}
+		values[key] = value
+	}
+
+	from, err := parsePoint(values["from"])
+	if err != nil {
+		return matrix.Mat4{}, err
+	}
+	to, err := parsePoint(values["to"])
+	if err != nil {
+		return matrix.Mat4{}, err
+	}
+	up, err := parseVector(values["up"])
+	if err != nil {
+		return matrix.Mat4{}, err
+	}
+
+	return transformations.ViewTransform(from, to, up), nil
+}
+
+func parseSampling(c *camera.Camera, node *yaml.Node, fields []field) error {
+	sampling, samples := c.Sampling()
+
+	if value := lookup(fields, "sampling"); value != nil {
+		s, err := camera.ParseSampling(value.Value)
+		if err != nil {
+			return errorAt(value, "%s", err)
+		}
+		sampling = s
+	}
+
+	if value := lookup(fields, "samples"); value != nil {
+		n, err := parseInt(value)
+		if err != nil {
+			return err
+		}
+		samples = int(n)
+	}
+
+	if err := c.SetSampling(sampling, samples); err != nil {
+		return errorAt(node, "%s", err)
+	}
+	return nil
+}
+
+func parseLens(c *camera.Camera, node *yaml.Node, fields []field) error {
+	aperture, focalDistance := c.Lens()
+
+	if value := lookup(fields, "aperture"); value != nil {
+		a, err := parseFloat(value)
+		if err != nil {
+			return err
+		}
+		aperture = a
+	}
+
+	if value := lookup(fields, "focal-distance"); value != nil {
+		d, err := parseFloat(value)
+		if err != nil {
+			return err
+		}
+		focalDistance = d
+	}
+
+	if err := c.SetLens(aperture, focalDistance); err != nil {
+		return errorAt(node, "%s", err)
+	}
+	return nil
+}
+
+func parseShutter(c *camera.Camera, fields []field) error {
+	value := lookup(fields, "shutter")
+	if value == nil {
+		return nil
+	}
+
+	times, err := parseFloats(value, 2)
+	if err != nil {
+		return err
+	}
+
+	if err := c.SetShutter(times[0], times[1]); err != nil {
+		return errorAt(value, "%s", err)
+	}
+	return nil
+}
+
+func (p *parser) parseLight(node *yaml.Node, fields []field) error {
+	if lookup(fields, "corner") != nil {
+		return p.parseAreaLight(node, fields)
+	}
+	if lookup(fields, "angle") != nil {
+		return p.parseSpotLight(node, fields)
+	}
+	if lookup(fields, "direction") != nil {
+		return p.parseDirectionalLight(node, fields)
+	}
+
+	if err := checkKeys(fields, "add", "at", "intensity"); err != nil {
+		return err
+	}
+
+	at, err := require(node, fields, "at")
+	if err != nil {
+		return err
+	}
+	position, err := parsePoint(at)
+	if err != nil {
+		return err
+	}
+
+	value, err := require(node, fields, "intensity")
+	if err != nil {
+		return err
+	}
+	intensity, err := parseColor(value)
+	if err != nil {
+		return err
+	}
+
+	p.scene.World.AddLight(ray.NewPointLight(position, intensity))
+	return nil
+}
+
+// centred puts every sample of a light in the middle of its cell. Unlike
+// ray.Sequence it holds no state, so the workers of a render can share it.
+func centred() float64 {
+	return 0.5
+}
+
+func (p *parser) parseAreaLight(node *yaml.Node, fields []field) error {
+	if err := checkKeys(fields, "add", "corner", "uvec", "usteps", "vvec", "vsteps", "jitter", "intensity"); err != nil {
+		return err
+	}
+
+	values := map[string]*yaml.Node{}
+	for _, key := range []string{"corner", "uvec", "usteps", "vvec", "vsteps", "intensity"} {
+		value, err := require(node, fields, key)
+		if err != nil {
+			return err
+		}
+		values[key] = value
+	}
+
+	corner, err := parsePoint(values["corner"])
+	if err != nil {
+		return err
+	}
+	uvec, err := parseVector(values["uvec"])
+	if err != nil {
+		return err
+	}
+	usteps, err := parseInt(values["usteps"])
+	if err != nil {
+		return err
+	}
+	vvec, err := parseVector(values["vvec"])
+	if err != nil {
+		return err
+	}
+	vsteps, err := parseInt(values["vsteps"])
+	if err != nil {
+		return err
+	}
+	intensity, err := parseColor(values["intensity"])
+	if err != nil {
+		return err
+	}
+
+	light := ray.NewAreaLight(corner, uvec, int(usteps), vvec, int(vsteps), intensity)
+
+	if value := lookup(fields, "jitter"); value != nil {
+		jitter, err := parseBool(value)
+		if err != nil {
+			return err
+		}
+		if !jitter {
+			light.JitterBy = centred
+		}
+	}
+
+	p.scene.World.AddLight(light)
+	return nil
+}
+
+func (p *parser) parseDirectionalLight(node *yaml.Node, fields []field) error {
+	if err := checkKeys(fields, "add", "direction", "intensity"); err != nil {
+		return err
+	}
+
+	value, err := require(node, fields, "direction")
+	if err != nil {
+		return err
+	}
+	direction, err := parseVector(value)
+	if err != nil {
+		return err
+	}
+
+	value, err = require(node, fields, "intensity")
+	if err != nil {
+		return err
+	}
+	intensity, err := parseColor(value)
+	if err != nil {
+		return err
+	}
+
+	p.scene.World.AddLight(ray.NewDirectionalLight(direction, intensity))
+	return nil
+}
+
+func (p *parser) parseSpotLight(node *yaml.Node, fields []field) error {
+	if err := checkKeys(fields, "add", "at", "direction", "angle", "falloff", "intensity"); err != nil {
+		return err
+	}
+
+	values := map[string]*yaml.Node{}
+	for _, key := range []string{"at", "direction", "angle", "intensity"} {
+		value, err := require(node, fields, key)
+		if err != nil {
+			return err
+		}
+		values[key] = value
+	}
+
+	position, err := parsePoint(values["at"])
+	if err != nil {
+		return err
+	}
+	direction, err := parseVector(values["direction"])
+	if err != nil {
+		return err
+	}
+	angle, err := parseFloat(values["angle"])
+	if err != nil {
+		return err
+	}
+	if angle <= 0 || angle >= math.Pi {
+		return errorAt(values["angle"], "spot light angle must be between 0 and π, found %g", angle)
+	}
+	intensity, err := parseColor(values["intensity"])
+	if err != nil {
+		return err
+	}
+
+	falloff := 0.0
+	if value := lookup(fields, "falloff"); value != nil {
+		if falloff, err = parseFloat(value); err != nil {
+			return err
+		}
+		if falloff < 0 {
+			return errorAt(value, "spot light falloff must not be negative, found %g", falloff)
+		}
+	}
+
+	p.scene.World.AddLight(ray.NewSpotLight(position, direction, intensity, angle, falloff))
+	return nil
+}
+
+// parseSettings reads how the world is rendered, where that belongs to the
+// scene rather than to one run of the renderer.
+func (p *parser) parseSettings(node *yaml.Node, fields []field) error {
+	if err := checkKeys(fields, "add", "surface-offset"); err != nil {
+		return err
+	}
+
+	if value := lookup(fields, "surface-offset"); value != nil {
+		offset, err := parseFloat(value)
+		if err != nil {
+			return err
+		}
+		if offset < 0 {
+			return errorAt(value, "surface offset must not be negative")
+		}
+		p.scene.World.SurfaceOffset = offset
+	}
+	return nil
+}
+
+func (p *parser) parseBackground(node *yaml.Node, fields []field) error {
+	if p.scene.World.Background != nil {
+		return errorAt(node, "scene already has a background")
+	}
+
+	if lookup(fields, "image") != nil {
+		return p.parseEnvironmentMap(node, fields)
+	}
+	if lookup(fields, "zenith") != nil {
+		return p.parseSky(node, fields)
+	}
+
+	if err := checkKeys(fields, "add", "color"); err != nil {
+		return err
+	}
+
+	value, err := require(node, fields, "color")
+	if err != nil {
+		return err
+	}
+	color, err := parseColor(value)
+	if err != nil {
+		return err
+	}
+
+	p.scene.World.Background = world.NewFlatBackground(color)
+	return nil
+}
+
+func (p *parser) parseSky(node *yaml.Node, fields []field) error {
+	if err := checkKeys(fields, "add", "zenith", "horizon", "ground"); err != nil {
+		return err
+	}
+
+	colors := map[string]tuple.Color{}
+	for _, key := range []string{"zenith", "horizon", "ground"} {
+		value, err := require(node, fields, key)
+		if err != nil {
+			return err
+		}
+		color, err := parseColor(value)
+		if err != nil {
+			return err
+		}
+		colors[key] = color
+	}
+
+	p.scene.World.Background = world.NewSky(colors["zenith"], colors["horizon"], colors["ground"])
+	return nil
+}
+
+func (p *parser) parseEnvironmentMap(node *yaml.Node, fields []field) error {
+	if err := checkKeys(fields, "add", "image", "light"); err != nil {
+		return err
+	}
+
+	value := lookup(fields, "image")
+	if value.Kind != yaml.ScalarNode || value.Value == "" {
+		return errorAt(value, "expected the path of an image")
+	}
+
+	path := value.Value
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(p.dir, path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return errorAt(value, "%s", err)
+	}
+	image, err := canvas.Decode(data)
+	if err != nil {
+		return errorAt(value, "%s: %s", path, err)
+	}
+
+	environment := world.NewEnvironmentMap(image)
+	p.scene.World.Background = environment
+
+	if value := lookup(fields, "light"); value != nil {
+		return p.parseEnvironmentLight(environment, value)
+	}
+	return nil
+}
+
+// parseEnvironmentLight makes an environment map into a light as well as
+// the background.
+func (p *parser) parseEnvironmentLight(environment *world.EnvironmentMap, node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return errorAt(node, "light must be a mapping")
+	}
+
+	usteps, vsteps, jitter, err := parseSampleGrid(node)
+	if err != nil {
+		return err
+	}
+
+	light := world.NewEnvironmentLight(environment, int(usteps), int(vsteps))
+	if !jitter {
+		light.JitterBy = centred
+	}
+
+	p.scene.World.AddLight(light)
+	return nil
+}
+
+func (p *parser) parseSphere(node *yaml.Node, fields []field) error {
+	if err := checkKeys(fields, "add", "material", "transform", "keyframes", "light"); err != nil {
+		return err
+	}
+
+	s := ray.NewSphere()
+
+	if value := lookup(fields, "material"); value != nil {
+		if err := p.applyMaterial(&s.Material, value); err != nil {
+			return err
+		}
+	}
+
+	if value := lookup(fields, "transform"); value != nil {
+		transform, err := p.parseTransform(value)
+		if err != nil {
+			return err
+		}
+
+		if err := s.SetTransform(transform); err != nil {
+			return errorAt(value, "invalid transform: %s", err)
+		}
+	}
+
+	if value := lookup(fields, "keyframes"); value != nil {
+		if lookup(fields, "transform") != nil {
+			return errorAt(value, "an object cannot have both a transform and keyframes")
+		}
+
+		track, err := p.parseKeyframes(value, parseObjectKeyframe)
+		if err != nil {
+			return err
+		}
+		p.scene.Animation.Add(s, track)
+	}
+
+	if value := lookup(fields, "light"); value != nil {
+		if err := p.parseShapeLight(s, value); err != nil {
+			return err
+		}
+	}
+
+	p.scene.World.AddObject(s)
+	return nil
+}
+
+// parseShapeLight makes a shape into a light that glows with the emission
+// of its material.
+func (p *parser) parseShapeLight(s *ray.Sphere, node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return errorAt(node, "light must be a mapping")
+	}
+	if s.Material.Emission == tuple.Black {
+		return errorAt(node, "only a shape whose material has an emission can be a light")
+	}
+
+	usteps, vsteps, jitter, err := parseSampleGrid(node)
+	if err != nil {
+		return err
+	}
+
+	light := ray.NewShapeLight(s, s.Material.Emission, int(usteps), int(vsteps))
+	if !jitter {
+		light.JitterBy = centred
+	}
+
+	p.scene.World.AddLight(light)
+	return nil
+}
+
+// parseSampleGrid reads the grid a light covering an area is sampled over,
+// and whether its samples are jittered within their cells.
+func parseSampleGrid(node *yaml.Node) (int32, int32, bool, error) {
+	fields := mappingFields(node)
+	if err := checkKeys(fields, "usteps", "vsteps", "jitter"); err != nil {
+		return 0, 0, false, err
+	}
+
+	steps := map[string]int32{}
+	for _, key := range []string{"usteps", "vsteps"} {
+		value, err := require(node, fields, key)
+		if err != nil {
+			return 0, 0, false, err
+		}
+		if steps[key], err = parseInt(value); err != nil {
+			return 0, 0, false, err
+		}
+	}
+
+	jitter := true
+	if value := lookup(fields, "jitter"); value != nil {
+		var err error
+		if jitter, err = parseBool(value); err != nil {
+			return 0, 0, false, err
+		}
+	}
+
+	return steps["usteps"], steps["vsteps"], jitter, nil
+}
+
+func parseObjectKeyframe(p *parser, node *yaml.Node, fields []field) (matrix.Mat4, error) {
+	if err := checkKeys(fields, "frame", "transform"); err != nil {
+		return matrix.Mat4{}, err
+	}
+
+	value, err := require(node, fields, "transform")
+	if err != nil {
+		return matrix.Mat4{}, err
+	}
+
+	return p.parseTransform(value)
+}
+
+// parseKeyframes reads a list of keyframes, each with a frame number and
+// whatever attributes parseTransform needs to place the target.
+func (p *parser) parseKeyframes(node *yaml.Node, parseTransform func(*parser, *yaml.Node, []field) (matrix.Mat4, error)) (*animation.Track, error) {
+	if node.Kind != yaml.SequenceNode || len(node.Content) == 0 {
+		return nil, errorAt(node, "keyframes must be a non-empty list")
+	}
+
+	keyframes := []animation.Keyframe{}
+
+	for _, item := range node.Content {
+		if item.Kind != yaml.MappingNode {
+			return nil, errorAt(item, "keyframe must be a mapping")
+		}
+
+		fields := mappingFields(item)
+
+		value, err := require(item, fields, "frame")
+		if err != nil {
+			return nil, err
+		}
+		frame, err := parseFloat(value)
+		if err != nil {
+			return nil, err
+		}
+
+		transform, err := parseTransform(p, item, fields)
+		if err != nil {
+			return nil, err
+		}
+
+		keyframes = append(keyframes, animation.Keyframe{Frame: frame, Transform: transform})
+	}
+
+	track, err := animation.NewTrack(keyframes...)
+	if err != nil {
+		return nil, errorAt(node, "%s", err)
+	}
+
+	return track, nil
+}
+
+func (p *parser) resolve(node *yaml.Node) (*yaml.Node, error) {
+	if node.Kind != yaml.ScalarNode {
+		return node, nil
+	}
+
+	value, ok := p.defines[node.Value]
+
+	if !ok {
+		return nil, errorAt(node, "unknown definition %q", node.Value)
+	}
+
+	return value, nil
+}
+
+func (p *parser) applyMaterial(m *ray.Material, node *yaml.Node) error {
+	value, err := p.resolve(node)
+	if err != nil {
+		return err
+	}
+
+	if value.Kind != yaml.MappingNode {
+		return errorAt(node, "material must be a mapping or the name of a definition")
+	}
+
+	for _, f := range mappingFields(value) {
+		var err error
+
+		switch f.key.Value {
+		case "model":
+			err = applyModel(m, f.value)
+		case "color":
+			var c tuple.Color
+			c, err = parseColor(f.value)
+			if err == nil {
+				m.Color = c
+			}
+		case "ambient":
+			m.Ambient, err = parseFloat(f.value)
+		case "diffuse":
+			m.Diffuse, err = parseFloat(f.value)
+		case "specular":
+			m.Specular, err = parseFloat(f.value)
+		case "shininess":
+			m.Shininess, err = parseFloat(f.value)
+		case "metallic":
+			m.Metallic, err = parseFloat(f.value)
+		case "roughness":
+			m.Roughness, err = parseFloat(f.value)
+		case "reflective":
+			m.Reflective, err = parseFloat(f.value)
+		case "emission":
+			var c tuple.Color
+			c, err = parseColor(f.value)
+			if err == nil {
+				m.Emission = c
+			}
+		case "casts-shadow":
+			m.CastsShadow, err = parseBool(f.value)
+		case "pattern":
+			m.Pattern, err = parsePattern(f.value)
+		default:
+			err = errorAt(f.key, "unknown material attribute %q", f.key.Value)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// applyModel switches a material to another model. A Phong material
+// switched to metallic-roughness is converted so that it keeps the look
+// given by the attributes before the switch.
+func applyModel(m *ray.Material, node *yaml.Node) error {
+	model, err := ray.ParseModel(node.Value)
+	if err != nil {
+		return errorAt(node, "%s", err)
+	}
+
+	if model == ray.MetallicRoughness {
+		*m = m.MetallicRoughness()
+	}
+	m.Model = model
+	return nil
+}
+
+// patternSetting is a number a kind of pattern can be tuned with.
+type patternSetting struct {
+	key   string
+	value *float64
+}
+
+// parsePattern reads a pattern, of a type and the two colours it uses,
+// optionally perturbed.
+func parsePattern(node *yaml.Node) (ray.Pattern, error) {
+	if node.Kind != yaml.MappingNode {
+		return nil, errorAt(node, "pattern must be a mapping")
+	}
+	fields := mappingFields(node)
+
+	kind, err := require(node, fields, "type")
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := require(node, fields, "colors")
+	if err != nil {
+		return nil, err
+	}
+	if value.Kind != yaml.SequenceNode || len(value.Content) != 2 {
+		return nil, errorAt(value, "expected a list of 2 colors")
+	}
+	a, err := parseColor(value.Content[0])
+	if err != nil {
+		return nil, err
+	}
+	b, err := parseColor(value.Content[1])
+	if err != nil {
+		return nil, err
+	}
+
+	var pattern ray.Pattern
+	var settings []patternSetting
+	var octaves *int
+
+	switch kind.Value {
+	case "stripes":
+		pattern = ray.NewStripes(a, b)
+	case "marble":
+		marble := ray.NewMarble(a, b)
+		settings = []patternSetting{{"scale", &marble.Scale}, {"turbulence", &marble.Turbulence}}
+		octaves = &marble.Octaves
+		pattern = marble
+	case "wood":
+		wood := ray.NewWood(a, b)
+		settings = []patternSetting{{"rings", &wood.Rings}, {"turbulence", &wood.Turbulence}}
+		octaves = &wood.Octaves
+		pattern = wood
+	case "turbulence":
+		turbulence := ray.NewTurbulence(a, b)
+		settings = []patternSetting{{"scale", &turbulence.Scale}}
+		octaves = &turbulence.Octaves
+		pattern = turbulence
+	default:
+		return nil, errorAt(kind, "unknown pattern %q, expected stripes, marble, wood or turbulence", kind.Value)
+	}
+
+	// Any kind of pattern can be perturbed.
+	allowed := []string{"type", "colors", "perturb"}
+	if octaves != nil {
+		allowed = append(allowed, "octaves")
+	}
+	for _, setting := range settings {
+		allowed = append(allowed, setting.key)
+	}
+	if err := checkKeys(fields, allowed...); err != nil {
+		return nil, err
+	}
+
+	for _, setting := range settings {
+		if value := lookup(fields, setting.key); value != nil {
+			if *setting.value, err = parseFloat(value); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if value := lookup(fields, "octaves"); value != nil {
+		n, err := parseInt(value)
+		if err != nil {
+			return nil, err
+		}
+		*octaves = int(n)
+	}
+
+	if value := lookup(fields, "perturb"); value != nil {
+		return parsePerturb(pattern, value)
+	}
+	return pattern, nil
+}
+
+func parsePerturb(pattern ray.Pattern, node *yaml.Node) (ray.Pattern, error) {
+	if node.Kind != yaml.MappingNode {
+		return nil, errorAt(node, "perturb must be a mapping")
+	}
+	fields := mappingFields(node)
+	if err := checkKeys(fields, "amount", "scale"); err != nil {
+		return nil, err
+	}
+
+	value, err := require(node, fields, "amount")
+	if err != nil {
+		return nil, err
+	}
+	amount, err := parseFloat(value)
+	if err != nil {
+		return nil, err
+	}
+
+	perturb := ray.NewPerturb(pattern, amount)
+	if value := lookup(fields, "scale"); value != nil {
+		if perturb.Scale, err = parseFloat(value); err != nil {
+			return nil, err
+		}
+	}
+	return perturb, nil
+}
+
+func (p *parser) parseTransform(node *yaml.Node) (matrix.Mat4, error) {
+	if node.Kind != yaml.SequenceNode {
+		return matrix.Mat4{}, errorAt(node, "transform must be a list of operations")
+	}
+
+	result := transformations.Identity()
+
+	for _, item := range node.Content {
+		var m matrix.Mat4
+		var err error
+
+		if item.Kind == yaml.ScalarNode {
+			if p.resolving[item.Value] {
+				return matrix.Mat4{}, errorAt(item, "define %q refers to itself", item.Value)
+			}
+
+			var value *yaml.Node
+			value, err = p.resolve(item)
+			if err != nil {
+				return matrix.Mat4{}, err
+			}
+
+			p.resolving[item.Value] = true
+			m, err = p.parseTransform(value)
+			delete(p.resolving, item.Value)
+		} else {
+			m, err = parseOperation(item)
+		}
+
+		if err != nil {
+			return matrix.Mat4{}, err
+		}
+
+		result = result.Then(m)
+	}
+
+	return result.Matrix(), nil
+}
+
+func parseOperation(node *yaml.Node) (matrix.Mat4, error) {
+	if node.Kind != yaml.SequenceNode || len(node.Content) == 0 {
+		return matrix.Mat4{}, errorAt(node, "transform operation must be a list such as [translate, 1, 2, 3]")
+	}
+
+	name := node.Content[0].Value
+	args := node.Content[1:]
+
+	expected := map[string]int{
+		"translate": 3,
+		"scale":     3,
+		"rotate-x":  1,
+		"rotate-y":  1,
+		"rotate-z":  1,
+		"shear":     6,
+	}
+
+	count, ok := expected[name]
+	if !ok {
+		return matrix.Mat4{}, errorAt(node.Content[0], "unknown transform operation %q", name)
+	}
+
+	if len(args) != count {
+		return matrix.Mat4{}, errorAt(node, "%s expects %d arguments, found %d", name, count, len(args))
+	}
+
+	v := make([]float64, count)
+	for i, arg := range args {
+		f, err := parseFloat(arg)
+		if err != nil {
+			return matrix.Mat4{}, err
+		}
+		v[i] = f
+	}
+
+	switch name {
+	case "translate":
+		return transformations.Translation(v[0], v[1], v[2]), nil
+	case "scale":
+		return transformations.Scaling(v[0], v[1], v[2]), nil
+	case "rotate-x":
+		return transformations.RotationX(v[0]), nil
+	case "rotate-y":
+		return transformations.RotationY(v[0]), nil
+	case "rotate-z":
+		return transformations.RotationZ(v[0]), nil
+	default:
+		return transformations.Shearing(v[0], v[1], v[2], v[3], v[4], v[5]), nil
+	}
+}
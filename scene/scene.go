@@ -0,0 +1,97 @@
+package scene
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"rtt/animation"
+	"rtt/camera"
+	"rtt/world"
+
+	"gopkg.in/yaml.v3"
+)
+
+type Scene struct {
+	Camera    *camera.Camera
+	World     *world.World
+	Animation *animation.Animation
+}
+
+type Error struct {
+	Line    int
+	Message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Message)
+}
+
+func errorAt(node *yaml.Node, format string, args ...any) error {
+	return &Error{
+		Line:    node.Line,
+		Message: fmt.Sprintf(format, args...),
+	}
+}
+
+func LoadFile(path string) (*Scene, error) {
+	data, err := os.ReadFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	scene, err := parse(data, filepath.Dir(path))
+
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return scene, nil
+}
+
+// Parse reads a scene from data. Paths in the scene, such as those of
+// images, are relative to the working directory.
+func Parse(data []byte) (*Scene, error) {
+	return parse(data, "")
+}
+
+func parse(data []byte, dir string) (*Scene, error) {
+	var document yaml.Node
+
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+
+	if document.Kind != yaml.DocumentNode || len(document.Content) == 0 {
+		return nil, errors.New("scene is empty")
+	}
+
+	p := &parser{
+		defines:   map[string]*yaml.Node{},
+		resolving: map[string]bool{},
+		dir:       dir,
+		scene: &Scene{
+			World:     world.NewWorld(),
+			Animation: animation.NewAnimation(),
+		},
+	}
+
+	root := document.Content[0]
+
+	if err := p.parseRoot(root); err != nil {
+		return nil, err
+	}
+
+	if p.scene.Camera == nil {
+		return nil, errorAt(root, "scene has no camera")
+	}
+
+	// Animated scenes are loaded as they are at frame 0, so rendering one as
+	// a still image gives its first frame.
+	if err := p.scene.Animation.Apply(0); err != nil {
+		return nil, err
+	}
+
+	return p.scene, nil
+}
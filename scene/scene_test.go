@@ -0,0 +1,596 @@
+package scene
+
+import (
+	"context"
+	"fmt"
+	"rtt/matrix"
+	"rtt/ray"
+	"rtt/shared"
+	"rtt/sharedtest"
+	"rtt/transformations"
+	"rtt/tuple"
+	"rtt/world"
+	"strings"
+	"testing"
+
+	"github.com/cucumber/godog"
+)
+
+type parseResult struct {
+	scene *Scene
+	err   error
+}
+
+func getScene(ctx context.Context, variable string) (*Scene, error) {
+	result := ctx.Value(sharedtest.Variables{Name: variable}).(*parseResult)
+
+	if result.err != nil {
+		return nil, result.err
+	}
+
+	return result.scene, nil
+}
+
+func getMatrix(ctx context.Context, variable string) matrix.Mat4 {
+	return ctx.Value(sharedtest.Variables{Name: variable}).(matrix.Mat4)
+}
+
+func aSceneFile(ctx context.Context, variable string, source *godog.DocString) (context.Context, error) {
+	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, source.Content), nil
+}
+
+func aParsedScene(ctx context.Context, variable, sourceVariable string) (context.Context, error) {
+	source := ctx.Value(sharedtest.Variables{Name: sourceVariable}).(string)
+	scene, err := Parse([]byte(source))
+	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, &parseResult{scene: scene, err: err}), nil
+}
+
+func aLoadedScene(ctx context.Context, variable, path string) (context.Context, error) {
+	scene, err := LoadFile(path)
+	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, &parseResult{scene: scene, err: err}), nil
+}
+
+func atFrame(ctx context.Context, variable string, frame float64) (context.Context, error) {
+	scene, err := getScene(ctx, variable)
+	if err != nil {
+		return ctx, err
+	}
+
+	return ctx, scene.Animation.Apply(frame)
+}
+
+func aMatrix(ctx context.Context, variable, kind string, x, y, z float64) (context.Context, error) {
+	var m matrix.Mat4
+
+	switch kind {
+	case "translation":
+		m = transformations.Translation(x, y, z)
+	default:
+		m = transformations.Scaling(x, y, z)
+	}
+
+	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, m), nil
+}
+
+func aRotation(ctx context.Context, variable, axis string, r float64) (context.Context, error) {
+	var m matrix.Mat4
+
+	switch axis {
+	case "x":
+		m = transformations.RotationX(r)
+	case "y":
+		m = transformations.RotationY(r)
+	default:
+		m = transformations.RotationZ(r)
+	}
+
+	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, m), nil
+}
+
+func aShearing(ctx context.Context, variable string, xy, xz, yx, yz, zx, zy float64) (context.Context, error) {
+	m := transformations.Shearing(xy, xz, yx, yz, zx, zy)
+	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, m), nil
+}
+
+func assertCameraSize(ctx context.Context, variable, component string, expected int32) error {
+	scene, err := getScene(ctx, variable)
+	if err != nil {
+		return err
+	}
+
+	actual := scene.Camera.HSize
+	if component == "vsize" {
+		actual = scene.Camera.VSize
+	}
+
+	if actual != expected {
+		return fmt.Errorf("Error %s %d != %d!", component, actual, expected)
+	}
+	return nil
+}
+
+func assertCameraLens(ctx context.Context, variable, component string, expected float64) error {
+	scene, err := getScene(ctx, variable)
+	if err != nil {
+		return err
+	}
+
+	aperture, focalDistance := scene.Camera.Lens()
+	actual := aperture
+	if component == "focal_distance" {
+		actual = focalDistance
+	}
+
+	if !shared.CompareFloat(actual, expected) {
+		return fmt.Errorf("Error %s %g != %g!", component, actual, expected)
+	}
+	return nil
+}
+
+func assertCameraShutter(ctx context.Context, variable, component string, expected float64) error {
+	scene, err := getScene(ctx, variable)
+	if err != nil {
+		return err
+	}
+
+	open, close := scene.Camera.Shutter()
+	actual := open
+	if component == "shutter_close" {
+		actual = close
+	}
+
+	if !shared.CompareFloat(actual, expected) {
+		return fmt.Errorf("Error %s %g != %g!", component, actual, expected)
+	}
+	return nil
+}
+
+func assertCameraFieldOfView(ctx context.Context, variable string, expected float64) error {
+	scene, err := getScene(ctx, variable)
+	if err != nil {
+		return err
+	}
+
+	if !shared.CompareFloat(scene.Camera.FieldOfView, expected) {
+		return fmt.Errorf("Error %f != %f!", scene.Camera.FieldOfView, expected)
+	}
+	return nil
+}
+
+func assertCameraTransform(ctx context.Context, variable string, fx, fy, fz, tx, ty, tz, ux, uy, uz float64) error {
+	scene, err := getScene(ctx, variable)
+	if err != nil {
+		return err
+	}
+
+	expected := transformations.ViewTransform(tuple.NewPoint(fx, fy, fz), tuple.NewPoint(tx, ty, tz), tuple.NewVector(ux, uy, uz))
+
+	if !scene.Camera.Transform().Equals(expected) {
+		return fmt.Errorf("Error %+v != %+v!", scene.Camera.Transform(), expected)
+	}
+	return nil
+}
+
+func assertCameraSampling(ctx context.Context, variable, expected string) error {
+	scene, err := getScene(ctx, variable)
+	if err != nil {
+		return err
+	}
+
+	sampling, _ := scene.Camera.Sampling()
+	if sampling.String() != expected {
+		return fmt.Errorf("Error sampling %s != %s!", sampling, expected)
+	}
+	return nil
+}
+
+func assertCameraSamples(ctx context.Context, variable string, expected int) error {
+	scene, err := getScene(ctx, variable)
+	if err != nil {
+		return err
+	}
+
+	_, samples := scene.Camera.Sampling()
+	if samples != expected {
+		return fmt.Errorf("Error samples %d != %d!", samples, expected)
+	}
+	return nil
+}
+
+func assertSurfaceOffset(ctx context.Context, variable string, expected float64) error {
+	scene, err := getScene(ctx, variable)
+	if err != nil {
+		return err
+	}
+
+	if scene.World.SurfaceOffset != expected {
+		return fmt.Errorf("Error surface offset %v != %v!", scene.World.SurfaceOffset, expected)
+	}
+	return nil
+}
+
+func assertCount(ctx context.Context, variable, collection string, expected int) error {
+	scene, err := getScene(ctx, variable)
+	if err != nil {
+		return err
+	}
+
+	actual := len(scene.World.Objects)
+	if collection == "lights" {
+		actual = len(scene.World.Lights)
+	}
+
+	if actual != expected {
+		return fmt.Errorf("Error count %d not %d!", actual, expected)
+	}
+	return nil
+}
+
+func assertLightKind(ctx context.Context, variable string, index int, kind string) error {
+	scene, err := getScene(ctx, variable)
+	if err != nil {
+		return err
+	}
+
+	var actual string
+	switch scene.World.Lights[index].(type) {
+	case *ray.PointLight:
+		actual = "point"
+	case *ray.AreaLight:
+		actual = "area"
+	case *ray.DirectionalLight:
+		actual = "directional"
+	case *ray.SpotLight:
+		actual = "spot"
+	case *ray.ShapeLight:
+		actual = "shape"
+	case *world.EnvironmentLight:
+		actual = "environment"
+	}
+
+	if actual != kind {
+		return fmt.Errorf("Error light %d is a %s light, not %s!", index, actual, kind)
+	}
+	return nil
+}
+
+func assertLightEmitter(ctx context.Context, variable string, index int, objectsVariable string, objectIndex int) error {
+	scene, err := getScene(ctx, variable)
+	if err != nil {
+		return err
+	}
+	objects, err := getScene(ctx, objectsVariable)
+	if err != nil {
+		return err
+	}
+
+	if ray.EmitterOf(scene.World.Lights[index]) != ray.Emitter(objects.World.Objects[objectIndex]) {
+		return fmt.Errorf("Error light %d is not made from object %d!", index, objectIndex)
+	}
+	return nil
+}
+
+func lightTuple(light ray.Light, component string) (*tuple.Tuple, error) {
+	switch l := light.(type) {
+	case *ray.PointLight:
+		switch component {
+		case "position":
+			return l.Position.Tuple(), nil
+		case "intensity":
+			return l.Intensity.Tuple(), nil
+		}
+	case *ray.AreaLight:
+		switch component {
+		case "corner":
+			return l.Corner.Tuple(), nil
+		case "uvec":
+			return l.UVec.Tuple(), nil
+		case "vvec":
+			return l.VVec.Tuple(), nil
+		case "intensity":
+			return l.Intensity.Tuple(), nil
+		}
+	case *ray.DirectionalLight:
+		switch component {
+		case "direction":
+			return l.Direction.Tuple(), nil
+		case "intensity":
+			return l.Intensity.Tuple(), nil
+		}
+	case *ray.SpotLight:
+		switch component {
+		case "position":
+			return l.Position.Tuple(), nil
+		case "direction":
+			return l.Direction.Tuple(), nil
+		case "intensity":
+			return l.Intensity.Tuple(), nil
+		}
+	}
+	return nil, fmt.Errorf("%T has no %s", light, component)
+}
+
+func assertLightComponent(ctx context.Context, variable string, index int, component, kind string, x, y, z float64) error {
+	scene, err := getScene(ctx, variable)
+	if err != nil {
+		return err
+	}
+
+	actual, err := lightTuple(scene.World.Lights[index], component)
+	if err != nil {
+		return err
+	}
+
+	expected := tuple.NewVector(x, y, z).Tuple()
+	if kind == "point" {
+		expected = tuple.NewPoint(x, y, z).Tuple()
+	} else if kind == "color" {
+		expected = tuple.NewColor(x, y, z).Tuple()
+	}
+
+	if !tuple.CompareTuple(actual, expected) {
+		return fmt.Errorf("Error %+v != %+v!", actual, expected)
+	}
+	return nil
+}
+
+func assertLightSamples(ctx context.Context, variable string, index, expected int) error {
+	scene, err := getScene(ctx, variable)
+	if err != nil {
+		return err
+	}
+
+	actual := len(scene.World.Lights[index].Sample(nil))
+	if actual != expected {
+		return fmt.Errorf("Error samples %d not %d!", actual, expected)
+	}
+	return nil
+}
+
+func assertSpotLightComponent(ctx context.Context, variable string, index int, component string, expected float64) error {
+	scene, err := getScene(ctx, variable)
+	if err != nil {
+		return err
+	}
+
+	light, ok := scene.World.Lights[index].(*ray.SpotLight)
+	if !ok {
+		return fmt.Errorf("Error light %d is not a spot light!", index)
+	}
+
+	actual := light.Angle
+	if component == "falloff" {
+		actual = light.Falloff
+	}
+
+	if !shared.CompareFloat(actual, expected) {
+		return fmt.Errorf("Error %f != %f!", actual, expected)
+	}
+	return nil
+}
+
+func assertMaterialColor(ctx context.Context, variable string, index int, component string, r, g, b float64) error {
+	scene, err := getScene(ctx, variable)
+	if err != nil {
+		return err
+	}
+
+	actual := scene.World.Objects[index].Material.Color
+	if component == "emission" {
+		actual = scene.World.Objects[index].Material.Emission
+	}
+	expected := tuple.NewColor(r, g, b)
+
+	if !actual.Equals(expected) {
+		return fmt.Errorf("Error %+v != %+v!", actual, expected)
+	}
+	return nil
+}
+
+func assertPatternAt(ctx context.Context, variable string, index int, x, y, z, r, g, b float64) error {
+	scene, err := getScene(ctx, variable)
+	if err != nil {
+		return err
+	}
+
+	pattern := scene.World.Objects[index].Material.Pattern
+	if pattern == nil {
+		return fmt.Errorf("object %d has no pattern", index)
+	}
+
+	actual := pattern.ColorAt(tuple.NewPoint(x, y, z))
+	expected := tuple.NewColor(r, g, b)
+
+	if !actual.Equals(expected) {
+		return fmt.Errorf("Error %+v != %+v!", actual, expected)
+	}
+	return nil
+}
+
+func assertPerturbed(ctx context.Context, variable string, index int, amount, scale float64) error {
+	scene, err := getScene(ctx, variable)
+	if err != nil {
+		return err
+	}
+
+	perturb, ok := scene.World.Objects[index].Material.Pattern.(*ray.Perturb)
+	if !ok {
+		return fmt.Errorf("object %d is not perturbed", index)
+	}
+	if !shared.CompareFloat(perturb.Amount, amount) || !shared.CompareFloat(perturb.Scale, scale) {
+		return fmt.Errorf("Error perturbed by %g at scale %g!", perturb.Amount, perturb.Scale)
+	}
+	return nil
+}
+
+func assertMaterialComponent(ctx context.Context, variable string, index int, component string, expected float64) error {
+	scene, err := getScene(ctx, variable)
+	if err != nil {
+		return err
+	}
+
+	material := scene.World.Objects[index].Material
+
+	var actual float64
+	switch component {
+	case "ambient":
+		actual = material.Ambient
+	case "diffuse":
+		actual = material.Diffuse
+	case "specular":
+		actual = material.Specular
+	case "shininess":
+		actual = material.Shininess
+	case "metallic":
+		actual = material.Metallic
+	case "roughness":
+		actual = material.Roughness
+	case "reflective":
+		actual = material.Reflective
+	}
+
+	if !shared.CompareFloat(actual, expected) {
+		return fmt.Errorf("Error %s %f != %f!", component, actual, expected)
+	}
+	return nil
+}
+
+func assertMaterialModel(ctx context.Context, variable string, index int, expected string) error {
+	scene, err := getScene(ctx, variable)
+	if err != nil {
+		return err
+	}
+
+	if actual := scene.World.Objects[index].Material.Model; actual.String() != expected {
+		return fmt.Errorf("Error model %s != %s!", actual, expected)
+	}
+	return nil
+}
+
+func assertCastsShadow(ctx context.Context, variable string, index int, expected string) error {
+	scene, err := getScene(ctx, variable)
+	if err != nil {
+		return err
+	}
+
+	actual := scene.World.Objects[index].Material.CastsShadow
+	if actual != (expected == "true") {
+		return fmt.Errorf("Error casts_shadow %t != %s!", actual, expected)
+	}
+	return nil
+}
+
+func assertTransform(ctx context.Context, variable string, index int, product string) error {
+	scene, err := getScene(ctx, variable)
+	if err != nil {
+		return err
+	}
+
+	names := strings.Split(product, " * ")
+	expected := getMatrix(ctx, names[0])
+	for _, name := range names[1:] {
+		expected = expected.Multiply(getMatrix(ctx, name))
+	}
+
+	actual := scene.World.Objects[index].Transform()
+	if !actual.Equals(expected) {
+		return fmt.Errorf("Error %+v != %+v!", actual, expected)
+	}
+	return nil
+}
+
+func assertBackground(ctx context.Context, variable string, x, y, z, r, g, b float64) error {
+	scene, err := getScene(ctx, variable)
+	if err != nil {
+		return err
+	}
+
+	if scene.World.Background == nil {
+		return fmt.Errorf("scene %s has no background", variable)
+	}
+
+	actual := scene.World.Background.ColorAt(tuple.NewVector(x, y, z))
+	expected := tuple.NewColor(r, g, b)
+	if !tuple.CompareTuple(actual.Tuple(), expected.Tuple()) {
+		return fmt.Errorf("Error background %+v != %+v!", actual, expected)
+	}
+	return nil
+}
+
+func assertFailure(ctx context.Context, variable, expected string) error {
+	_, err := getScene(ctx, variable)
+
+	if err == nil {
+		return fmt.Errorf("scene %s parsed without error", variable)
+	}
+
+	expected = strings.ReplaceAll(expected, `\"`, `"`)
+	if err.Error() != expected {
+		return fmt.Errorf("Error %q != %q!", err.Error(), expected)
+	}
+	return nil
+}
+
+func constructors(sc *godog.ScenarioContext) {
+	v := sharedtest.TupleVariableName
+	m := sharedtest.MatrixVariableName
+	d := sharedtest.Decimal
+
+	sc.Step(fmt.Sprintf(`^%s ← scene file:$`, v), aSceneFile)
+	sc.Step(fmt.Sprintf(`^%s ← parse_scene\(%s\)$`, v, v), aParsedScene)
+	sc.Step(fmt.Sprintf(`^%s ← load_scene\("(.+)"\)$`, v), aLoadedScene)
+	sc.Step(fmt.Sprintf(`^%s ← (translation|scaling)\(%s, %s, %s\)$`, m, d, d, d), aMatrix)
+	sc.Step(fmt.Sprintf(`^%s ← rotation_(x|y|z)\(%s\)$`, m, d), aRotation)
+	sc.Step(fmt.Sprintf(`^%s is at frame %s$`, v, d), atFrame)
+	sc.Step(fmt.Sprintf(`^%s ← shearing\(%s, %s, %s, %s, %s, %s\)$`, m, d, d, d, d, d, d), aShearing)
+}
+
+func assertions(sc *godog.ScenarioContext) {
+	v := sharedtest.TupleVariableName
+	d := sharedtest.Decimal
+	n := sharedtest.PosInt
+
+	sc.Step(fmt.Sprintf(`^%s.camera.(hsize|vsize) = %s$`, v, n), assertCameraSize)
+	sc.Step(fmt.Sprintf(`^%s.camera.sampling = ([a-z]+)$`, v), assertCameraSampling)
+	sc.Step(fmt.Sprintf(`^%s.camera.samples = %s$`, v, n), assertCameraSamples)
+	sc.Step(fmt.Sprintf(`^%s.camera.field_of_view = %s$`, v, d), assertCameraFieldOfView)
+	sc.Step(fmt.Sprintf(`^%s.camera.(aperture|focal_distance) = %s$`, v, d), assertCameraLens)
+	sc.Step(fmt.Sprintf(`^%s.camera.(shutter_open|shutter_close) = %s$`, v, d), assertCameraShutter)
+	sc.Step(fmt.Sprintf(`^%s.camera.transform = view_transform\(point\(%s, %s, %s\), point\(%s, %s, %s\), vector\(%s, %s, %s\)\)$`, v, d, d, d, d, d, d, d, d, d), assertCameraTransform)
+	sc.Step(fmt.Sprintf(`^%s.surface_offset = %s$`, v, d), assertSurfaceOffset)
+	sc.Step(fmt.Sprintf(`^%s.(objects|lights).count = %s$`, v, n), assertCount)
+	sc.Step(fmt.Sprintf(`^%s.lights\[%s\] is an? (point|area|directional|spot|shape|environment) light$`, v, n), assertLightKind)
+	sc.Step(fmt.Sprintf(`^%s.lights\[%s\] is made from %s.objects\[%s\]$`, v, n, v, n), assertLightEmitter)
+	sc.Step(fmt.Sprintf(`^%s.lights\[%s\].(position|direction|intensity|corner|uvec|vvec) = (point|vector|color)\(%s, %s, %s\)$`, v, n, d, d, d), assertLightComponent)
+	sc.Step(fmt.Sprintf(`^%s.lights\[%s\].samples = %s$`, v, n, n), assertLightSamples)
+	sc.Step(fmt.Sprintf(`^%s.lights\[%s\].(angle|falloff) = %s$`, v, n, d), assertSpotLightComponent)
+	sc.Step(fmt.Sprintf(`^%s.objects\[%s\].material.(color|emission) = color\(%s, %s, %s\)$`, v, n, d, d, d), assertMaterialColor)
+	sc.Step(fmt.Sprintf(`^%s.objects\[%s\].material.(ambient|diffuse|specular|shininess|metallic|roughness|reflective) = %s$`, v, n, d), assertMaterialComponent)
+	sc.Step(fmt.Sprintf(`^%s.objects\[%s\].material.pattern_at\(point\(%s, %s, %s\)\) = color\(%s, %s, %s\)$`, v, n, d, d, d, d, d, d), assertPatternAt)
+	sc.Step(fmt.Sprintf(`^%s.objects\[%s\].material.pattern is perturbed by %s at scale %s$`, v, n, d, d), assertPerturbed)
+	sc.Step(fmt.Sprintf(`^%s.objects\[%s\].material.model = ([a-z\-]+)$`, v, n), assertMaterialModel)
+	sc.Step(fmt.Sprintf(`^%s.objects\[%s\].material.casts_shadow = (true|false)$`, v, n), assertCastsShadow)
+	sc.Step(fmt.Sprintf(`^%s.objects\[%s\].transform = ([A-Z](?: \* [A-Z])*)$`, v, n), assertTransform)
+	sc.Step(fmt.Sprintf(`^%s.background_at\(vector\(%s, %s, %s\)\) = color\(%s, %s, %s\)$`, v, d, d, d, d, d, d), assertBackground)
+	sc.Step(fmt.Sprintf(`^%s fails with "(.*)"$`, v), assertFailure)
+}
+
+func initializeScenario(sc *godog.ScenarioContext) {
+	constructors(sc)
+	assertions(sc)
+}
+
+func TestFeatures(t *testing.T) {
+	suite := godog.TestSuite{
+		ScenarioInitializer: initializeScenario,
+		Options: &godog.Options{
+			Format:   "pretty",
+			Paths:    []string{"features/scene.feature"},
+			TestingT: t,
+		},
+	}
+
+	if suite.Run() != 0 {
+		t.Fatal("non-zero exit status")
+	}
+}
@@ -0,0 +1,79 @@
+package shared
+
+import (
+	"context"
+	"fmt"
+	"rtt/sharedtest"
+	"strconv"
+	"testing"
+
+	"github.com/cucumber/godog"
+)
+
+var float = `([0-9\.\-\+eInfa]+|NaN)`
+
+func aTolerance(ctx context.Context, variable string, absolute, relative float64, ulps int) (context.Context, error) {
+	t := Tolerance{Absolute: absolute, Relative: relative, ULPs: uint64(ulps)}
+	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, t), nil
+}
+
+func assertCompare(ctx context.Context, as, bs, not, variable string) error {
+	a, err := strconv.ParseFloat(as, 64)
+	if err != nil {
+		return err
+	}
+	b, err := strconv.ParseFloat(bs, 64)
+	if err != nil {
+		return err
+	}
+
+	t := DefaultTolerance
+	if variable != "" {
+		t = ctx.Value(sharedtest.Variables{Name: variable}).(Tolerance)
+	}
+
+	if t.Equal(a, b) != (not == "") {
+		return fmt.Errorf("Error %g and %g equal was %t!", a, b, t.Equal(a, b))
+	}
+	return nil
+}
+
+func assertULPDistance(as, bs string, expected int) error {
+	a, err := strconv.ParseFloat(as, 64)
+	if err != nil {
+		return err
+	}
+	b, err := strconv.ParseFloat(bs, 64)
+	if err != nil {
+		return err
+	}
+
+	if actual := ULPDistance(a, b); actual != uint64(expected) {
+		return fmt.Errorf("Error distance %d != %d!", actual, expected)
+	}
+	return nil
+}
+
+func InitializeScenario(sc *godog.ScenarioContext) {
+	v := sharedtest.TupleVariableName
+	d := sharedtest.Decimal
+
+	sc.Step(fmt.Sprintf(`^%s ← tolerance\(%s, %s, %s\)$`, v, d, d, sharedtest.PosInt), aTolerance)
+	sc.Step(fmt.Sprintf(`^%s and %s are (not )?equal(?: within %s)?$`, float, float, v), assertCompare)
+	sc.Step(fmt.Sprintf(`^ulp_distance\(%s, %s\) = %s$`, float, float, sharedtest.PosInt), assertULPDistance)
+}
+
+func TestFeatures(t *testing.T) {
+	suite := godog.TestSuite{
+		ScenarioInitializer: InitializeScenario,
+		Options: &godog.Options{
+			Format:   "pretty",
+			Paths:    []string{"features/comparison.feature"},
+			TestingT: t,
+		},
+	}
+
+	if suite.Run() != 0 {
+		t.Fatal("non-zero exit status")
+	}
+}
@@ -0,0 +1,41 @@
+Feature: Comparing floats
+
+Scenario Outline: Comparing with the default tolerance
+  Then <a> and <b> are <result>
+
+  Examples:
+    | a          | b             | result    |
+    | 1          | 1.000001      | equal     |
+    | 1          | 1.0001        | not equal |
+    | 0          | -0            | equal     |
+    | 0.000001   | -0.000001     | equal     |
+    | 1000000000 | 1000000000.5  | equal     |
+    | 1000000000 | 1000000010    | not equal |
+    | NaN        | NaN           | not equal |
+    | +Inf       | +Inf          | equal     |
+    | +Inf       | -Inf          | not equal |
+
+Scenario Outline: Counting the floats between two values
+  Then ulp_distance(<a>, <b>) = <distance>
+
+  Examples:
+    | a                      | b                       | distance |
+    | 1                      | 1                       | 0        |
+    | 1                      | 1.0000000000000002      | 1        |
+    | 1.0000000000000002     | 1                       | 1        |
+    | 0                      | -0                      | 0        |
+    | 5e-324                 | -5e-324                 | 2        |
+    | 1e300                  | 1.0000000000000002e300  | 1        |
+
+Scenario: A tolerance of a few floats
+  Given t ← tolerance(0, 0, 2)
+  Then 1 and 1.0000000000000004 are equal within t
+    And 1 and 1.0000000000000007 are not equal within t
+    And 1e-300 and 1.0000000000000002e-300 are equal within t
+
+Scenario: A relative tolerance scales with the values
+  Given t ← tolerance(0, 0.01, 0)
+  Then 100 and 100.9 are equal within t
+    And 100 and 101.1 are not equal within t
+    And 0.1 and 0.1009 are equal within t
+    And 0.1 and 0.1011 are not equal within t
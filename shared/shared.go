@@ -4,7 +4,66 @@ import (
 	"math"
 )
 
+// Epsilon is the absolute part of DefaultTolerance, which is enough to tell
+// apart the values of a scene at the scale of a unit sphere.
+const Epsilon = 0.00001
+
+// DefaultSurfaceOffset is how far a hit point is moved off its surface
+// before secondary rays are cast from it, so that they do not hit the same
+// surface again through rounding errors. It is a length in world units, not
+// a tolerance, and worlds with very large or very small objects may need
+// another value.
+const DefaultSurfaceOffset = 0.00001
+
+// Tolerance decides when two floats are equal. Values are equal when they
+// are within Absolute of each other, which matters near zero; within
+// Relative of the larger magnitude, which matters for large values; or at
+// most ULPs representable floats apart.
+type Tolerance struct {
+	Absolute float64
+	Relative float64
+	ULPs     uint64
+}
+
+var DefaultTolerance = Tolerance{Absolute: Epsilon, Relative: 1e-9, ULPs: 4}
+
+func (t Tolerance) Equal(a, b float64) bool {
+	if a == b {
+		return true
+	}
+
+	if math.IsNaN(a) || math.IsNaN(b) || math.IsInf(a, 0) || math.IsInf(b, 0) {
+		return false
+	}
+
+	diff := math.Abs(a - b)
+	if diff <= t.Absolute || diff <= t.Relative*math.Max(math.Abs(a), math.Abs(b)) {
+		return true
+	}
+
+	return ULPDistance(a, b) <= t.ULPs
+}
+
+// ULPDistance counts the floats between a and b, so that neighbouring floats
+// are 1 apart whatever their magnitude. 0 and -0 are the same float.
+func ULPDistance(a, b float64) uint64 {
+	ia, ib := orderedBits(a), orderedBits(b)
+	if ia > ib {
+		return uint64(ia) - uint64(ib)
+	}
+	return uint64(ib) - uint64(ia)
+}
+
+// orderedBits maps floats onto integers in the same order, so the
+// difference of two results is the number of floats between them.
+func orderedBits(f float64) int64 {
+	bits := int64(math.Float64bits(f))
+	if bits < 0 {
+		return math.MinInt64 - bits
+	}
+	return bits
+}
+
 func CompareFloat(a, b float64) bool {
-	epsilon := 0.00001
-	return math.Abs(a-b) < epsilon
+	return DefaultTolerance.Equal(a, b)
 }
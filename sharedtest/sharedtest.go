@@ -17,7 +17,7 @@ var rootDivision = regexp.MustCompile(rootDivisionPattern)
 
 type Variables struct{ Name string }
 
-func parseDecimal(s string) (float64, error) {
+func ParseDecimal(s string) (float64, error) {
 	sign := 1.0
 	remaining := s
 	if s[0] == '-' {
@@ -47,15 +47,15 @@ func parseDecimal(s string) (float64, error) {
 }
 
 func ParseXYZ(xString, yString, zString string) (float64, float64, float64, error) {
-	x, err := parseDecimal(xString)
+	x, err := ParseDecimal(xString)
 	if err != nil {
 		return 0, 0, 0, err
 	}
-	y, err := parseDecimal(yString)
+	y, err := ParseDecimal(yString)
 	if err != nil {
 		return 0, 0, 0, err
 	}
-	z, err := parseDecimal(zString)
+	z, err := ParseDecimal(zString)
 	if err != nil {
 		return 0, 0, 0, err
 	}
@@ -0,0 +1,54 @@
+package transformations
+
+import (
+	"rtt/matrix"
+	"rtt/tuple"
+)
+
+// Builder chains transformations in the order they are applied, so
+// Identity().RotateX(a).Translate(x, y, z) rotates first and then
+// translates, without writing the matrix product back to front.
+type Builder struct {
+	transform matrix.Mat4
+}
+
+func Identity() Builder {
+	return Builder{transform: matrix.Identity4}
+}
+
+// Then applies m after everything already in the chain.
+func (b Builder) Then(m matrix.Mat4) Builder {
+	return Builder{transform: m.Multiply(b.transform)}
+}
+
+func (b Builder) Translate(x, y, z float64) Builder {
+	return b.Then(Translation(x, y, z))
+}
+
+func (b Builder) Scale(x, y, z float64) Builder {
+	return b.Then(Scaling(x, y, z))
+}
+
+func (b Builder) RotateX(r float64) Builder {
+	return b.Then(RotationX(r))
+}
+
+func (b Builder) RotateY(r float64) Builder {
+	return b.Then(RotationY(r))
+}
+
+func (b Builder) RotateZ(r float64) Builder {
+	return b.Then(RotationZ(r))
+}
+
+func (b Builder) Rotate(axis tuple.Vector, angle float64) Builder {
+	return b.Then(RotationAxisAngle(axis, angle))
+}
+
+func (b Builder) Shear(xy, xz, yx, yz, zx, zy float64) Builder {
+	return b.Then(Shearing(xy, xz, yx, yz, zx, zy))
+}
+
+func (b Builder) Matrix() matrix.Mat4 {
+	return b.transform
+}
@@ -0,0 +1,87 @@
+package transformations
+
+import (
+	"errors"
+	"math"
+	"rtt/matrix"
+	"rtt/quaternion"
+	"rtt/shared"
+	"rtt/tuple"
+)
+
+var ErrNotDecomposable = errors.New("matrix is not made of translation, rotation and scaling alone")
+
+// Compose scales, then rotates, then translates.
+func Compose(translation tuple.Vector, rotation quaternion.Quaternion, scale tuple.Vector) matrix.Mat4 {
+	return Identity().
+		Scale(scale.X, scale.Y, scale.Z).
+		Then(rotation.Matrix()).
+		Translate(translation.X, translation.Y, translation.Z).
+		Matrix()
+}
+
+// Decompose is the inverse of Compose. It fails for matrices that shear,
+// project or collapse a dimension, since those have no such split. A
+// mirroring matrix comes back with a negative x scale.
+func Decompose(m matrix.Mat4) (translation tuple.Vector, rotation quaternion.Quaternion, scale tuple.Vector, err error) {
+	if !shared.CompareFloat(m[12], 0) || !shared.CompareFloat(m[13], 0) || !shared.CompareFloat(m[14], 0) || !shared.CompareFloat(m[15], 1) {
+		return translation, rotation, scale, ErrNotDecomposable
+	}
+
+	translation = tuple.NewVector(m[3], m[7], m[11])
+
+	columns := [3]tuple.Vector{
+		tuple.NewVector(m[0], m[4], m[8]),
+		tuple.NewVector(m[1], m[5], m[9]),
+		tuple.NewVector(m[2], m[6], m[10]),
+	}
+
+	var lengths [3]float64
+	for i, c := range columns {
+		lengths[i] = c.Magnitude()
+		if lengths[i] < shared.Epsilon {
+			return translation, rotation, scale, ErrNotDecomposable
+		}
+		columns[i] = c.ScalarDiv(lengths[i])
+	}
+
+	for i := 0; i < 3; i++ {
+		if math.Abs(columns[i].Dot(columns[(i+1)%3])) > shared.Epsilon {
+			return translation, rotation, scale, ErrNotDecomposable
+		}
+	}
+
+	if columns[0].Cross(columns[1]).Dot(columns[2]) < 0 {
+		lengths[0] = -lengths[0]
+		columns[0] = columns[0].Negate()
+	}
+
+	scale = tuple.NewVector(lengths[0], lengths[1], lengths[2])
+	rotation = quaternion.FromMatrix(matrix.Mat4{
+		columns[0].X, columns[1].X, columns[2].X, 0,
+		columns[0].Y, columns[1].Y, columns[2].Y, 0,
+		columns[0].Z, columns[1].Z, columns[2].Z, 0,
+		0, 0, 0, 1,
+	})
+
+	return translation, rotation, scale, nil
+}
+
+// Interpolate blends two transforms t of the way from a to b, moving and
+// scaling in straight lines and rotating along the shortest arc.
+func Interpolate(a, b matrix.Mat4, t float64) (matrix.Mat4, error) {
+	ta, ra, sa, err := Decompose(a)
+	if err != nil {
+		return matrix.Mat4{}, err
+	}
+
+	tb, rb, sb, err := Decompose(b)
+	if err != nil {
+		return matrix.Mat4{}, err
+	}
+
+	translation := ta.Add(tb.Subtract(ta).ScalarMultiply(t))
+	scale := sa.Add(sb.Subtract(sa).ScalarMultiply(t))
+
+	return Compose(translation, quaternion.Slerp(ra, rb, t), scale), nil
+}
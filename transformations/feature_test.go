@@ -2,12 +2,17 @@ package transformations
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math"
+	"regexp"
 	"rtt/matrix"
+	"rtt/quaternion"
 	"rtt/sharedtest"
 	"rtt/tuple"
 	"rtt/tupletest"
+	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/cucumber/godog"
@@ -43,6 +48,219 @@ func aRotation(ctx context.Context, variable, over string, value float64) (conte
 	}
 }
 
+var chainCall = regexp.MustCompile(`\.([a-z_]+)\(((?:[^()]|\([^()]*\))*)\)`)
+var axisAngleArgs = regexp.MustCompile(`^vector\((.+), (.+), (.+)\), (.+)$`)
+var piFraction = regexp.MustCompile(`^(\d*)π / (\d+)$`)
+
+func parseAngle(s string) (float64, error) {
+	match := piFraction.FindStringSubmatch(s)
+	if match == nil {
+		return sharedtest.ParseDecimal(s)
+	}
+
+	multiple := 1.0
+	if match[1] != "" {
+		multiple, _ = strconv.ParseFloat(match[1], 64)
+	}
+	divisor, _ := strconv.ParseFloat(match[2], 64)
+
+	return multiple * math.Pi / divisor, nil
+}
+
+func parseAxisAngle(args string) (tuple.Vector, float64, error) {
+	match := axisAngleArgs.FindStringSubmatch(args)
+	if match == nil {
+		return tuple.Vector{}, 0, fmt.Errorf("expected vector(x, y, z), angle, found %q", args)
+	}
+
+	x, y, z, err := sharedtest.ParseXYZ(match[1], match[2], match[3])
+	if err != nil {
+		return tuple.Vector{}, 0, err
+	}
+
+	angle, err := parseAngle(match[4])
+	return tuple.NewVector(x, y, z), angle, err
+}
+
+func parseArgs(args string, count int) ([]float64, error) {
+	parts := strings.Split(args, ", ")
+	if len(parts) != count {
+		return nil, fmt.Errorf("expected %d arguments, found %q", count, args)
+	}
+
+	values := make([]float64, count)
+	for i, part := range parts {
+		value, err := parseAngle(part)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value
+	}
+
+	return values, nil
+}
+
+func applyCall(b Builder, name, args string) (Builder, error) {
+	if name == "rotate" {
+		axis, angle, err := parseAxisAngle(args)
+		return b.Rotate(axis, angle), err
+	}
+
+	counts := map[string]int{
+		"translate": 3,
+		"scale":     3,
+		"rotate_x":  1,
+		"rotate_y":  1,
+		"rotate_z":  1,
+		"shear":     6,
+	}
+
+	count, ok := counts[name]
+	if !ok {
+		return b, fmt.Errorf("unknown transformation %s", name)
+	}
+
+	v, err := parseArgs(args, count)
+	if err != nil {
+		return b, err
+	}
+
+	switch name {
+	case "translate":
+		return b.Translate(v[0], v[1], v[2]), nil
+	case "scale":
+		return b.Scale(v[0], v[1], v[2]), nil
+	case "rotate_x":
+		return b.RotateX(v[0]), nil
+	case "rotate_y":
+		return b.RotateY(v[0]), nil
+	case "rotate_z":
+		return b.RotateZ(v[0]), nil
+	default:
+		return b.Shear(v[0], v[1], v[2], v[3], v[4], v[5]), nil
+	}
+}
+
+func aChain(ctx context.Context, variable, calls string) (context.Context, error) {
+	b := Identity()
+
+	for _, call := range chainCall.FindAllStringSubmatch(calls, -1) {
+		var err error
+		b, err = applyCall(b, call[1], call[2])
+		if err != nil {
+			return ctx, err
+		}
+	}
+
+	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, b.Matrix()), nil
+}
+
+func anAxisAngleRotation(ctx context.Context, variable, args string) (context.Context, error) {
+	axis, angle, err := parseAxisAngle(args)
+	if err != nil {
+		return ctx, err
+	}
+
+	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, RotationAxisAngle(axis, angle)), nil
+}
+
+type decomposition struct {
+	translation tuple.Vector
+	rotation    quaternion.Quaternion
+	scale       tuple.Vector
+}
+
+func aDecomposition(ctx context.Context, variable, matrixVariable string) (context.Context, error) {
+	m := ctx.Value(sharedtest.Variables{Name: matrixVariable}).(matrix.Mat4)
+
+	translation, rotation, scale, err := Decompose(m)
+	if err != nil {
+		return ctx, err
+	}
+
+	parts := decomposition{translation: translation, rotation: rotation, scale: scale}
+	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, parts), nil
+}
+
+func aComposition(ctx context.Context, variable, partsVariable string) (context.Context, error) {
+	parts := ctx.Value(sharedtest.Variables{Name: partsVariable}).(decomposition)
+	m := Compose(parts.translation, parts.rotation, parts.scale)
+	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, m), nil
+}
+
+func anInterpolation(ctx context.Context, variable, aVariable, bVariable string, t float64) (context.Context, error) {
+	a := ctx.Value(sharedtest.Variables{Name: aVariable}).(matrix.Mat4)
+	b := ctx.Value(sharedtest.Variables{Name: bVariable}).(matrix.Mat4)
+
+	m, err := Interpolate(a, b, t)
+	if err != nil {
+		return ctx, err
+	}
+
+	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, m), nil
+}
+
+func assertDecompositionVector(ctx context.Context, variable, component, xs, ys, zs string) (context.Context, error) {
+	parts := ctx.Value(sharedtest.Variables{Name: variable}).(decomposition)
+	x, y, z, err := sharedtest.ParseXYZ(xs, ys, zs)
+	if err != nil {
+		return ctx, err
+	}
+
+	actual := parts.translation
+	if component == "scale" {
+		actual = parts.scale
+	}
+
+	expected := tuple.NewVector(x, y, z)
+	if !actual.Equals(expected) {
+		return ctx, fmt.Errorf("%s was %+v, not %+v", component, actual, expected)
+	}
+
+	return ctx, nil
+}
+
+func assertDecompositionRotation(ctx context.Context, variable, ws, xs, ys, zs string) (context.Context, error) {
+	parts := ctx.Value(sharedtest.Variables{Name: variable}).(decomposition)
+	w, err := sharedtest.ParseDecimal(ws)
+	if err != nil {
+		return ctx, err
+	}
+	x, y, z, err := sharedtest.ParseXYZ(xs, ys, zs)
+	if err != nil {
+		return ctx, err
+	}
+
+	expected := quaternion.New(w, x, y, z)
+	if !parts.rotation.Equals(expected) {
+		return ctx, fmt.Errorf("rotation was %+v, not %+v", parts.rotation, expected)
+	}
+
+	return ctx, nil
+}
+
+func assertNotDecomposable(ctx context.Context, matrixVariable string) (context.Context, error) {
+	m := ctx.Value(sharedtest.Variables{Name: matrixVariable}).(matrix.Mat4)
+
+	if _, _, _, err := Decompose(m); !errors.Is(err, ErrNotDecomposable) {
+		return ctx, fmt.Errorf("expected %s not to be decomposable, got %v", matrixVariable, err)
+	}
+
+	return ctx, nil
+}
+
+func decompositionSteps(sc *godog.ScenarioContext) {
+	v := tupleVariableName
+	d := sharedtest.Decimal
+
+	sc.Step(fmt.Sprintf(`^%s ← decompose\(%s\)$`, v, v), aDecomposition)
+	sc.Step(fmt.Sprintf(`^%s ← compose\(%s\)$`, v, v), aComposition)
+	sc.Step(fmt.Sprintf(`^%s ← interpolate\(%s, %s, %s\)$`, v, v, v, d), anInterpolation)
+	sc.Step(fmt.Sprintf(`^%s\.(translation|scale) = vector\(%s, %s, %s\)$`, v, d, d, d), assertDecompositionVector)
+	sc.Step(fmt.Sprintf(`^%s\.rotation = quaternion\(%s, %s, %s, %s\)$`, v, d, d, d, d), assertDecompositionRotation)
+	sc.Step(fmt.Sprintf(`^%s cannot be decomposed$`, v), assertNotDecomposable)
+}
+
 func transformationConstructors(sc *godog.ScenarioContext) {
 	tupletest.AddConstructPoint(sc)
 	tupletest.AddConstructVector(sc)
@@ -52,10 +270,26 @@ func transformationConstructors(sc *godog.ScenarioContext) {
 	sc.Step(regex, aScaling)
 	regex = `^(.+) ← rotation_(.)\(π \/ (\d+)\)$`
 	sc.Step(regex, aRotation)
+	regex = fmt.Sprintf(`^%s ← identity\(\)((?:\..+)*)$`, tupleVariableName)
+	sc.Step(regex, aChain)
+	regex = fmt.Sprintf(`^%s ← rotation_axis_angle\((.+)\)$`, tupleVariableName)
+	sc.Step(regex, anAxisAngleRotation)
 	regex = fmt.Sprintf(`^(.+) ← shearing\(%s, %s, %s, %s, %s, %s\)$`, sharedtest.Decimal, sharedtest.Decimal, sharedtest.Decimal, sharedtest.Decimal, sharedtest.Decimal, sharedtest.Decimal)
 	sc.Step(regex, aShearing)
 }
 
+// transform multiplies the point or vector stored in variable by m.
+func transform(ctx context.Context, m matrix.Mat4, variable string) (any, error) {
+	switch t := ctx.Value(sharedtest.Variables{Name: variable}).(type) {
+	case tuple.Point:
+		return m.MultiplyPoint(t), nil
+	case tuple.Vector:
+		return m.MultiplyVector(t), nil
+	default:
+		return nil, fmt.Errorf("%s is a %T, not a point or vector", variable, t)
+	}
+}
+
 func assertMultiplyComparePoint(ctx context.Context, a, b string, xStr, yStr, zStr string) (context.Context, error) {
 	x, y, z, err := sharedtest.ParseXYZ(xStr, yStr, zStr)
 
@@ -63,14 +297,14 @@ func assertMultiplyComparePoint(ctx context.Context, a, b string, xStr, yStr, zS
 		return ctx, err
 	}
 
-	expected := tuple.Point(x, y, z)
+	expected := tuple.NewPoint(x, y, z)
 
-	aMatrix := ctx.Value(sharedtest.Variables{Name: a}).(*matrix.Matrix)
-	bPoint := ctx.Value(sharedtest.Variables{Name: b}).(*tuple.Tuple)
+	aMatrix := ctx.Value(sharedtest.Variables{Name: a}).(matrix.Mat4)
+	bPoint := ctx.Value(sharedtest.Variables{Name: b}).(tuple.Point)
 
-	result := aMatrix.MultiplyTuple(bPoint)
+	result := aMatrix.MultiplyPoint(bPoint)
 
-	if !tuple.CompareTuple(expected, result) {
+	if !expected.Equals(result) {
 		return ctx, fmt.Errorf("%+v was not %+v", result, expected)
 	}
 
@@ -78,14 +312,14 @@ func assertMultiplyComparePoint(ctx context.Context, a, b string, xStr, yStr, zS
 }
 
 func assertMultiplyCompareVector(ctx context.Context, a, b string, x, y, z float64) (context.Context, error) {
-	expected := tuple.Vector(x, y, z)
+	expected := tuple.NewVector(x, y, z)
 
-	aMatrix := ctx.Value(sharedtest.Variables{Name: a}).(*matrix.Matrix)
-	bPoint := ctx.Value(sharedtest.Variables{Name: b}).(*tuple.Tuple)
+	aMatrix := ctx.Value(sharedtest.Variables{Name: a}).(matrix.Mat4)
+	bVector := ctx.Value(sharedtest.Variables{Name: b}).(tuple.Vector)
 
-	result := aMatrix.MultiplyTuple(bPoint)
+	result := aMatrix.MultiplyVector(bVector)
 
-	if *expected != *result {
+	if expected != result {
 		return ctx, fmt.Errorf("%+v was not %+v", result, expected)
 	}
 
@@ -93,9 +327,12 @@ func assertMultiplyCompareVector(ctx context.Context, a, b string, x, y, z float
 }
 
 func assignInverse(ctx context.Context, destination, input string) (context.Context, error) {
-	inputMatrix := ctx.Value(sharedtest.Variables{Name: input}).(*matrix.Matrix)
+	inputMatrix := ctx.Value(sharedtest.Variables{Name: input}).(matrix.Mat4)
 
-	result, _ := inputMatrix.Invert()
+	result, err := inputMatrix.Invert()
+	if err != nil {
+		return ctx, err
+	}
 
 	return context.WithValue(ctx, sharedtest.Variables{Name: destination}, result), nil
 }
@@ -107,19 +344,21 @@ func transformationAssertions(sc *godog.ScenarioContext) {
 	sc.Step(regex, assertMultiplyCompareVector)
 }
 
-func matrixTupleMultiplication(ctx context.Context, destination, aName, bName string) context.Context {
-	a := ctx.Value(sharedtest.Variables{Name: aName}).(*matrix.Matrix)
-	b := ctx.Value(sharedtest.Variables{Name: bName}).(*tuple.Tuple)
+func matrixTupleMultiplication(ctx context.Context, destination, aName, bName string) (context.Context, error) {
+	a := ctx.Value(sharedtest.Variables{Name: aName}).(matrix.Mat4)
 
-	t := a.MultiplyTuple(b)
+	t, err := transform(ctx, a, bName)
+	if err != nil {
+		return ctx, err
+	}
 
-	return context.WithValue(ctx, sharedtest.Variables{Name: destination}, t)
+	return context.WithValue(ctx, sharedtest.Variables{Name: destination}, t), nil
 }
 
 func matrixMultiplication(ctx context.Context, destination, aName, bName, cName string) context.Context {
-	a := ctx.Value(sharedtest.Variables{Name: aName}).(*matrix.Matrix)
-	b := ctx.Value(sharedtest.Variables{Name: bName}).(*matrix.Matrix)
-	c := ctx.Value(sharedtest.Variables{Name: cName}).(*matrix.Matrix)
+	a := ctx.Value(sharedtest.Variables{Name: aName}).(matrix.Mat4)
+	b := ctx.Value(sharedtest.Variables{Name: bName}).(matrix.Mat4)
+	c := ctx.Value(sharedtest.Variables{Name: cName}).(matrix.Mat4)
 
 	t := a.Multiply(b.Multiply(c))
 
@@ -138,8 +377,8 @@ func transformationAssignments(sc *godog.ScenarioContext) {
 }
 
 func assertTupleEquals(ctx context.Context, aName, operator, bName string) (context.Context, error) {
-	a := ctx.Value(sharedtest.Variables{Name: aName}).(*tuple.Tuple)
-	b := ctx.Value(sharedtest.Variables{Name: bName}).(*tuple.Tuple)
+	a, _ := tupletest.AsTuple(ctx, aName)
+	b, _ := tupletest.AsTuple(ctx, bName)
 
 	if operator == "=" && !tuple.CompareTuple(a, b) {
 		return ctx, fmt.Errorf("Error %s != %s!", aName, bName)
@@ -152,10 +391,77 @@ func assertTupleEquals(ctx context.Context, aName, operator, bName string) (cont
 	return ctx, nil
 }
 
+func aViewTransform(ctx context.Context, variable, fromName, toName, upName string) (context.Context, error) {
+	from := ctx.Value(sharedtest.Variables{Name: fromName}).(tuple.Point)
+	to := ctx.Value(sharedtest.Variables{Name: toName}).(tuple.Point)
+	up := ctx.Value(sharedtest.Variables{Name: upName}).(tuple.Vector)
+
+	t := ViewTransform(from, to, up)
+
+	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, t), nil
+}
+
+func assertMatrixEquals(ctx context.Context, name string, expected matrix.Mat4) (context.Context, error) {
+	actual := ctx.Value(sharedtest.Variables{Name: name}).(matrix.Mat4)
+
+	if !actual.Equals(expected) {
+		return ctx, fmt.Errorf("%+v was not %+v", actual, expected)
+	}
+
+	return ctx, nil
+}
+
+func assertIdentity(ctx context.Context, name string) (context.Context, error) {
+	return assertMatrixEquals(ctx, name, matrix.Identity4)
+}
+
+func assertScaling(ctx context.Context, name string, x, y, z float64) (context.Context, error) {
+	return assertMatrixEquals(ctx, name, Scaling(x, y, z))
+}
+
+func assertTranslation(ctx context.Context, name string, x, y, z float64) (context.Context, error) {
+	return assertMatrixEquals(ctx, name, Translation(x, y, z))
+}
+
+func assertMatrixTable(ctx context.Context, name string, table *godog.Table) (context.Context, error) {
+	values := []float64{}
+
+	for _, row := range table.Rows {
+		for _, cell := range row.Cells {
+			value, err := strconv.ParseFloat(strings.TrimSpace(cell.Value), 64)
+			if err != nil {
+				return ctx, err
+			}
+			values = append(values, value)
+		}
+	}
+
+	if len(values) != 16 {
+		return ctx, fmt.Errorf("expected a 4x4 matrix, found %d values", len(values))
+	}
+
+	return assertMatrixEquals(ctx, name, matrix.Mat4(values))
+}
+
+func viewTransformSteps(sc *godog.ScenarioContext) {
+	regex := fmt.Sprintf(`^%s ← view_transform\(%s, %s, %s\)$`, tupleVariableName, tupleVariableName, tupleVariableName, tupleVariableName)
+	sc.Step(regex, aViewTransform)
+	regex = fmt.Sprintf(`^%s = identity_matrix$`, tupleVariableName)
+	sc.Step(regex, assertIdentity)
+	regex = fmt.Sprintf(`^%s = scaling\(%s, %s, %s\)$`, tupleVariableName, sharedtest.Decimal, sharedtest.Decimal, sharedtest.Decimal)
+	sc.Step(regex, assertScaling)
+	regex = fmt.Sprintf(`^%s = translation\(%s, %s, %s\)$`, tupleVariableName, sharedtest.Decimal, sharedtest.Decimal, sharedtest.Decimal)
+	sc.Step(regex, assertTranslation)
+	regex = fmt.Sprintf(`^%s is the following 4x4 matrix:$`, tupleVariableName)
+	sc.Step(regex, assertMatrixTable)
+}
+
 func InitializeScenario(sc *godog.ScenarioContext) {
 	transformationConstructors(sc)
 	transformationAssignments(sc)
 	transformationAssertions(sc)
+	viewTransformSteps(sc)
+	decompositionSteps(sc)
 }
 
 func TestFeature(t *testing.T) {
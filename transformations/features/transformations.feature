@@ -11,6 +11,12 @@ Scenario: Multiplying by the inverse of a translation matrix
     And p ← point(-3, 4, 5)
    Then inv * p = point(-8, 7, 3)
 
+Scenario: Inverting a translation far from the origin
+  Given transform ← translation(2000000000000, 0, 0)
+    And inv = inverse(transform)
+    And p ← point(2000000000000, 4, 5)
+   Then inv * p = point(0, 4, 5)
+
 Scenario: Translation does not affect vectors
   Given T ← translation(5, -3, 2)
     And v ← vector(-3, 4, 5)
@@ -118,34 +124,116 @@ Scenario: Chained transformations must be applied in reverse order
   When T ← C * B * A
   Then T * p = point(15, 0, 7)
 
-# Scenario: The transformation matrix for the default orientation
-#   Given from ← point(0, 0, 0)
-#     And to ← point(0, 0, -1)
-#     And up ← vector(0, 1, 0)
-#   When t ← view_transform(from, to, up)
-#   Then t = identity_matrix
-
-# Scenario: A view transformation matrix looking in positive z direction
-#   Given from ← point(0, 0, 0)
-#     And to ← point(0, 0, 1)
-#     And up ← vector(0, 1, 0)
-#   When t ← view_transform(from, to, up)
-#   Then t = scaling(-1, 1, -1)
-
-# Scenario: The view transformation moves the world
-#   Given from ← point(0, 0, 8)
-#     And to ← point(0, 0, 0)
-#     And up ← vector(0, 1, 0)
-#   When t ← view_transform(from, to, up)
-#   Then t = translation(0, 0, -8)
-
-# Scenario: An arbitrary view transformation
-#   Given from ← point(1, 3, 2)
-#     And to ← point(4, -2, 8)
-#     And up ← vector(1, 1, 0)
-#   When t ← view_transform(from, to, up)
-#   Then t is the following 4x4 matrix:
-#       | -0.50709 | 0.50709 |  0.67612 | -2.36643 |
-#       |  0.76772 | 0.60609 |  0.12122 | -2.82843 |
-#       | -0.35857 | 0.59761 | -0.71714 |  0.00000 |
-#       |  0.00000 | 0.00000 |  0.00000 |  1.00000 |
+Scenario: A transformation chain applies operations in reading order
+  Given p ← point(1, 0, 1)
+  When t ← identity().rotate_x(π / 2).scale(5, 5, 5).translate(10, 5, 7)
+  Then t * p = point(15, 0, 7)
+
+Scenario: An empty transformation chain is the identity
+  When t ← identity()
+  Then t = identity_matrix
+
+Scenario: Reordering a transformation chain changes the result
+  Given p ← point(1, 0, 1)
+  When t ← identity().translate(10, 5, 7).scale(5, 5, 5).rotate_x(π / 2)
+  Then t * p = point(55, -40, 25)
+
+Scenario: Shearing in a transformation chain
+  Given p ← point(2, 3, 4)
+  When t ← identity().shear(1, 0, 0, 0, 0, 0).translate(0, 0, 1)
+  Then t * p = point(5, 3, 5)
+
+Scenario: Rotating around the y axis with an axis and angle
+  Given p ← point(0, 0, 1)
+  When t ← rotation_axis_angle(vector(0, 1, 0), π / 2)
+  Then t * p = point(1, 0, 0)
+
+Scenario: Rotating around a diagonal axis
+  Given p ← point(1, 0, 0)
+  When t ← rotation_axis_angle(vector(1, 1, 1), 2π / 3)
+  Then t * p = point(0, 1, 0)
+
+Scenario: An axis and angle rotation does not move points on the axis
+  Given p ← point(2, -3, 1)
+  When t ← rotation_axis_angle(vector(2, -3, 1), 1.2)
+  Then t * p = point(2, -3, 1)
+
+Scenario: Rotating around an arbitrary axis in a transformation chain
+  Given p ← point(1, 0, 0)
+  When t ← identity().rotate(vector(0, 0, 1), π / 2).translate(1, 0, 0)
+  Then t * p = point(1, 1, 0)
+
+Scenario: The transformation matrix for the default orientation
+  Given from ← point(0, 0, 0)
+    And to ← point(0, 0, -1)
+    And up ← vector(0, 1, 0)
+  When t ← view_transform(from, to, up)
+  Then t = identity_matrix
+
+Scenario: A view transformation matrix looking in positive z direction
+  Given from ← point(0, 0, 0)
+    And to ← point(0, 0, 1)
+    And up ← vector(0, 1, 0)
+  When t ← view_transform(from, to, up)
+  Then t = scaling(-1, 1, -1)
+
+Scenario: The view transformation moves the world
+  Given from ← point(0, 0, 8)
+    And to ← point(0, 0, 0)
+    And up ← vector(0, 1, 0)
+  When t ← view_transform(from, to, up)
+  Then t = translation(0, 0, -8)
+
+Scenario: An arbitrary view transformation
+  Given from ← point(1, 3, 2)
+    And to ← point(4, -2, 8)
+    And up ← vector(1, 1, 0)
+  When t ← view_transform(from, to, up)
+  Then t is the following 4x4 matrix:
+      | -0.50709 | 0.50709 |  0.67612 | -2.36643 |
+      |  0.76772 | 0.60609 |  0.12122 | -2.82843 |
+      | -0.35857 | 0.59761 | -0.71714 |  0.00000 |
+      |  0.00000 | 0.00000 |  0.00000 |  1.00000 |
+
+Scenario: Decomposing a transformation
+  Given t ← identity().scale(2, 3, 4).rotate_y(π / 2).translate(1, 2, 3)
+  When parts ← decompose(t)
+  Then parts.translation = vector(1, 2, 3)
+    And parts.scale = vector(2, 3, 4)
+    And parts.rotation = quaternion(√2/2, 0, √2/2, 0)
+
+Scenario: Decomposing the identity
+  Given t ← identity()
+  When parts ← decompose(t)
+  Then parts.translation = vector(0, 0, 0)
+    And parts.scale = vector(1, 1, 1)
+    And parts.rotation = quaternion(1, 0, 0, 0)
+
+Scenario: Decomposing a reflection gives a negative scale
+  Given t ← identity().scale(-1, 1, 1)
+  When parts ← decompose(t)
+  Then parts.scale = vector(-1, 1, 1)
+    And parts.rotation = quaternion(1, 0, 0, 0)
+
+Scenario: Composing a decomposition gives back the transformation
+  Given t ← identity().scale(1, 2, 0.5).rotate(vector(1, 2, 3), π / 3).translate(-4, 0, 2)
+    And p ← point(1, 1, 1)
+  When parts ← decompose(t)
+    And c ← compose(parts)
+  Then t * p = point(-4.42513, 2.04292, 2.94643)
+    And c * p = point(-4.42513, 2.04292, 2.94643)
+
+Scenario: A sheared transformation cannot be decomposed
+  Given t ← identity().shear(1, 0, 0, 0, 0, 0)
+  Then t cannot be decomposed
+
+Scenario: A transformation that flattens a dimension cannot be decomposed
+  Given t ← identity().scale(1, 0, 1)
+  Then t cannot be decomposed
+
+Scenario: Interpolating between two transformations
+  Given a ← identity()
+    And b ← identity().scale(3, 3, 3).rotate_z(π / 2).translate(2, 0, 0)
+    And p ← point(1, 0, 0)
+  When t ← interpolate(a, b, 0.5)
+  Then t * p = point(2.41421, 1.41421, 0)
@@ -3,58 +3,91 @@ package transformations
 import (
 	"math"
 	"rtt/matrix"
+	"rtt/tuple"
 )
 
-func Translation(x, y, z float64) *matrix.Matrix {
-	return matrix.FromValues([]float64{
+func Translation(x, y, z float64) matrix.Mat4 {
+	return matrix.Mat4{
 		1, 0, 0, x,
 		0, 1, 0, y,
 		0, 0, 1, z,
 		0, 0, 0, 1,
-	})
+	}
 }
 
-func Scaling(x, y, z float64) *matrix.Matrix {
-	return matrix.FromValues([]float64{
+func Scaling(x, y, z float64) matrix.Mat4 {
+	return matrix.Mat4{
 		x, 0, 0, 0,
 		0, y, 0, 0,
 		0, 0, z, 0,
 		0, 0, 0, 1,
-	})
+	}
 }
 
-func RotationX(r float64) *matrix.Matrix {
-	return matrix.FromValues([]float64{
+func RotationX(r float64) matrix.Mat4 {
+	return matrix.Mat4{
 		1, 0, 0, 0,
 		0, math.Cos(r), -math.Sin(r), 0,
 		0, math.Sin(r), math.Cos(r), 0,
 		0, 0, 0, 1,
-	})
+	}
 }
 
-func RotationY(r float64) *matrix.Matrix {
-	return matrix.FromValues([]float64{
+func RotationY(r float64) matrix.Mat4 {
+	return matrix.Mat4{
 		math.Cos(r), 0, math.Sin(r), 0,
 		0, 1, 0, 0,
 		-math.Sin(r), 0, math.Cos(r), 0,
 		0, 0, 0, 1,
-	})
+	}
 }
 
-func RotationZ(r float64) *matrix.Matrix {
-	return matrix.FromValues([]float64{
+func RotationZ(r float64) matrix.Mat4 {
+	return matrix.Mat4{
 		math.Cos(r), -math.Sin(r), 0, 0,
 		math.Sin(r), math.Cos(r), 0, 0,
 		0, 0, 1, 0,
 		0, 0, 0, 1,
-	})
+	}
 }
 
-func Shearing(xy, xz, yx, yz, zx, zy float64) *matrix.Matrix {
-	return matrix.FromValues([]float64{
+// RotationAxisAngle rotates by angle radians around axis, which need not be
+// normalized. Around the x, y and z axes it matches RotationX, RotationY and
+// RotationZ.
+func RotationAxisAngle(axis tuple.Vector, angle float64) matrix.Mat4 {
+	a := axis.Normalize()
+	c := math.Cos(angle)
+	s := math.Sin(angle)
+	t := 1 - c
+
+	return matrix.Mat4{
+		t*a.X*a.X + c, t*a.X*a.Y - s*a.Z, t*a.X*a.Z + s*a.Y, 0,
+		t*a.X*a.Y + s*a.Z, t*a.Y*a.Y + c, t*a.Y*a.Z - s*a.X, 0,
+		t*a.X*a.Z - s*a.Y, t*a.Y*a.Z + s*a.X, t*a.Z*a.Z + c, 0,
+		0, 0, 0, 1,
+	}
+}
+
+func Shearing(xy, xz, yx, yz, zx, zy float64) matrix.Mat4 {
+	return matrix.Mat4{
 		1, xy, xz, 0,
 		yx, 1, yz, 0,
 		zx, zy, 1, 0,
 		0, 0, 0, 1,
-	})
+	}
+}
+
+func ViewTransform(from, to tuple.Point, up tuple.Vector) matrix.Mat4 {
+	forward := to.Subtract(from).Normalize()
+	left := forward.Cross(up.Normalize())
+	trueUp := left.Cross(forward)
+
+	orientation := matrix.Mat4{
+		left.X, left.Y, left.Z, 0,
+		trueUp.X, trueUp.Y, trueUp.Z, 0,
+		-forward.X, -forward.Y, -forward.Z, 0,
+		0, 0, 0, 1,
+	}
+
+	return orientation.Multiply(Translation(-from.X, -from.Y, -from.Z))
 }
@@ -0,0 +1,47 @@
+package tuple
+
+import "rtt/shared"
+
+type Color struct {
+	R float64
+	G float64
+	B float64
+}
+
+func NewColor(r, g, b float64) Color {
+	return Color{R: r, G: g, B: b}
+}
+
+var Black = Color{}
+var White = Color{R: 1, G: 1, B: 1}
+var Red = Color{R: 1}
+
+func (c Color) Add(other Color) Color {
+	return Color{R: c.R + other.R, G: c.G + other.G, B: c.B + other.B}
+}
+
+func (c Color) Subtract(other Color) Color {
+	return Color{R: c.R - other.R, G: c.G - other.G, B: c.B - other.B}
+}
+
+func (c Color) ScalarMultiply(f float64) Color {
+	return Color{R: c.R * f, G: c.G * f, B: c.B * f}
+}
+
+func (c Color) ScalarDiv(f float64) Color {
+	return Color{R: c.R / f, G: c.G / f, B: c.B / f}
+}
+
+func (a Color) Hadamard(b Color) Color {
+	return Color{R: a.R * b.R, G: a.G * b.G, B: a.B * b.B}
+}
+
+// Tuple returns the colour as a tuple with W set to 0, the way colours are
+// written in the feature files.
+func (c Color) Tuple() *Tuple {
+	return &Tuple{X: c.R, Y: c.G, Z: c.B, W: 0}
+}
+
+func (c Color) Equals(other Color) bool {
+	return shared.CompareFloat(c.R, other.R) && shared.CompareFloat(c.G, other.G) && shared.CompareFloat(c.B, other.B)
+}
@@ -0,0 +1,37 @@
+package tuple
+
+import "rtt/shared"
+
+// Point is a position in space. Subtracting two points gives the Vector
+// between them, and points can only be moved by vectors, never added.
+type Point struct {
+	X float64
+	Y float64
+	Z float64
+}
+
+func NewPoint(x, y, z float64) Point {
+	return Point{X: x, Y: y, Z: z}
+}
+
+var ZeroPoint = Point{}
+
+func (p Point) Add(v Vector) Point {
+	return Point{X: p.X + v.X, Y: p.Y + v.Y, Z: p.Z + v.Z}
+}
+
+func (p Point) Subtract(other Point) Vector {
+	return Vector{X: p.X - other.X, Y: p.Y - other.Y, Z: p.Z - other.Z}
+}
+
+func (p Point) SubtractVector(v Vector) Point {
+	return Point{X: p.X - v.X, Y: p.Y - v.Y, Z: p.Z - v.Z}
+}
+
+func (p Point) Tuple() *Tuple {
+	return &Tuple{X: p.X, Y: p.Y, Z: p.Z, W: 1}
+}
+
+func (p Point) Equals(other Point) bool {
+	return shared.CompareFloat(p.X, other.X) && shared.CompareFloat(p.Y, other.Y) && shared.CompareFloat(p.Z, other.Z)
+}
@@ -5,6 +5,8 @@ import (
 	"rtt/shared"
 )
 
+// Tuple is a general four component tuple where W tells points (1) from
+// vectors (0). The renderer itself works with Point, Vector and Color.
 type Tuple struct {
 	X float64
 	Y float64
@@ -20,45 +22,6 @@ func (t *Tuple) IsVector() bool {
 	return t.W == 0
 }
 
-func Point(x, y, z float64) *Tuple {
-	return &Tuple{
-		X: x,
-		Y: y,
-		Z: z,
-		W: 1,
-	}
-}
-
-func Vector(x, y, z float64) *Tuple {
-	return &Tuple{
-		X: x,
-		Y: y,
-		Z: z,
-		W: 0,
-	}
-}
-
-func Color(r, g, b float64) *Tuple {
-	return &Tuple{
-		X: r,
-		Y: g,
-		Z: b,
-		W: 0,
-	}
-}
-
-func (t *Tuple) Red() float64 {
-	return t.X
-}
-
-func (t *Tuple) Green() float64 {
-	return t.Y
-}
-
-func (t *Tuple) Blue() float64 {
-	return t.Z
-}
-
 func (t *Tuple) Add(other *Tuple) *Tuple {
 	return &Tuple{
 		X: t.X + other.X,
@@ -125,30 +88,22 @@ func (t *Tuple) Dot(other *Tuple) float64 {
 		t.W*other.W
 }
 
-func (a *Tuple) Cross(b *Tuple) *Tuple {
-	return Vector(a.Y*b.Z-a.Z*b.Y,
-		a.Z*b.X-a.X*b.Z,
-		a.X*b.Y-a.Y*b.X)
+func (t *Tuple) AsPoint() Point {
+	return Point{X: t.X, Y: t.Y, Z: t.Z}
 }
 
-func (a *Tuple) Hadamard(b *Tuple) *Tuple {
-	red := a.Red() * b.Red()
-	green := a.Green() * b.Green()
-	blue := a.Blue() * b.Blue()
-	return Color(red, green, blue)
+func (t *Tuple) AsVector() Vector {
+	return Vector{X: t.X, Y: t.Y, Z: t.Z}
 }
 
-func (a *Tuple) Reflect(normal *Tuple) *Tuple {
-	doubleNormal := normal.ScalarMultiply(2)
-	dot := a.Dot(normal)
-	return a.Subtract(doubleNormal.ScalarMultiply(dot))
+func (t *Tuple) AsColor() Color {
+	return Color{R: t.X, G: t.Y, B: t.Z}
 }
 
 func CompareTuple(a, b *Tuple) bool {
-	return shared.CompareFloat(a.X, b.X) && shared.CompareFloat(a.Y, b.Y) && shared.CompareFloat(a.Z, b.Z) && shared.CompareFloat(a.W, b.W)
+	return CompareTupleWithin(a, b, shared.DefaultTolerance)
 }
 
-var White = Color(1, 1, 1)
-var Red = Color(1, 0, 0)
-
-var ZeroPoint = Point(0, 0, 0)
+func CompareTupleWithin(a, b *Tuple, t shared.Tolerance) bool {
+	return t.Equal(a.X, b.X) && t.Equal(a.Y, b.Y) && t.Equal(a.Z, b.Z) && t.Equal(a.W, b.W)
+}
@@ -0,0 +1,69 @@
+package tuple
+
+import (
+	"math"
+	"rtt/shared"
+)
+
+// Vector is a direction and length in space.
+type Vector struct {
+	X float64
+	Y float64
+	Z float64
+}
+
+func NewVector(x, y, z float64) Vector {
+	return Vector{X: x, Y: y, Z: z}
+}
+
+func (v Vector) Add(other Vector) Vector {
+	return Vector{X: v.X + other.X, Y: v.Y + other.Y, Z: v.Z + other.Z}
+}
+
+func (v Vector) Subtract(other Vector) Vector {
+	return Vector{X: v.X - other.X, Y: v.Y - other.Y, Z: v.Z - other.Z}
+}
+
+func (v Vector) Negate() Vector {
+	return Vector{X: -v.X, Y: -v.Y, Z: -v.Z}
+}
+
+func (v Vector) ScalarMultiply(f float64) Vector {
+	return Vector{X: v.X * f, Y: v.Y * f, Z: v.Z * f}
+}
+
+func (v Vector) ScalarDiv(f float64) Vector {
+	return Vector{X: v.X / f, Y: v.Y / f, Z: v.Z / f}
+}
+
+func (v Vector) Magnitude() float64 {
+	return math.Sqrt(v.X*v.X + v.Y*v.Y + v.Z*v.Z)
+}
+
+func (v Vector) Normalize() Vector {
+	return v.ScalarDiv(v.Magnitude())
+}
+
+func (v Vector) Dot(other Vector) float64 {
+	return v.X*other.X + v.Y*other.Y + v.Z*other.Z
+}
+
+func (a Vector) Cross(b Vector) Vector {
+	return Vector{
+		X: a.Y*b.Z - a.Z*b.Y,
+		Y: a.Z*b.X - a.X*b.Z,
+		Z: a.X*b.Y - a.Y*b.X,
+	}
+}
+
+func (v Vector) Reflect(normal Vector) Vector {
+	return v.Subtract(normal.ScalarMultiply(2 * v.Dot(normal)))
+}
+
+func (v Vector) Tuple() *Tuple {
+	return &Tuple{X: v.X, Y: v.Y, Z: v.Z, W: 0}
+}
+
+func (v Vector) Equals(other Vector) bool {
+	return shared.CompareFloat(v.X, other.X) && shared.CompareFloat(v.Y, other.Y) && shared.CompareFloat(v.Z, other.Z)
+}
@@ -9,8 +9,8 @@ import (
 	"github.com/cucumber/godog"
 )
 
-func doCompareTuple(ctx context.Context, variable string, x, y, z, w float64) error {
-	actual, ok := ctx.Value(sharedtest.Variables{Name: variable}).(*tuple.Tuple)
+func doCompareTuple(ctx context.Context, variable, operator string, x, y, z, w float64) error {
+	actual, ok := AsTuple(ctx, variable)
 
 	if !ok {
 		return fmt.Errorf("tuple %s is not set", variable)
@@ -23,20 +23,20 @@ func doCompareTuple(ctx context.Context, variable string, x, y, z, w float64) er
 		W: w,
 	}
 
-	if !tuple.CompareTuple(actual, &t) {
-		return fmt.Errorf("%+v was not %+v", actual, t)
+	if equal := tuple.CompareTuple(actual, &t); equal != (operator == "=") {
+		return fmt.Errorf("%+v %s %+v was false", actual, operator, t)
 	}
 
 	return nil
 }
 
 func AddCompareTuple(sc *godog.ScenarioContext) {
-	regex := fmt.Sprintf(`^(.+) = tuple\(%s, %s, %s, %s\)$`, sharedtest.Decimal, sharedtest.Decimal, sharedtest.Decimal, sharedtest.Decimal)
+	regex := fmt.Sprintf(`^(.+) (=|!=) tuple\(%s, %s, %s, %s\)$`, sharedtest.Decimal, sharedtest.Decimal, sharedtest.Decimal, sharedtest.Decimal)
 	sc.Step(regex, doCompareTuple)
 }
 
 func doCompareVector(ctx context.Context, variable string, xString, yString, zString string) error {
-	actual, ok := ctx.Value(sharedtest.Variables{Name: variable}).(*tuple.Tuple)
+	actual, ok := AsTuple(ctx, variable)
 
 	if !ok {
 		return fmt.Errorf("tuple %s is not set", variable)
@@ -47,7 +47,7 @@ func doCompareVector(ctx context.Context, variable string, xString, yString, zSt
 		return err
 	}
 
-	t := tuple.Vector(x, y, z)
+	t := tuple.NewVector(x, y, z).Tuple()
 
 	if !tuple.CompareTuple(actual, t) {
 		return fmt.Errorf("%+v was not %+v", actual, t)
@@ -62,13 +62,13 @@ func AddCompareVector(sc *godog.ScenarioContext) {
 }
 
 func doComparePoint(ctx context.Context, variable string, x, y, z float64) error {
-	actual, ok := ctx.Value(sharedtest.Variables{Name: variable}).(*tuple.Tuple)
+	actual, ok := AsTuple(ctx, variable)
 
 	if !ok {
 		return fmt.Errorf("tuple %s is not set", variable)
 	}
 
-	t := tuple.Point(x, y, z)
+	t := tuple.NewPoint(x, y, z).Tuple()
 
 	if !tuple.CompareTuple(actual, t) {
 		return fmt.Errorf("%+v was not %+v", actual, t)
@@ -83,13 +83,13 @@ func AddComparePoint(sc *godog.ScenarioContext) {
 }
 
 func doCompareColor(ctx context.Context, variable string, x, y, z float64) error {
-	actual, ok := ctx.Value(sharedtest.Variables{Name: variable}).(*tuple.Tuple)
+	actual, ok := AsTuple(ctx, variable)
 
 	if !ok {
 		return fmt.Errorf("tuple %s is not set", variable)
 	}
 
-	t := tuple.Color(x, y, z)
+	t := tuple.NewColor(x, y, z).Tuple()
 
 	if !tuple.CompareTuple(actual, t) {
 		return fmt.Errorf("%+v was not %+v", actual, t)
@@ -104,8 +104,8 @@ func AddCompareColor(sc *godog.ScenarioContext) {
 }
 
 func doCompareNormalize(ctx context.Context, variable string, expected string) error {
-	in := ctx.Value(sharedtest.Variables{Name: variable}).(*tuple.Tuple)
-	expectedTuple := ctx.Value(sharedtest.Variables{Name: expected}).(*tuple.Tuple)
+	in, _ := AsTuple(ctx, variable)
+	expectedTuple, _ := AsTuple(ctx, expected)
 
 	if !tuple.CompareTuple(in.Normalize(), expectedTuple) {
 		return fmt.Errorf("%+v normalized was %+v, not %+v", in, in.Normalize(), expectedTuple)
@@ -9,8 +9,25 @@ import (
 	"github.com/cucumber/godog"
 )
 
+// AsTuple returns the tuple, point, vector or colour stored in variable as a
+// plain tuple, so steps can compare any of them against a literal.
+func AsTuple(ctx context.Context, variable string) (*tuple.Tuple, bool) {
+	switch v := ctx.Value(sharedtest.Variables{Name: variable}).(type) {
+	case *tuple.Tuple:
+		return v, true
+	case tuple.Point:
+		return v.Tuple(), true
+	case tuple.Vector:
+		return v.Tuple(), true
+	case tuple.Color:
+		return v.Tuple(), true
+	default:
+		return nil, false
+	}
+}
+
 func doConstructColor(ctx context.Context, variable string, x, y, z float64) (context.Context, error) {
-	p := tuple.Color(x, y, z)
+	p := tuple.NewColor(x, y, z)
 	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, p), nil
 }
 
@@ -24,7 +41,7 @@ func doConstructPoint(ctx context.Context, variable string, xString, yString, zS
 	if err != nil {
 		return ctx, err
 	}
-	p := tuple.Point(x, y, z)
+	p := tuple.NewPoint(x, y, z)
 	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, p), nil
 }
 
@@ -38,7 +55,7 @@ func doConstructVector(ctx context.Context, variable string, xString, yString, z
 	if err != nil {
 		return ctx, err
 	}
-	v := tuple.Vector(x, y, z)
+	v := tuple.NewVector(x, y, z)
 	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, v), nil
 }
 
@@ -23,60 +23,159 @@ func aTuple(ctx context.Context, variable string, x, y, z, w float64) (context.C
 	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, &t), nil
 }
 
+func value(ctx context.Context, name string) any {
+	return ctx.Value(sharedtest.Variables{Name: name})
+}
+
+func unsupported(op string, left, right any) error {
+	return fmt.Errorf("cannot %s %T and %T", op, left, right)
+}
+
 func aNormalized(ctx context.Context, variable, in string) (context.Context, error) {
-	t := ctx.Value(sharedtest.Variables{Name: in}).(*tuple.Tuple)
-	t = t.Normalize()
-	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, t), nil
+	var result any
+
+	switch t := value(ctx, in).(type) {
+	case tuple.Vector:
+		result = t.Normalize()
+	case *tuple.Tuple:
+		result = t.Normalize()
+	default:
+		return ctx, fmt.Errorf("cannot normalize %T", t)
+	}
+
+	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, result), nil
 }
 
 func aReflect(ctx context.Context, variable, inName, normalName string) (context.Context, error) {
-	in := ctx.Value(sharedtest.Variables{Name: inName}).(*tuple.Tuple)
-	normal := ctx.Value(sharedtest.Variables{Name: normalName}).(*tuple.Tuple)
+	in := value(ctx, inName).(tuple.Vector)
+	normal := value(ctx, normalName).(tuple.Vector)
 	result := in.Reflect(normal)
 	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, result), nil
 }
 
 func add(ctx context.Context, assignee, left, right string) (context.Context, error) {
-	leftTuple := ctx.Value(sharedtest.Variables{Name: left}).(*tuple.Tuple)
-	rightTuple := ctx.Value(sharedtest.Variables{Name: right}).(*tuple.Tuple)
-	t := leftTuple.Add(rightTuple)
-	return context.WithValue(ctx, sharedtest.Variables{Name: assignee}, t), nil
+	var result any
+
+	switch l := value(ctx, left).(type) {
+	case *tuple.Tuple:
+		if r, ok := value(ctx, right).(*tuple.Tuple); ok {
+			result = l.Add(r)
+		}
+	case tuple.Point:
+		if r, ok := value(ctx, right).(tuple.Vector); ok {
+			result = l.Add(r)
+		}
+	case tuple.Vector:
+		if r, ok := value(ctx, right).(tuple.Vector); ok {
+			result = l.Add(r)
+		}
+	case tuple.Color:
+		if r, ok := value(ctx, right).(tuple.Color); ok {
+			result = l.Add(r)
+		}
+	}
+
+	if result == nil {
+		return ctx, unsupported("add", value(ctx, left), value(ctx, right))
+	}
+
+	return context.WithValue(ctx, sharedtest.Variables{Name: assignee}, result), nil
 }
 
 func subtract(ctx context.Context, assignee, left, right string) (context.Context, error) {
-	leftTuple := ctx.Value(sharedtest.Variables{Name: left}).(*tuple.Tuple)
-	rightTuple := ctx.Value(sharedtest.Variables{Name: right}).(*tuple.Tuple)
-	t := leftTuple.Subtract(rightTuple)
-	return context.WithValue(ctx, sharedtest.Variables{Name: assignee}, t), nil
+	var result any
+
+	switch l := value(ctx, left).(type) {
+	case *tuple.Tuple:
+		if r, ok := value(ctx, right).(*tuple.Tuple); ok {
+			result = l.Subtract(r)
+		}
+	case tuple.Point:
+		switch r := value(ctx, right).(type) {
+		case tuple.Point:
+			result = l.Subtract(r)
+		case tuple.Vector:
+			result = l.SubtractVector(r)
+		}
+	case tuple.Vector:
+		if r, ok := value(ctx, right).(tuple.Vector); ok {
+			result = l.Subtract(r)
+		}
+	case tuple.Color:
+		if r, ok := value(ctx, right).(tuple.Color); ok {
+			result = l.Subtract(r)
+		}
+	}
+
+	if result == nil {
+		return ctx, unsupported("subtract", value(ctx, left), value(ctx, right))
+	}
+
+	return context.WithValue(ctx, sharedtest.Variables{Name: assignee}, result), nil
 }
 
 func mul(ctx context.Context, assignee, left, right string) (context.Context, error) {
-	leftTuple := ctx.Value(sharedtest.Variables{Name: left}).(*tuple.Tuple)
-	rightTuple := ctx.Value(sharedtest.Variables{Name: right}).(*tuple.Tuple)
-	t := leftTuple.Hadamard(rightTuple)
-	return context.WithValue(ctx, sharedtest.Variables{Name: assignee}, t), nil
+	l, lok := value(ctx, left).(tuple.Color)
+	r, rok := value(ctx, right).(tuple.Color)
+
+	if !lok || !rok {
+		return ctx, unsupported("multiply", value(ctx, left), value(ctx, right))
+	}
+
+	return context.WithValue(ctx, sharedtest.Variables{Name: assignee}, l.Hadamard(r)), nil
 }
 
 func negate(ctx context.Context, assignee, in string) (context.Context, error) {
-	inTuple := ctx.Value(sharedtest.Variables{Name: in}).(*tuple.Tuple)
-	t := inTuple.Negate()
-	return context.WithValue(ctx, sharedtest.Variables{Name: assignee}, t), nil
+	var result any
+
+	switch t := value(ctx, in).(type) {
+	case *tuple.Tuple:
+		result = t.Negate()
+	case tuple.Vector:
+		result = t.Negate()
+	default:
+		return ctx, fmt.Errorf("cannot negate %T", t)
+	}
+
+	return context.WithValue(ctx, sharedtest.Variables{Name: assignee}, result), nil
 }
 
 func scalar_mul(ctx context.Context, assignee, in string, scalar float64) (context.Context, error) {
-	inTuple := ctx.Value(sharedtest.Variables{Name: in}).(*tuple.Tuple)
-	t := inTuple.ScalarMultiply(scalar)
-	return context.WithValue(ctx, sharedtest.Variables{Name: assignee}, t), nil
+	var result any
+
+	switch t := value(ctx, in).(type) {
+	case *tuple.Tuple:
+		result = t.ScalarMultiply(scalar)
+	case tuple.Vector:
+		result = t.ScalarMultiply(scalar)
+	case tuple.Color:
+		result = t.ScalarMultiply(scalar)
+	default:
+		return ctx, fmt.Errorf("cannot scale %T", t)
+	}
+
+	return context.WithValue(ctx, sharedtest.Variables{Name: assignee}, result), nil
 }
 
 func scalar_div(ctx context.Context, assignee, in string, scalar float64) (context.Context, error) {
-	inTuple := ctx.Value(sharedtest.Variables{Name: in}).(*tuple.Tuple)
-	t := inTuple.ScalarDiv(scalar)
-	return context.WithValue(ctx, sharedtest.Variables{Name: assignee}, t), nil
+	var result any
+
+	switch t := value(ctx, in).(type) {
+	case *tuple.Tuple:
+		result = t.ScalarDiv(scalar)
+	case tuple.Vector:
+		result = t.ScalarDiv(scalar)
+	case tuple.Color:
+		result = t.ScalarDiv(scalar)
+	default:
+		return ctx, fmt.Errorf("cannot divide %T", t)
+	}
+
+	return context.WithValue(ctx, sharedtest.Variables{Name: assignee}, result), nil
 }
 
 func compareMag(ctx context.Context, variable string, sqrt string, expected float64) error {
-	actual, ok := ctx.Value(sharedtest.Variables{variable}).(*tuple.Tuple)
+	actual, ok := AsTuple(ctx, variable)
 
 	if !ok {
 		return fmt.Errorf("tuple %s is not set", variable)
@@ -94,8 +193,8 @@ func compareMag(ctx context.Context, variable string, sqrt string, expected floa
 }
 
 func compareDot(ctx context.Context, left, right string, expected float64) error {
-	l := ctx.Value(sharedtest.Variables{Name: left}).(*tuple.Tuple)
-	r := ctx.Value(sharedtest.Variables{Name: right}).(*tuple.Tuple)
+	l := value(ctx, left).(tuple.Vector)
+	r := value(ctx, right).(tuple.Vector)
 
 	if l.Dot(r) != expected {
 		return fmt.Errorf("dot(%+v, %+v) was %f, not %f", l, r, l.Dot(r), expected)
@@ -105,11 +204,11 @@ func compareDot(ctx context.Context, left, right string, expected float64) error
 }
 
 func compareCross(ctx context.Context, left, right, expected string) error {
-	l := ctx.Value(sharedtest.Variables{Name: left}).(*tuple.Tuple)
-	r := ctx.Value(sharedtest.Variables{Name: right}).(*tuple.Tuple)
-	e := ctx.Value(sharedtest.Variables{Name: expected}).(*tuple.Tuple)
+	l := value(ctx, left).(tuple.Vector)
+	r := value(ctx, right).(tuple.Vector)
+	e := value(ctx, expected).(tuple.Vector)
 
-	if *l.Cross(r) != *e {
+	if l.Cross(r) != e {
 		return fmt.Errorf("cross(%+v, %+v) was %+v, not %+v", l, r, l.Cross(r), e)
 	}
 
@@ -117,7 +216,7 @@ func compareCross(ctx context.Context, left, right, expected string) error {
 }
 
 func aComponentEquals(ctx context.Context, variable string, component string, value float64) error {
-	tuple, ok := ctx.Value(sharedtest.Variables{variable}).(*tuple.Tuple)
+	tuple, ok := AsTuple(ctx, variable)
 
 	if !ok {
 		return fmt.Errorf("tuple [%s] is not set (will check component [%s] for value [%f])", variable, component, value)
@@ -134,11 +233,11 @@ func aComponentEquals(ctx context.Context, variable string, component string, va
 	case "w":
 		actual = tuple.W
 	case "red":
-		actual = tuple.Red()
+		actual = tuple.X
 	case "green":
-		actual = tuple.Green()
+		actual = tuple.Y
 	case "blue":
-		actual = tuple.Blue()
+		actual = tuple.Z
 	default:
 		return fmt.Errorf("Unknown component '%s'", component)
 	}
@@ -151,7 +250,7 @@ func aComponentEquals(ctx context.Context, variable string, component string, va
 }
 
 func aPointCheck(ctx context.Context, variable string, notA string) error {
-	tuple, ok := ctx.Value(sharedtest.Variables{variable}).(*tuple.Tuple)
+	tuple, ok := AsTuple(ctx, variable)
 
 	if !ok {
 		return fmt.Errorf("tuple %s is not set", variable)
@@ -169,7 +268,7 @@ func aPointCheck(ctx context.Context, variable string, notA string) error {
 }
 
 func aVectorCheck(ctx context.Context, variable string, notA string) error {
-	tuple, ok := ctx.Value(sharedtest.Variables{variable}).(*tuple.Tuple)
+	tuple, ok := AsTuple(ctx, variable)
 
 	if !ok {
 		return fmt.Errorf("tuple %s is not set", variable)
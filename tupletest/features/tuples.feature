@@ -32,6 +32,12 @@ Scenario: Adding two tuples
    When a3 = a1 + a2
    Then a3 = tuple(1, 1, 6, 1)
 
+Scenario: Moving a point by a vector
+  Given p ← point(3, -2, 5)
+    And v ← vector(-2, 3, 1)
+    When q = p + v
+  Then q = point(1, 1, 6)
+
 Scenario: Subtracting two points
   Given p1 ← point(3, 2, 1)
     And p2 ← point(5, 6, 7)
@@ -165,3 +171,11 @@ Scenario: Reflecting a vector off a slanted surface
     And n ← vector(√2/2, √2/2, 0)
   When r ← reflect(v, n)
   Then r = vector(1, 0, 0)
+
+Scenario: Large tuples are compared relative to their size
+  Given a ← point(4000000000, -2000000000, 1000000000)
+  Then a = tuple(4000000001, -2000000001, 1000000000.5, 1)
+
+Scenario: Small differences in large tuples are still noticed
+  Given a ← point(4000000000, -2000000000, 1000000000)
+  Then a != tuple(4000000100, -2000000000, 1000000000, 1)
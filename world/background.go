@@ -0,0 +1,94 @@
+package world
+
+import (
+	"math"
+	"rtt/canvas"
+	"rtt/tuple"
+)
+
+// Background is what a ray that misses every object sees, which depends
+// only on the direction of the ray.
+type Background interface {
+	ColorAt(direction tuple.Vector) tuple.Color
+}
+
+type FlatBackground struct {
+	Color tuple.Color
+}
+
+func NewFlatBackground(color tuple.Color) *FlatBackground {
+	return &FlatBackground{Color: color}
+}
+
+func (b *FlatBackground) ColorAt(direction tuple.Vector) tuple.Color {
+	return b.Color
+}
+
+// Sky fades from Horizon to Zenith above the horizon, and from Horizon to
+// Ground below it, with the sine of the angle to the horizon.
+type Sky struct {
+	Zenith  tuple.Color
+	Horizon tuple.Color
+	Ground  tuple.Color
+}
+
+func NewSky(zenith, horizon, ground tuple.Color) *Sky {
+	return &Sky{
+		Zenith:  zenith,
+		Horizon: horizon,
+		Ground:  ground,
+	}
+}
+
+func (s *Sky) ColorAt(direction tuple.Vector) tuple.Color {
+	y := direction.Normalize().Y
+	if y >= 0 {
+		return s.Horizon.ScalarMultiply(1 - y).Add(s.Zenith.ScalarMultiply(y))
+	}
+	return s.Horizon.ScalarMultiply(1 + y).Add(s.Ground.ScalarMultiply(-y))
+}
+
+// EnvironmentMap wraps an equirectangular image around the world. Its
+// columns run all the way around the y axis, with the middle column
+// straight along +z, and its rows from straight up at the top to straight
+// down at the bottom. Colours are blended between the nearest pixels.
+type EnvironmentMap struct {
+	Image *canvas.Canvas
+}
+
+func NewEnvironmentMap(image *canvas.Canvas) *EnvironmentMap {
+	return &EnvironmentMap{Image: image}
+}
+
+func (e *EnvironmentMap) ColorAt(direction tuple.Vector) tuple.Color {
+	d := direction.Normalize()
+	u := 0.5 + math.Atan2(d.X, d.Z)/(2*math.Pi)
+	v := math.Acos(math.Max(-1, math.Min(1, d.Y))) / math.Pi
+
+	return e.bilinear(u*float64(e.Image.Width)-0.5, v*float64(e.Image.Height)-0.5)
+}
+
+// bilinear blends the four pixels around x and y, measured in pixels from
+// the centre of the top left one. Columns wrap around, and rows stop at the
+// top and bottom of the image.
+func (e *EnvironmentMap) bilinear(x, y float64) tuple.Color {
+	width, height := e.Image.Width, e.Image.Height
+
+	x0, y0 := math.Floor(x), math.Floor(y)
+	fx, fy := x-x0, y-y0
+
+	column := func(i int32) int32 {
+		return ((i % width) + width) % width
+	}
+	row := func(i int32) int32 {
+		return max(0, min(height-1, i))
+	}
+
+	left, right := column(int32(x0)), column(int32(x0)+1)
+	top, bottom := row(int32(y0)), row(int32(y0)+1)
+
+	upper := e.Image.PixelAt(left, top).ScalarMultiply(1 - fx).Add(e.Image.PixelAt(right, top).ScalarMultiply(fx))
+	lower := e.Image.PixelAt(left, bottom).ScalarMultiply(1 - fx).Add(e.Image.PixelAt(right, bottom).ScalarMultiply(fx))
+
+	return upper.ScalarMultiply(1 - fy).Add(lower.ScalarMultiply(fy))
+}
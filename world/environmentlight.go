@@ -0,0 +1,159 @@
+package world
+
+import (
+	"math"
+	"math/rand"
+	"rtt/ray"
+	"rtt/tuple"
+	"sort"
+)
+
+// EnvironmentLight lights the world from an environment map, as though the
+// map surrounded it at an infinite distance. It is shaded with one
+// direction from each cell of a USteps by VSteps grid, laid over the
+// luminance of the map rather than over its area, so that bright parts of
+// the map such as the sun get most of the samples.
+//
+// JitterBy, if set, places the samples within their cells in place of the
+// rng they are sampled with.
+//
+// The map is taken to be flat over each of its pixels, which may differ a
+// little from the blended colours seen when it is the background.
+type EnvironmentLight struct {
+	Map      *EnvironmentMap
+	USteps   int
+	VSteps   int
+	Samples  int
+	JitterBy func() float64
+
+	// rows holds the running total of the weights of the rows of the map,
+	// and columns that of the pixels along each row, starting from 0. The
+	// weight of a pixel is its luminance times the solid angle it covers.
+	rows    []float64
+	columns [][]float64
+	// mean is the radiance of the map averaged over every direction, and
+	// direction the way that most of its light comes from.
+	mean      tuple.Color
+	direction tuple.Vector
+}
+
+func NewEnvironmentLight(environment *EnvironmentMap, usteps, vsteps int) *EnvironmentLight {
+	image := environment.Image
+	width, height := int(image.Width), int(image.Height)
+
+	l := &EnvironmentLight{
+		Map:     environment,
+		USteps:  usteps,
+		VSteps:  vsteps,
+		Samples: usteps * vsteps,
+		rows:    make([]float64, height+1),
+		columns: make([][]float64, height),
+	}
+
+	sum := tuple.Black
+	towards := tuple.NewVector(0, 0, 0)
+
+	for y := 0; y < height; y++ {
+		solidAngle := l.pixelSolidAngle(y)
+		l.columns[y] = make([]float64, width+1)
+
+		for x := 0; x < width; x++ {
+			radiance := image.PixelAt(int32(x), int32(y))
+			weight := luminance(radiance) * solidAngle
+
+			l.columns[y][x+1] = l.columns[y][x] + weight
+			sum = sum.Add(radiance.ScalarMultiply(solidAngle))
+
+			cosTheta := (l.cosTheta(y) + l.cosTheta(y+1)) / 2
+			towards = towards.Add(l.directionAt((float64(x)+0.5)/float64(width), cosTheta).ScalarMultiply(weight))
+		}
+
+		l.rows[y+1] = l.rows[y] + l.columns[y][width]
+	}
+
+	l.mean = sum.ScalarDiv(4 * math.Pi)
+	l.direction = tuple.NewVector(0, 1, 0)
+	if towards.Magnitude() > 0 {
+		l.direction = towards.Normalize()
+	}
+
+	return l
+}
+
+// luminance is how bright a colour looks, weighting its components as the
+// eye does.
+func luminance(c tuple.Color) float64 {
+	return 0.2126*c.R + 0.7152*c.G + 0.0722*c.B
+}
+
+// cosTheta is the cosine of the angle from straight up to the top edge of
+// row y of the map.
+func (l *EnvironmentLight) cosTheta(y int) float64 {
+	return math.Cos(math.Pi * float64(y) / float64(l.Map.Image.Height))
+}
+
+func (l *EnvironmentLight) pixelSolidAngle(y int) float64 {
+	return 2 * math.Pi / float64(l.Map.Image.Width) * (l.cosTheta(y) - l.cosTheta(y+1))
+}
+
+// directionAt turns a fraction u of the way across the map and the cosine
+// of the angle from straight up into a direction, the inverse of the
+// lookup of EnvironmentMap.ColorAt.
+func (l *EnvironmentLight) directionAt(u, cosTheta float64) tuple.Vector {
+	phi := (u - 0.5) * 2 * math.Pi
+	sinTheta := math.Sqrt(math.Max(0, 1-cosTheta*cosTheta))
+	return tuple.NewVector(sinTheta*math.Sin(phi), cosTheta, sinTheta*math.Cos(phi))
+}
+
+// pick finds the interval of totals, a running total starting from 0, that
+// u of the way through the whole falls in, and how far through that
+// interval it is.
+func pick(totals []float64, u float64) (int, float64) {
+	target := u * totals[len(totals)-1]
+	i := sort.Search(len(totals)-1, func(i int) bool { return totals[i+1] > target })
+	i = min(i, len(totals)-2)
+
+	return i, (target - totals[i]) / (totals[i+1] - totals[i])
+}
+
+func (l *EnvironmentLight) DirectionFrom(point tuple.Point) tuple.Vector {
+	return l.direction
+}
+
+func (l *EnvironmentLight) DistanceFrom(point tuple.Point) float64 {
+	return math.Inf(1)
+}
+
+func (l *EnvironmentLight) IntensityAt(point tuple.Point) tuple.Color {
+	return l.mean
+}
+
+// Sample picks a pixel with a probability that follows its weight and a
+// direction spread evenly over the solid angle of the pixel. The intensity
+// of each sample is its radiance over π times its probability density, so
+// that averaging the samples gives the light falling on a point.
+func (l *EnvironmentLight) Sample(rng *rand.Rand) []ray.Light {
+	power := l.rows[len(l.rows)-1]
+	if power == 0 {
+		return []ray.Light{ray.NewDirectionalLight(l.direction.Negate(), tuple.Black)}
+	}
+
+	samples := make([]ray.Light, 0, l.Samples)
+
+	for v := 0; v < l.VSteps; v++ {
+		for u := 0; u < l.USteps; u++ {
+			y, fy := pick(l.rows, (float64(v)+ray.Jitter(l.JitterBy, rng))/float64(l.VSteps))
+			x, fx := pick(l.columns[y], (float64(u)+ray.Jitter(l.JitterBy, rng))/float64(l.USteps))
+
+			cosTheta := l.cosTheta(y) + fy*(l.cosTheta(y+1)-l.cosTheta(y))
+			direction := l.directionAt((float64(x)+fx)/float64(l.Map.Image.Width), cosTheta)
+
+			radiance := l.Map.Image.PixelAt(int32(x), int32(y))
+			intensity := radiance.ScalarMultiply(power / (math.Pi * luminance(radiance)))
+
+			samples = append(samples, ray.NewDirectionalLight(direction.Negate(), intensity))
+		}
+	}
+
+	return samples
+}
@@ -0,0 +1,558 @@
+Feature: World
+
+Scenario: Creating a world
+  Given w ← world()
+  Then w contains no objects
+    And w has no light source
+
+Scenario: The default world
+  Given light ← point_light(point(-10, 10, -10), color(1, 1, 1))
+    And w ← default_world()
+  Then w.light = light
+    And w.objects.count = 2
+
+Scenario: Intersect a world with a ray
+  Given w ← default_world()
+    And r ← ray(point(0, 0, -5), vector(0, 0, 1))
+  When xs ← intersect_world(w, r)
+  Then xs.count = 4
+    And xs[0].t = 4
+    And xs[1].t = 4.5
+    And xs[2].t = 5.5
+    And xs[3].t = 6
+
+Scenario: Precomputing the state of an intersection
+  Given w ← default_world()
+    And r ← ray(point(0, 0, -5), vector(0, 0, 1))
+    And shape ← the first object in w
+    And i ← intersection(4, shape)
+  When comps ← prepare_computations(w, i, r)
+  Then comps.t = 4
+    And comps.object = shape
+    And comps.point = point(0, 0, -1)
+    And comps.eyev = vector(0, 0, -1)
+    And comps.normalv = vector(0, 0, -1)
+    And comps.inside = false
+
+Scenario: The hit, when an intersection occurs on the inside
+  Given w ← default_world()
+    And r ← ray(point(0, 0, 0), vector(0, 0, 1))
+    And shape ← the second object in w
+    And i ← intersection(0.5, shape)
+  When comps ← prepare_computations(w, i, r)
+  Then comps.point = point(0, 0, 0.5)
+    And comps.eyev = vector(0, 0, -1)
+    And comps.inside = true
+    And comps.normalv = vector(0, 0, -1)
+
+Scenario: The hit on a moving sphere is where it is at the ray's time
+  Given w ← world()
+    And shape ← sphere() moving by translation(2, 0, 0) in w
+    And r ← ray(point(2, 0, -5), vector(0, 0, 1))
+    And r.time ← 1
+  When xs ← intersect_world(w, r)
+    And i ← intersection(4, shape)
+    And comps ← prepare_computations(w, i, r)
+  Then xs.count = 2
+    And xs[0].t = 4
+    And comps.point = point(2, 0, -1)
+    And comps.normalv = vector(0, 0, -1)
+
+Scenario: The hit should offset the point
+  Given w ← world()
+    And r ← ray(point(0, 0, -5), vector(0, 0, 1))
+    And shape ← sphere() with translation(0, 0, 1) in w
+    And i ← intersection(5, shape)
+  When comps ← prepare_computations(w, i, r)
+  Then comps.over_point.z < -EPSILON/2
+    And comps.point.z > comps.over_point.z
+
+Scenario: The hit offset is configured per world
+  Given w ← world()
+    And w.surface_offset ← 0.01
+    And r ← ray(point(0, 0, -5), vector(0, 0, 1))
+    And shape ← sphere() with translation(0, 0, 1) in w
+    And i ← intersection(5, shape)
+  When comps ← prepare_computations(w, i, r)
+  Then comps.point = point(0, 0, 0)
+    And comps.over_point = point(0, 0, -0.01)
+
+Scenario: Shading an intersection
+  Given w ← default_world()
+    And r ← ray(point(0, 0, -5), vector(0, 0, 1))
+    And shape ← the first object in w
+    And i ← intersection(4, shape)
+  When comps ← prepare_computations(w, i, r)
+    And c ← shade_hit(w, comps)
+  Then c = color(0.38066, 0.47583, 0.2855)
+
+Scenario: Shading an intersection takes the colour of the pattern there
+  Given w ← default_world()
+    And r ← ray(point(0, 0, -5), vector(0, 0, 1))
+    And shape ← the first object in w
+    And shape.material.color ← color(0, 0, 0)
+    And shape.material.pattern ← marble(color(0.8, 1, 0.6), color(0.8, 1, 0.6))
+    And i ← intersection(4, shape)
+  When comps ← prepare_computations(w, i, r)
+    And c ← shade_hit(w, comps)
+  Then c = color(0.38066, 0.47583, 0.2855)
+
+Scenario: Shading an intersection from the inside
+  Given w ← default_world()
+    And w.light ← point_light(point(0, 0.25, 0), color(1, 1, 1))
+    And r ← ray(point(0, 0, 0), vector(0, 0, 1))
+    And shape ← the second object in w
+    And i ← intersection(0.5, shape)
+  When comps ← prepare_computations(w, i, r)
+    And c ← shade_hit(w, comps)
+  Then c = color(0.90498, 0.90498, 0.90498)
+
+Scenario: The color when a ray misses
+  Given w ← default_world()
+    And r ← ray(point(0, 0, -5), vector(0, 1, 0))
+  When c ← color_at(w, r)
+  Then c = color(0, 0, 0)
+
+Scenario: The color when a ray hits
+  Given w ← default_world()
+    And r ← ray(point(0, 0, -5), vector(0, 0, 1))
+  When c ← color_at(w, r)
+  Then c = color(0.38066, 0.47583, 0.2855)
+
+Scenario: The color with an intersection behind the ray
+  Given w ← default_world()
+    And outer ← the first object in w
+    And outer.material.ambient ← 1
+    And inner ← the second object in w
+    And inner.material.ambient ← 1
+    And r ← ray(point(0, 0, 0.75), vector(0, 0, -1))
+  When c ← color_at(w, r)
+  Then c = inner.material.color
+
+Scenario: There is no shadow when nothing is collinear with point and light
+  Given w ← default_world()
+    And p ← point(0, 10, 0)
+   Then is_shadowed(w, p) is false
+
+Scenario: The shadow when an object is between the point and the light
+  Given w ← default_world()
+    And p ← point(10, -10, 10)
+   Then is_shadowed(w, p) is true
+
+Scenario: Objects that do not cast shadows let the light through
+  Given w ← default_world()
+    And outer ← the first object in w
+    And inner ← the second object in w
+    And outer.material.casts_shadow ← false
+    And inner.material.casts_shadow ← false
+    And p ← point(10, -10, 10)
+   Then is_shadowed(w, p) is false
+
+Scenario: An object that casts shadows still shades a point behind one that does not
+  Given w ← default_world()
+    And outer ← the first object in w
+    And outer.material.casts_shadow ← false
+    And p ← point(10, -10, 10)
+   Then is_shadowed(w, p) is true
+
+Scenario: Any hit in the world stops at the maximum distance
+  Given w ← default_world()
+    And r ← ray(point(0, 0, -5), vector(0, 0, 1))
+  Then any_hit(w, r, 3.9) is false
+    And any_hit(w, r, 4.1) is true
+
+Scenario: Objects that do not cast shadows are not hit by shadow queries
+  Given w ← default_world()
+    And outer ← the first object in w
+    And outer.material.casts_shadow ← false
+    And r ← ray(point(0, 0, -5), vector(0, 0, 1))
+  Then any_hit(w, r, 4.1) is false
+    And any_hit(w, r, 4.6) is true
+
+Scenario: There is no shadow when an object is behind the light
+  Given w ← default_world()
+    And p ← point(-20, 20, -20)
+   Then is_shadowed(w, p) is false
+
+Scenario: There is no shadow when an object is behind the point
+  Given w ← default_world()
+    And p ← point(-2, 2, -2)
+   Then is_shadowed(w, p) is false
+
+Scenario: shade_hit() is given an intersection in shadow
+  Given w ← world()
+    And w.light ← point_light(point(0, 0, -10), color(1, 1, 1))
+    And s1 ← sphere() in w
+    And s2 ← sphere() with translation(0, 0, 10) in w
+    And r ← ray(point(0, 0, 5), vector(0, 0, 1))
+    And i ← intersection(4, s2)
+  When comps ← prepare_computations(w, i, r)
+    And c ← shade_hit(w, comps)
+  Then c = color(0.1, 0.1, 0.1)
+
+Scenario: The reflected color for a nonreflective material
+  Given w ← default_world()
+    And r ← ray(point(0, 0, 0), vector(0, 0, 1))
+    And shape ← the second object in w
+    And shape.material.ambient ← 1
+    And i ← intersection(1, shape)
+  When comps ← prepare_computations(w, i, r)
+    And c ← reflected_color(w, comps)
+  Then c = color(0, 0, 0)
+
+Scenario: The reflected color for a reflective material
+  Given w ← default_world()
+    And shape ← sphere() with translation(0, -2, 0) in w
+    And shape.material.reflective ← 0.5
+    And r ← ray(point(0, -1.2928932, -5), vector(0, 0, 1))
+    And i ← intersection(4.2928932, shape)
+  When comps ← prepare_computations(w, i, r)
+    And c ← reflected_color(w, comps)
+  Then c = color(0.04, 0.05, 0.03)
+
+Scenario: shade_hit() with a reflective material
+  Given w ← default_world()
+    And shape ← sphere() with translation(0, -2, 0) in w
+    And shape.material.reflective ← 0.5
+    And r ← ray(point(0, -1.2928932, -5), vector(0, 0, 1))
+    And i ← intersection(4.2928932, shape)
+  When comps ← prepare_computations(w, i, r)
+    And c ← shade_hit(w, comps)
+  Then c = color(0.87945, 0.88945, 0.86945)
+
+Scenario: color_at() with mutually reflective surfaces
+  Given w ← world()
+    And w.light ← point_light(point(0, 0, 0), color(1, 1, 1))
+    And lower ← sphere() with translation(0, -1001, 0) in w
+    And lower.material.reflective ← 1
+    And upper ← sphere() with translation(0, 1001, 0) in w
+    And upper.material.reflective ← 1
+    And r ← ray(point(0, 0, 0), vector(0, 1, 0))
+  Then color_at(w, r) should terminate successfully
+
+Scenario: The reflected color at the maximum recursive depth
+  Given w ← default_world()
+    And shape ← sphere() with translation(0, -2, 0) in w
+    And shape.material.reflective ← 0.5
+    And r ← ray(point(0, -1.2928932, -5), vector(0, 0, 1))
+    And i ← intersection(4.2928932, shape)
+  When comps ← prepare_computations(w, i, r)
+    And c ← reflected_color(w, comps, 0)
+  Then c = color(0, 0, 0)
+
+Scenario Outline: is_shadowed tests for occlusion between two points
+  Given w ← default_world()
+    And light_position ← point(-10, -10, -10)
+    And p ← <point>
+  Then is_shadowed(w, light_position, p) is <result>
+
+  Examples:
+    | point                | result |
+    | point(-10, -10, 10)  | false  |
+    | point(10, 10, 10)    | true   |
+    | point(-20, -20, -20) | false  |
+    | point(-5, -5, -5)    | false  |
+
+Scenario Outline: Point lights evaluate the light intensity at a given point
+  Given w ← default_world()
+    And light ← w.light
+    And pt ← <point>
+  When intensity ← intensity_at(light, pt, w)
+  Then intensity = <result>
+
+  Examples:
+    | point                | result |
+    | point(0, 1.0001, 0)  | 1.0    |
+    | point(-1.0001, 0, 0) | 1.0    |
+    | point(0, 0, -1.0001) | 1.0    |
+    | point(0, 0, 1.0001)  | 0.0    |
+    | point(1.0001, 0, 0)  | 0.0    |
+    | point(0, -1.0001, 0) | 0.0    |
+    | point(0, 0, 0)       | 0.0    |
+
+Scenario Outline: Directional lights are shadowed by anything along their direction
+  Given w ← default_world()
+    And light ← directional_light(vector(0, -1, 0), color(1, 1, 1))
+    And pt ← <point>
+  When intensity ← intensity_at(light, pt, w)
+  Then intensity = <result>
+
+  Examples:
+    | point             | result |
+    | point(0, 2, 0)    | 1.0    |
+    | point(0, -2, 0)   | 0.0    |
+    | point(0, -100, 0) | 0.0    |
+    | point(2, -2, 0)   | 1.0    |
+
+Scenario Outline: The area light intensity function
+  Given w ← default_world()
+    And light ← area_light(point(-0.5, -0.5, -5), vector(1, 0, 0), 2, vector(0, 1, 0), 2, color(1, 1, 1))
+    And light.jitter_by ← sequence(0.5)
+    And pt ← <point>
+  When intensity ← intensity_at(light, pt, w)
+  Then intensity = <result>
+
+  Examples:
+    | point                | result |
+    | point(0, 0, 2)       | 0.0    |
+    | point(1, -1, 2)      | 0.25   |
+    | point(1.5, 0, 2)     | 0.5    |
+    | point(1.25, 1.25, 3) | 0.75   |
+    | point(0, 0, -2)      | 1.0    |
+
+Scenario Outline: The area light with jittered samples
+  Given w ← default_world()
+    And light ← area_light(point(-0.5, -0.5, -5), vector(1, 0, 0), 2, vector(0, 1, 0), 2, color(1, 1, 1))
+    And light.jitter_by ← sequence(0.7, 0.3, 0.9, 0.1, 0.5)
+    And pt ← <point>
+  When intensity ← intensity_at(light, pt, w)
+  Then intensity = <result>
+
+  Examples:
+    | point                | result |
+    | point(0, 0, 2)       | 0.0    |
+    | point(1, -1, 2)      | 0.5    |
+    | point(1.5, 0, 2)     | 0.75   |
+    | point(1.25, 1.25, 3) | 0.75   |
+    | point(0, 0, -2)      | 1.0    |
+
+Scenario: shade_hit() samples the area light
+  Given w ← world()
+    And light ← area_light(point(-0.5, -0.5, -5), vector(1, 0, 0), 2, vector(0, 1, 0), 2, color(1, 1, 1))
+    And light.jitter_by ← sequence(0.5)
+    And w has light light
+    And shape ← sphere() in w
+    And shape.material.specular ← 0
+    And r ← ray(point(0, 0, -5), vector(0, 0, 1))
+    And i ← intersection(4, shape)
+  When comps ← prepare_computations(w, i, r)
+    And c ← shade_hit(w, comps)
+  Then c = color(0.9965, 0.9965, 0.9965)
+
+Scenario: Worlds are shaded with the Whitted integrator by default
+  Given w ← world()
+  Then w.integrator = whitted
+
+Scenario: Parsing an unknown integrator
+  Then parsing the integrator "photon" fails with "unknown integrator \"photon\", expected whitted or path"
+
+Scenario: An emissive surface glows under Whitted shading
+  Given w ← world()
+    And shape ← sphere() in w
+    And shape.material.emission ← color(0.5, 0.25, 0)
+    And shape.material.ambient ← 0
+    And r ← ray(point(0, 0, -5), vector(0, 0, 1))
+  When c ← color_at(w, r)
+  Then c = color(0.5, 0.25, 0)
+
+Scenario: The Whitted integrator gives the same colour as color_at
+  Given w ← default_world()
+    And r ← ray(point(0, 0, -5), vector(0, 0, 1))
+  When c ← radiance(w, r)
+  Then c = color(0.38066, 0.47583, 0.2855)
+
+Scenario: A path that misses everything is black
+  Given w ← default_world()
+    And w.integrator ← path
+    And r ← ray(point(0, 0, -5), vector(0, 1, 0))
+  When c ← radiance(w, r)
+  Then c = color(0, 0, 0)
+
+Scenario: Path tracing replaces the ambient term with gathered light
+  Given w ← default_world()
+    And w.integrator ← path
+    And r ← ray(point(0, 0, -5), vector(0, 0, 1))
+  When c ← radiance(w, r)
+  Then c = color(0.30066, 0.37583, 0.2255)
+
+Scenario: Path tracing sees emissive surfaces
+  Given w ← world()
+    And w.integrator ← path
+    And shape ← sphere() in w
+    And shape.material.emission ← color(1, 0.5, 0)
+    And r ← ray(point(0, 0, -5), vector(0, 0, 1))
+  When c ← radiance(w, r)
+  Then c = color(1, 0.5, 0)
+
+Scenario: Light bounces between diffuse surfaces
+  Given w ← world()
+    And w.integrator ← path
+    And w.max_depth ← 2
+    And shape ← sphere() in w
+    And shape.material.color ← color(1, 1, 1)
+    And shape.material.diffuse ← 0.5
+    And shape.material.emission ← color(1, 1, 1)
+    And r ← ray(point(0, 0, 0), vector(0, 0, 1))
+  When c ← radiance(w, r)
+  Then c = color(1.75, 1.75, 1.75)
+
+Scenario: Russian roulette keeps long paths unbiased
+  Given w ← world()
+    And w.integrator ← path
+    And w.max_depth ← 50
+    And shape ← sphere() in w
+    And shape.material.color ← color(1, 1, 1)
+    And shape.material.diffuse ← 0.5
+    And shape.material.emission ← color(1, 1, 1)
+    And r ← ray(point(0, 0, 0), vector(0, 0, 1))
+  When c ← radiance(w, r) averaged over 4000 paths
+  Then c = color(2, 2, 2) within 0.05
+
+Scenario Outline: A shape light is not shadowed by its own shape
+  Given w ← world()
+    And lamp ← sphere() with translation(0, 5, 0) in w
+    And lamp.material.emission ← color(1, 1, 1)
+    And light ← shape_light(lamp, 4, 4)
+    And w has light light
+    And blocker ← sphere() with translation(<x>, 2.5, 0) in w
+    And pt ← point(0, 0, 0)
+  When intensity ← intensity_at(light, pt, w)
+  Then intensity = <result>
+
+  Examples:
+    | x | result |
+    | 3 | 1.0    |
+    | 0 | 0.0    |
+
+Scenario: Path tracing gathers light bounced onto a glowing shape
+  Given w ← world()
+    And w.integrator ← path
+    And w.max_depth ← 1
+    And room ← sphere() with scaling(3, 3, 3) in w
+    And room.material.diffuse ← 0.5
+    And room.material.specular ← 0
+    And lamp ← sphere() with translation(0, 1.5, 0) in w
+    And lamp.material.emission ← color(4, 4, 4)
+    And r ← ray(point(0, 0, 0), vector(0, -1, 0))
+  When c ← radiance(w, r) averaged over 20000 paths
+  Then c = color(0.09877, 0.09877, 0.09877) within 0.01
+
+Scenario: Path tracing counts a shape light once
+  Given w ← world()
+    And w.integrator ← path
+    And w.max_depth ← 1
+    And room ← sphere() with scaling(3, 3, 3) in w
+    And room.material.diffuse ← 0.5
+    And room.material.specular ← 0
+    And lamp ← sphere() with translation(0, 1.5, 0) in w
+    And lamp.material.emission ← color(4, 4, 4)
+    And light ← shape_light(lamp, 16, 16)
+    And w has light light
+    And r ← ray(point(0, 0, 0), vector(0, -1, 0))
+  When c ← radiance(w, r) averaged over 100 paths
+  Then c = color(0.09877, 0.09877, 0.09877) within 0.01
+
+Scenario: A shape light still glows when seen directly
+  Given w ← world()
+    And w.integrator ← path
+    And lamp ← sphere() in w
+    And lamp.material.emission ← color(4, 4, 4)
+    And light ← shape_light(lamp, 2, 2)
+    And w has light light
+    And r ← ray(point(0, 0, -5), vector(0, 0, 1))
+  When c ← radiance(w, r)
+  Then c = color(4, 4, 4)
+
+Scenario: A ray that misses everything sees the background
+  Given w ← default_world()
+    And w.background ← background(color(0.2, 0.3, 0.5))
+    And r ← ray(point(0, 0, -5), vector(0, 1, 0))
+  When c ← color_at(w, r)
+  Then c = color(0.2, 0.3, 0.5)
+
+Scenario: A mirror reflects the background
+  Given w ← world()
+    And w.background ← background(color(0.2, 0.3, 0.5))
+    And shape ← sphere() in w
+    And shape.material.color ← color(0, 0, 0)
+    And shape.material.ambient ← 0
+    And shape.material.reflective ← 1
+    And r ← ray(point(0, 0, -5), vector(0, 0, 1))
+  When c ← color_at(w, r)
+  Then c = color(0.2, 0.3, 0.5)
+
+Scenario: A path that misses everything sees the background
+  Given w ← default_world()
+    And w.integrator ← path
+    And w.background ← background(color(0.2, 0.3, 0.5))
+    And r ← ray(point(0, 0, -5), vector(0, 1, 0))
+  When c ← radiance(w, r)
+  Then c = color(0.2, 0.3, 0.5)
+
+Scenario Outline: A sky fades from the horizon to the zenith and the ground
+  Given w ← world()
+    And w.background ← sky(color(0, 0, 1), color(1, 1, 1), color(0.5, 0.25, 0))
+  When c ← background_at(w, vector(<x>, <y>, <z>))
+  Then c = color(<r>, <g>, <b>)
+
+  Examples:
+    | x | y  | z | r       | g       | b       |
+    | 0 | 1  | 0 | 0       | 0       | 1       |
+    | 1 | 0  | 0 | 1       | 1       | 1       |
+    | 0 | -1 | 0 | 0.5     | 0.25    | 0       |
+    | 0 | 1  | 1 | 0.29289 | 0.29289 | 1       |
+    | 0 | -1 | 1 | 0.64645 | 0.46967 | 0.29289 |
+
+Scenario Outline: An environment map wraps an image around the world
+  Given w ← world()
+    And image ← gradient_canvas(4, 2)
+    And w.background ← environment_map(image)
+  When c ← background_at(w, vector(<x>, <y>, <z>))
+  Then c = color(<r>, <g>, 0)
+
+  Examples:
+    | x  | y  | z  | r   | g   |
+    | 0  | 0  | 1  | 1.5 | 0.5 |
+    | 0  | 0  | 3  | 1.5 | 0.5 |
+    | 1  | 0  | 0  | 2.5 | 0.5 |
+    | -1 | 0  | 0  | 0.5 | 0.5 |
+    | 0  | 0  | -1 | 1.5 | 0.5 |
+    | 0  | 1  | 0  | 1.5 | 0   |
+    | 0  | -1 | 0  | 1.5 | 1   |
+
+Scenario: An even environment lights a surface as much as the sky it faces
+  Given w ← world()
+    And image ← canvas(4, 2) filled with color(1, 1, 1)
+    And w.background ← environment_map(image)
+    And light ← environment_light(w.background, 16, 16)
+    And w has light light
+    And shape ← sphere() in w
+    And shape.material.ambient ← 0
+    And shape.material.diffuse ← 1
+    And shape.material.specular ← 0
+    And r ← ray(point(0, 5, 0), vector(0, -1, 0))
+    And i ← intersection(4, shape)
+  When comps ← prepare_computations(w, i, r)
+    And c ← shade_hit(w, comps)
+  Then c = color(1, 1, 1)
+
+Scenario: An environment light aims its samples at the bright parts of the map
+  Given w ← world()
+    And image ← canvas(8, 4) filled with color(0, 0, 0)
+    And write_pixel(image, 4, 1, color(16, 16, 16))
+    And w.background ← environment_map(image)
+    And light ← environment_light(w.background, 1, 1)
+    And w has light light
+    And shape ← sphere() in w
+    And shape.material.ambient ← 0
+    And shape.material.diffuse ← 1
+    And shape.material.specular ← 0
+    And r ← ray(point(0, 5, 0), vector(0, -1, 0))
+    And i ← intersection(4, shape)
+  When comps ← prepare_computations(w, i, r)
+    And c ← shade_hit(w, comps)
+  Then c = color(1, 1, 1)
+
+Scenario: Path tracing counts a background that lights the world once
+  Given w ← world()
+    And w.integrator ← path
+    And image ← canvas(4, 2) filled with color(1, 1, 1)
+    And w.background ← environment_map(image)
+    And light ← environment_light(w.background, 4, 4)
+    And w has light light
+    And shape ← sphere() in w
+    And shape.material.diffuse ← 1
+    And shape.material.specular ← 0
+    And r ← ray(point(0, 5, 0), vector(0, -1, 0))
+  When c ← radiance(w, r) averaged over 20 paths
+  Then c = color(1, 1, 1)
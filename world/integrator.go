@@ -0,0 +1,150 @@
+package world
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"rtt/ray"
+	"rtt/tuple"
+)
+
+type Integrator int
+
+const (
+	// Whitted shades each hit with the Phong model and follows mirror
+	// reflections, using the ambient term in place of indirect light.
+	Whitted Integrator = iota
+	// PathTracing follows random diffuse bounces to gather indirect light
+	// and light from emissive surfaces. It is noisy unless many samples are
+	// taken per pixel.
+	PathTracing
+)
+
+var integratorNames = []string{"whitted", "path"}
+
+// rouletteDepth is the number of bounces a path makes before Russian
+// roulette may end it.
+const rouletteDepth = 3
+
+func (i Integrator) String() string {
+	if i < 0 || int(i) >= len(integratorNames) {
+		return fmt.Sprintf("Integrator(%d)", int(i))
+	}
+	return integratorNames[i]
+}
+
+func ParseIntegrator(name string) (Integrator, error) {
+	for i, n := range integratorNames {
+		if n == name {
+			return Integrator(i), nil
+		}
+	}
+	return Whitted, fmt.Errorf("unknown integrator %q, expected whitted or path", name)
+}
+
+// Radiance returns the light arriving back along r, worked out by the
+// integrator of the world. rng samples the lights, and the paths of the
+// integrators that follow them.
+func (w *World) Radiance(r *ray.Ray, rng *rand.Rand) tuple.Color {
+	if w.Integrator == PathTracing {
+		return w.pathTrace(r, rng)
+	}
+	return w.colorAt(r, w.MaxDepth, rng)
+}
+
+// pathTrace follows a single path from r. At each hit it adds the emission
+// of the surface and the direct light from the world's lights, then either
+// follows the mirror reflection, with a probability of the material's
+// reflectivity, or bounces in a direction sampled from the material. Paths
+// end after MaxDepth bounces, and from rouletteDepth on are ended at random
+// when they carry little light, which the survivors make up for.
+//
+// The emission of a shape made into a light is not added when a path
+// bounces onto it, since it was already added as direct light at the
+// bounce. It still is when it is seen straight on or in a mirror. The same
+// goes for a background that lights the world.
+func (w *World) pathTrace(r *ray.Ray, rng *rand.Rand) tuple.Color {
+	result := tuple.Black
+	throughput := tuple.White
+	lit := false
+
+	for depth := 0; ; depth++ {
+		hit := w.Intersect(r).Hit()
+		if hit == nil {
+			if lit && w.isBackgroundLight() {
+				return result
+			}
+			return result.Add(throughput.Hadamard(w.backgroundAt(r.Direction)))
+		}
+
+		comps := w.PrepareComputations(hit, r)
+		material := comps.material()
+
+		if !lit || !w.isEmitter(comps.Object) {
+			result = result.Add(throughput.Hadamard(material.Emission))
+		}
+
+		if depth >= w.MaxDepth {
+			return result
+		}
+
+		if depth >= rouletteDepth {
+			survival := math.Min(1, math.Max(throughput.R, math.Max(throughput.G, throughput.B)))
+			if rng.Float64() >= survival {
+				return result
+			}
+			throughput = throughput.ScalarDiv(survival)
+		}
+
+		if rng.Float64() < material.Reflective {
+			r = comps.spawn(comps.Reflectv)
+			lit = false
+			continue
+		}
+
+		result = result.Add(throughput.Hadamard(w.directLight(material, comps, rng)))
+		lit = true
+
+		direction, weight := material.SampleBounce(comps.Eyev, comps.Normalv, rng)
+		if weight == tuple.Black {
+			return result
+		}
+		throughput = throughput.Hadamard(weight)
+
+		r = comps.spawn(direction)
+	}
+}
+
+// isEmitter reports whether a shape has been made into one of the world's
+// lights.
+func (w *World) isEmitter(object *ray.Sphere) bool {
+	for _, light := range w.Lights {
+		if emitter := ray.EmitterOf(light); emitter != nil && emitter == ray.Emitter(object) {
+			return true
+		}
+	}
+	return false
+}
+
+// isBackgroundLight reports whether the world's background is also one of
+// its lights.
+func (w *World) isBackgroundLight() bool {
+	for _, light := range w.Lights {
+		if environment, ok := light.(*EnvironmentLight); ok && Background(environment.Map) == w.Background {
+			return true
+		}
+	}
+	return false
+}
+
+// directLight is the shading of the hit without the ambient term, which
+// path tracing replaces with the light it gathers.
+func (w *World) directLight(material ray.Material, comps *Computations, rng *rand.Rand) tuple.Color {
+	material.Ambient = 0
+
+	result := tuple.Black
+	for _, light := range w.Lights {
+		result = result.Add(w.shade(&material, light, comps, rng))
+	}
+	return result
+}
@@ -0,0 +1,233 @@
+package world
+
+import (
+	"math/rand"
+	"rtt/ray"
+	"rtt/shared"
+	"rtt/tuple"
+)
+
+const DefaultMaxDepth = 5
+
+type World struct {
+	Objects       []*ray.Sphere
+	Lights        []ray.Light
+	MaxDepth      int
+	SurfaceOffset float64
+	Integrator    Integrator
+	Background    Background
+}
+
+type Computations struct {
+	T         float64
+	Object    *ray.Sphere
+	Point     tuple.Point
+	OverPoint tuple.Point
+	Eyev      tuple.Vector
+	Normalv   tuple.Vector
+	Reflectv  tuple.Vector
+	Inside    bool
+	// Time is when the ray that hit was cast, which rays that carry on from
+	// the hit share.
+	Time float64
+}
+
+func NewWorld() *World {
+	return &World{
+		Objects:       []*ray.Sphere{},
+		Lights:        []ray.Light{},
+		MaxDepth:      DefaultMaxDepth,
+		SurfaceOffset: shared.DefaultSurfaceOffset,
+	}
+}
+
+func (w *World) AddObject(s *ray.Sphere) {
+	w.Objects = append(w.Objects, s)
+}
+
+func (w *World) AddLight(l ray.Light) {
+	w.Lights = append(w.Lights, l)
+}
+
+func (w *World) Intersect(r *ray.Ray) ray.Intersections {
+	lists := make([]ray.Intersections, len(w.Objects))
+
+	for i, o := range w.Objects {
+		lists[i] = o.Intersect(r)
+	}
+
+	return ray.MergeAll(lists...)
+}
+
+func (w *World) PrepareComputations(i *ray.Intersection, r *ray.Ray) *Computations {
+	object := i.Object
+	point := r.Position(i.T)
+	eyev := r.Direction.Negate()
+	normalv := object.NormalAt(point, r.Time)
+
+	inside := false
+	if normalv.Dot(eyev) < 0 {
+		inside = true
+		normalv = normalv.Negate()
+	}
+
+	overPoint := point.Add(normalv.ScalarMultiply(w.SurfaceOffset))
+	reflectv := r.Direction.Reflect(normalv)
+
+	return &Computations{
+		T:         i.T,
+		Object:    object,
+		Point:     point,
+		OverPoint: overPoint,
+		Eyev:      eyev,
+		Normalv:   normalv,
+		Reflectv:  reflectv,
+		Inside:    inside,
+		Time:      r.Time,
+	}
+}
+
+// material is the material of the object that was hit, coloured by its
+// pattern at the hit.
+func (c *Computations) material() ray.Material {
+	return c.Object.MaterialAt(c.Point, c.Time)
+}
+
+// spawn starts a ray from just above the hit, cast at the same time as the
+// ray that hit.
+func (c *Computations) spawn(direction tuple.Vector) *ray.Ray {
+	r := ray.NewRay(c.OverPoint, direction)
+	r.Time = c.Time
+	return r
+}
+
+func (w *World) IsShadowed(lightPosition, point tuple.Point) bool {
+	v := lightPosition.Subtract(point)
+	return w.isOccluded(point, v.Normalize(), v.Magnitude(), 0, nil)
+}
+
+// isOccluded reports whether anything but the shape a light is made from,
+// if any, blocks the way from point to it at time.
+func (w *World) isOccluded(point tuple.Point, direction tuple.Vector, distance, time float64, emitter ray.Emitter) bool {
+	r := ray.NewRay(point, direction)
+	r.Time = time
+
+	for _, o := range w.Objects {
+		if o.Material.CastsShadow && ray.Emitter(o) != emitter && o.AnyHit(r, distance) {
+			return true
+		}
+	}
+	return false
+}
+
+// AnyHit reports whether r meets an object that casts shadows at some t with
+// 0 <= t < maxT. It stops at the first one it finds, in no particular order.
+func (w *World) AnyHit(r *ray.Ray, maxT float64) bool {
+	return w.isOccluded(r.Origin, r.Direction, maxT, r.Time, nil)
+}
+
+// IntensityAt is the share of the samples of light that reach point, with
+// any moving shapes where they start.
+func (w *World) IntensityAt(light ray.Light, point tuple.Point) float64 {
+	return w.intensityAt(light, point, 0, nil)
+}
+
+func (w *World) intensityAt(light ray.Light, point tuple.Point, time float64, rng *rand.Rand) float64 {
+	samples := light.Sample(rng)
+	emitter := ray.EmitterOf(light)
+	total := 0.0
+
+	for _, sample := range samples {
+		if !w.isOccluded(point, sample.DirectionFrom(point), sample.DistanceFrom(point), time, emitter) {
+			total += 1
+		}
+	}
+
+	return total / float64(len(samples))
+}
+
+// shade is the light that material reflects at the hit from one light.
+//
+// The samples of an environment light are shadowed one by one, rather than
+// the light as a whole by the share of them that are blocked. About half of
+// them come from behind the surface, and would otherwise count against it
+// twice: once for giving it no light and again for being shadowed by it.
+func (w *World) shade(material *ray.Material, light ray.Light, comps *Computations, rng *rand.Rand) tuple.Color {
+	environment, ok := light.(*EnvironmentLight)
+	if !ok {
+		intensity := w.intensityAt(light, comps.OverPoint, comps.Time, rng)
+		return ray.Lighting(material, light, comps.OverPoint, comps.Eyev, comps.Normalv, intensity, rng)
+	}
+
+	ambient := material.Color.Hadamard(environment.IntensityAt(comps.OverPoint)).ScalarMultiply(material.Ambient)
+	unlit := *material
+	unlit.Ambient = 0
+
+	samples := environment.Sample(rng)
+	sum := tuple.Black
+
+	for _, sample := range samples {
+		if !w.isOccluded(comps.OverPoint, sample.DirectionFrom(comps.OverPoint), sample.DistanceFrom(comps.OverPoint), comps.Time, nil) {
+			sum = sum.Add(ray.Lighting(&unlit, sample, comps.OverPoint, comps.Eyev, comps.Normalv, 1, rng))
+		}
+	}
+
+	return ambient.Add(sum.ScalarDiv(float64(len(samples))))
+}
+
+func (w *World) ShadeHit(comps *Computations, remaining int) tuple.Color {
+	return w.shadeHit(comps, remaining, nil)
+}
+
+func (w *World) shadeHit(comps *Computations, remaining int, rng *rand.Rand) tuple.Color {
+	material := comps.material()
+	surface := material.Emission
+
+	for _, light := range w.Lights {
+		surface = surface.Add(w.shade(&material, light, comps, rng))
+	}
+
+	reflected := w.reflectedColor(comps, remaining, rng)
+
+	return surface.Add(reflected)
+}
+
+func (w *World) ReflectedColor(comps *Computations, remaining int) tuple.Color {
+	return w.reflectedColor(comps, remaining, nil)
+}
+
+func (w *World) reflectedColor(comps *Computations, remaining int, rng *rand.Rand) tuple.Color {
+	if remaining <= 0 || comps.Object.Material.Reflective == 0 {
+		return tuple.Black
+	}
+
+	r := comps.spawn(comps.Reflectv)
+	color := w.colorAt(r, remaining-1, rng)
+
+	return color.ScalarMultiply(comps.Object.Material.Reflective)
+}
+
+func (w *World) ColorAt(r *ray.Ray) tuple.Color {
+	return w.colorAt(r, w.MaxDepth, nil)
+}
+
+// colorAt is the colour seen along r, with the lights sampled with rng.
+func (w *World) colorAt(r *ray.Ray, remaining int, rng *rand.Rand) tuple.Color {
+	hit := w.Intersect(r).Hit()
+
+	if hit == nil {
+		return w.backgroundAt(r.Direction)
+	}
+
+	comps := w.PrepareComputations(hit, r)
+	return w.shadeHit(comps, remaining, rng)
+}
+
+// backgroundAt is the colour seen by a ray in direction that misses every
+// object, which is black unless the world has a background.
+func (w *World) backgroundAt(direction tuple.Vector) tuple.Color {
+	if w.Background == nil {
+		return tuple.Black
+	}
+	return w.Background.ColorAt(direction)
+}
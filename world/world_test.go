@@ -0,0 +1,696 @@
+package world
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"rtt/canvas"
+	"rtt/matrix"
+	"rtt/ray"
+	"rtt/shared"
+	"rtt/sharedtest"
+	"rtt/transformations"
+	"rtt/tuple"
+	"rtt/tupletest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/cucumber/godog"
+)
+
+func defaultWorld() *World {
+	w := NewWorld()
+	w.AddLight(ray.NewPointLight(tuple.NewPoint(-10, 10, -10), tuple.NewColor(1, 1, 1)))
+
+	s1 := ray.NewSphere()
+	s1.Material.Color = tuple.NewColor(0.8, 1.0, 0.6)
+	s1.Material.Diffuse = 0.7
+	s1.Material.Specular = 0.2
+	w.AddObject(s1)
+
+	s2 := ray.NewSphere()
+	s2.SetTransform(transformations.Scaling(0.5, 0.5, 0.5))
+	w.AddObject(s2)
+
+	return w
+}
+
+func getWorld(ctx context.Context, variable string) *World {
+	return ctx.Value(sharedtest.Variables{Name: variable}).(*World)
+}
+
+func getComputations(ctx context.Context, variable string) *Computations {
+	return ctx.Value(sharedtest.Variables{Name: variable}).(*Computations)
+}
+
+func aWorld(ctx context.Context, variable string) (context.Context, error) {
+	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, NewWorld()), nil
+}
+
+func aDefaultWorld(ctx context.Context, variable string) (context.Context, error) {
+	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, defaultWorld()), nil
+}
+
+func aPointLight(ctx context.Context, variable string, x, y, z, r, g, b float64) (context.Context, error) {
+	light := ray.NewPointLight(tuple.NewPoint(x, y, z), tuple.NewColor(r, g, b))
+	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, light), nil
+}
+
+func setWorldLight(ctx context.Context, variable string, x, y, z, r, g, b float64) (context.Context, error) {
+	w := getWorld(ctx, variable)
+	w.Lights = []ray.Light{ray.NewPointLight(tuple.NewPoint(x, y, z), tuple.NewColor(r, g, b))}
+	return ctx, nil
+}
+
+func aRay(ctx context.Context, variable, ox, oy, oz, dx, dy, dz string) (context.Context, error) {
+	x, y, z, err := sharedtest.ParseXYZ(ox, oy, oz)
+	if err != nil {
+		return ctx, err
+	}
+	origin := tuple.NewPoint(x, y, z)
+
+	x, y, z, err = sharedtest.ParseXYZ(dx, dy, dz)
+	if err != nil {
+		return ctx, err
+	}
+	direction := tuple.NewVector(x, y, z)
+
+	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, ray.NewRay(origin, direction)), nil
+}
+
+func anIntersectWorld(ctx context.Context, variable, worldVariable, rayVariable string) (context.Context, error) {
+	w := getWorld(ctx, worldVariable)
+	r := ctx.Value(sharedtest.Variables{Name: rayVariable}).(*ray.Ray)
+	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, w.Intersect(r)), nil
+}
+
+func anObjectInWorld(ctx context.Context, variable, ordinal, worldVariable string) (context.Context, error) {
+	w := getWorld(ctx, worldVariable)
+
+	index := 0
+	if ordinal == "second" {
+		index = 1
+	}
+
+	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, w.Objects[index]), nil
+}
+
+func aSphereInWorld(ctx context.Context, variable, worldVariable string) (context.Context, error) {
+	w := getWorld(ctx, worldVariable)
+	s := ray.NewSphere()
+	w.AddObject(s)
+	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, s), nil
+}
+
+func aTranslatedSphereInWorld(ctx context.Context, variable, kind string, x, y, z float64, worldVariable string) (context.Context, error) {
+	w := getWorld(ctx, worldVariable)
+	s := ray.NewSphere()
+
+	transform := transformations.Translation(x, y, z)
+	if kind == "scaling" {
+		transform = transformations.Scaling(x, y, z)
+	}
+	if err := s.SetTransform(transform); err != nil {
+		return ctx, err
+	}
+	w.AddObject(s)
+	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, s), nil
+}
+
+func aMovingSphereInWorld(ctx context.Context, variable string, x, y, z float64, worldVariable string) (context.Context, error) {
+	w := getWorld(ctx, worldVariable)
+	s := ray.NewSphere()
+
+	if err := s.SetMotion(matrix.Identity4, transformations.Translation(x, y, z)); err != nil {
+		return ctx, err
+	}
+	w.AddObject(s)
+	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, s), nil
+}
+
+func setRayTime(ctx context.Context, rayVariable string, time float64) (context.Context, error) {
+	ctx.Value(sharedtest.Variables{Name: rayVariable}).(*ray.Ray).Time = time
+	return ctx, nil
+}
+
+func anIntersection(ctx context.Context, variable, tString, objectVariable string) (context.Context, error) {
+	t, _, _, err := sharedtest.ParseXYZ(tString, "0", "0")
+	if err != nil {
+		return ctx, err
+	}
+	s := ctx.Value(sharedtest.Variables{Name: objectVariable}).(*ray.Sphere)
+	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, s.Intersection(t)), nil
+}
+
+func somePreparedComputations(ctx context.Context, variable, worldVariable, intersectionVariable, rayVariable string) (context.Context, error) {
+	w := getWorld(ctx, worldVariable)
+	i := ctx.Value(sharedtest.Variables{Name: intersectionVariable}).(*ray.Intersection)
+	r := ctx.Value(sharedtest.Variables{Name: rayVariable}).(*ray.Ray)
+	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, w.PrepareComputations(i, r)), nil
+}
+
+func aShadeHit(ctx context.Context, variable, worldVariable, compsVariable string) (context.Context, error) {
+	w := getWorld(ctx, worldVariable)
+	comps := getComputations(ctx, compsVariable)
+	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, w.ShadeHit(comps, w.MaxDepth)), nil
+}
+
+func aReflectedColor(ctx context.Context, variable, worldVariable, compsVariable, remaining string) (context.Context, error) {
+	w := getWorld(ctx, worldVariable)
+	comps := getComputations(ctx, compsVariable)
+
+	depth := w.MaxDepth
+	if remaining != "" {
+		fmt.Sscanf(remaining, "%d", &depth)
+	}
+
+	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, w.ReflectedColor(comps, depth)), nil
+}
+
+func aColorAt(ctx context.Context, variable, worldVariable, rayVariable string) (context.Context, error) {
+	w := getWorld(ctx, worldVariable)
+	r := ctx.Value(sharedtest.Variables{Name: rayVariable}).(*ray.Ray)
+	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, w.ColorAt(r)), nil
+}
+
+func theWorldLight(ctx context.Context, variable, worldVariable string) (context.Context, error) {
+	w := getWorld(ctx, worldVariable)
+	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, w.Lights[0]), nil
+}
+
+func aDirectionalLight(ctx context.Context, variable string, x, y, z, r, g, b float64) (context.Context, error) {
+	light := ray.NewDirectionalLight(tuple.NewVector(x, y, z), tuple.NewColor(r, g, b))
+	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, light), nil
+}
+
+func anAreaLight(ctx context.Context, variable string, cx, cy, cz, ux, uy, uz float64, usteps int, vx, vy, vz float64, vsteps int) (context.Context, error) {
+	light := ray.NewAreaLight(tuple.NewPoint(cx, cy, cz), tuple.NewVector(ux, uy, uz), usteps, tuple.NewVector(vx, vy, vz), vsteps, tuple.NewColor(1, 1, 1))
+	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, light), nil
+}
+
+func aShapeLight(ctx context.Context, variable, objectVariable string, usteps, vsteps int) (context.Context, error) {
+	s := ctx.Value(sharedtest.Variables{Name: objectVariable}).(*ray.Sphere)
+	light := ray.NewShapeLight(s, s.Material.Emission, usteps, vsteps)
+	light.JitterBy = ray.Sequence(0.5)
+	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, light), nil
+}
+
+func setJitter(ctx context.Context, lightVariable, valuesString string) (context.Context, error) {
+	light := ctx.Value(sharedtest.Variables{Name: lightVariable}).(*ray.AreaLight)
+
+	values := []float64{}
+	for _, s := range strings.Split(valuesString, ", ") {
+		value, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return ctx, err
+		}
+		values = append(values, value)
+	}
+
+	light.JitterBy = ray.Sequence(values...)
+	return ctx, nil
+}
+
+func addLight(ctx context.Context, worldVariable, lightVariable string) (context.Context, error) {
+	w := getWorld(ctx, worldVariable)
+	w.AddLight(ctx.Value(sharedtest.Variables{Name: lightVariable}).(ray.Light))
+	return ctx, nil
+}
+
+func anIntensityAt(ctx context.Context, variable, lightVariable, pointVariable, worldVariable string) (context.Context, error) {
+	w := getWorld(ctx, worldVariable)
+	p := ctx.Value(sharedtest.Variables{Name: pointVariable}).(tuple.Point)
+
+	light := ctx.Value(sharedtest.Variables{Name: lightVariable}).(ray.Light)
+
+	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, w.IntensityAt(light, p)), nil
+}
+
+func setCastsShadow(ctx context.Context, objectVariable, value string) (context.Context, error) {
+	s := ctx.Value(sharedtest.Variables{Name: objectVariable}).(*ray.Sphere)
+	s.Material.CastsShadow = value == "true"
+	return ctx, nil
+}
+
+func assertAnyHit(ctx context.Context, worldVariable, rayVariable string, maxT float64, expected string) error {
+	w := getWorld(ctx, worldVariable)
+	r := ctx.Value(sharedtest.Variables{Name: rayVariable}).(*ray.Ray)
+
+	if actual := w.AnyHit(r, maxT); actual != (expected == "true") {
+		return fmt.Errorf("Error any_hit was %t!", actual)
+	}
+	return nil
+}
+
+func setMaterialColor(ctx context.Context, objectVariable, component string, r, g, b float64) (context.Context, error) {
+	s := ctx.Value(sharedtest.Variables{Name: objectVariable}).(*ray.Sphere)
+
+	if component == "emission" {
+		s.Material.Emission = tuple.NewColor(r, g, b)
+	} else {
+		s.Material.Color = tuple.NewColor(r, g, b)
+	}
+	return ctx, nil
+}
+
+func setMarblePattern(ctx context.Context, objectVariable string, ar, ag, ab, br, bg, bb float64) (context.Context, error) {
+	s := ctx.Value(sharedtest.Variables{Name: objectVariable}).(*ray.Sphere)
+	s.Material.Pattern = ray.NewMarble(tuple.NewColor(ar, ag, ab), tuple.NewColor(br, bg, bb))
+	return ctx, nil
+}
+
+func setIntegrator(ctx context.Context, variable, name string) (context.Context, error) {
+	w := getWorld(ctx, variable)
+
+	integrator, err := ParseIntegrator(name)
+	if err != nil {
+		return ctx, err
+	}
+
+	w.Integrator = integrator
+	return ctx, nil
+}
+
+func setMaxDepth(ctx context.Context, variable string, depth int) (context.Context, error) {
+	getWorld(ctx, variable).MaxDepth = depth
+	return ctx, nil
+}
+
+// aRadiance averages samples paths, each with a fixed seed so that the
+// scenarios are repeatable.
+func aRadiance(ctx context.Context, variable, worldVariable, rayVariable, samples string) (context.Context, error) {
+	w := getWorld(ctx, worldVariable)
+	r := ctx.Value(sharedtest.Variables{Name: rayVariable}).(*ray.Ray)
+
+	n := 1
+	if samples != "" {
+		n, _ = strconv.Atoi(samples)
+	}
+
+	rng := rand.New(rand.NewSource(1))
+	sum := tuple.Black
+	for i := 0; i < n; i++ {
+		sum = sum.Add(w.Radiance(r, rng))
+	}
+
+	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, sum.ScalarDiv(float64(n))), nil
+}
+
+func setFlatBackground(ctx context.Context, variable string, r, g, b float64) (context.Context, error) {
+	getWorld(ctx, variable).Background = NewFlatBackground(tuple.NewColor(r, g, b))
+	return ctx, nil
+}
+
+func setSky(ctx context.Context, variable string, zr, zg, zb, hr, hg, hb, gr, gg, gb float64) (context.Context, error) {
+	getWorld(ctx, variable).Background = NewSky(tuple.NewColor(zr, zg, zb), tuple.NewColor(hr, hg, hb), tuple.NewColor(gr, gg, gb))
+	return ctx, nil
+}
+
+func setEnvironmentMap(ctx context.Context, variable, imageVariable string) (context.Context, error) {
+	image := ctx.Value(sharedtest.Variables{Name: imageVariable}).(*canvas.Canvas)
+	getWorld(ctx, variable).Background = NewEnvironmentMap(image)
+	return ctx, nil
+}
+
+// aGradientCanvas makes a canvas whose pixel at x, y has the colour
+// color(x, y, 0), so that blends between pixels can be read off.
+func aGradientCanvas(ctx context.Context, variable string, width, height int) (context.Context, error) {
+	c := canvas.NewCanvas(int32(width), int32(height))
+	for y := int32(0); y < c.Height; y++ {
+		for x := int32(0); x < c.Width; x++ {
+			c.WritePixel(x, y, tuple.NewColor(float64(x), float64(y), 0))
+		}
+	}
+	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, c), nil
+}
+
+func aFilledCanvas(ctx context.Context, variable string, width, height int, r, g, b float64) (context.Context, error) {
+	c := canvas.NewCanvas(int32(width), int32(height))
+	for i := range c.Pixels {
+		c.Pixels[i] = tuple.NewColor(r, g, b)
+	}
+	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, c), nil
+}
+
+func writePixel(ctx context.Context, variable string, x, y int, r, g, b float64) (context.Context, error) {
+	c := ctx.Value(sharedtest.Variables{Name: variable}).(*canvas.Canvas)
+	c.WritePixel(int32(x), int32(y), tuple.NewColor(r, g, b))
+	return ctx, nil
+}
+
+func anEnvironmentLight(ctx context.Context, variable, worldVariable string, usteps, vsteps int) (context.Context, error) {
+	environment, ok := getWorld(ctx, worldVariable).Background.(*EnvironmentMap)
+	if !ok {
+		return ctx, fmt.Errorf("the background of %s is not an environment map", worldVariable)
+	}
+
+	light := NewEnvironmentLight(environment, usteps, vsteps)
+	light.JitterBy = ray.Sequence(0.5)
+	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, light), nil
+}
+
+func aBackgroundAt(ctx context.Context, variable, worldVariable string, x, y, z float64) (context.Context, error) {
+	w := getWorld(ctx, worldVariable)
+	return context.WithValue(ctx, sharedtest.Variables{Name: variable}, w.backgroundAt(tuple.NewVector(x, y, z))), nil
+}
+
+func assertColorWithin(ctx context.Context, variable string, r, g, b, tolerance float64) error {
+	actual := ctx.Value(sharedtest.Variables{Name: variable}).(tuple.Color)
+	expected := tuple.NewColor(r, g, b)
+
+	if !tuple.CompareTupleWithin(actual.Tuple(), expected.Tuple(), shared.Tolerance{Absolute: tolerance}) {
+		return fmt.Errorf("Error color %+v is not within %g of %+v!", actual, tolerance, expected)
+	}
+	return nil
+}
+
+func assertIntegrator(ctx context.Context, variable, expected string) error {
+	w := getWorld(ctx, variable)
+	if w.Integrator.String() != expected {
+		return fmt.Errorf("Error integrator %s != %s!", w.Integrator, expected)
+	}
+	return nil
+}
+
+func assertUnknownIntegrator(name, expected string) error {
+	_, err := ParseIntegrator(name)
+	if err == nil {
+		return fmt.Errorf("integrator %q was parsed", name)
+	}
+
+	expected = strings.ReplaceAll(expected, `\"`, `"`)
+	if err.Error() != expected {
+		return fmt.Errorf("Error %q != %q!", err.Error(), expected)
+	}
+	return nil
+}
+
+func setSurfaceOffset(ctx context.Context, variable string, offset float64) (context.Context, error) {
+	w := ctx.Value(sharedtest.Variables{Name: variable}).(*World)
+	w.SurfaceOffset = offset
+	return ctx, nil
+}
+
+func setMaterialComponent(ctx context.Context, objectVariable, component string, value float64) (context.Context, error) {
+	s := ctx.Value(sharedtest.Variables{Name: objectVariable}).(*ray.Sphere)
+
+	switch component {
+	case "ambient":
+		s.Material.Ambient = value
+	case "diffuse":
+		s.Material.Diffuse = value
+	case "specular":
+		s.Material.Specular = value
+	case "reflective":
+		s.Material.Reflective = value
+	default:
+		return ctx, fmt.Errorf("unknown component %s", component)
+	}
+
+	return ctx, nil
+}
+
+func assertNoObjects(ctx context.Context, variable string) error {
+	w := getWorld(ctx, variable)
+	if len(w.Objects) != 0 {
+		return fmt.Errorf("world has %d objects", len(w.Objects))
+	}
+	return nil
+}
+
+func assertNoLights(ctx context.Context, variable string) error {
+	w := getWorld(ctx, variable)
+	if len(w.Lights) != 0 {
+		return fmt.Errorf("world has %d lights", len(w.Lights))
+	}
+	return nil
+}
+
+func assertWorldLight(ctx context.Context, variable, lightVariable string) error {
+	w := getWorld(ctx, variable)
+	light := ctx.Value(sharedtest.Variables{Name: lightVariable}).(*ray.PointLight)
+
+	if len(w.Lights) != 1 {
+		return fmt.Errorf("world has %d lights", len(w.Lights))
+	}
+
+	actual, ok := w.Lights[0].(*ray.PointLight)
+	if !ok || !actual.Position.Equals(light.Position) || !actual.Intensity.Equals(light.Intensity) {
+		return fmt.Errorf("Error %+v != %+v!", w.Lights[0], light)
+	}
+
+	return nil
+}
+
+func assertObjectCount(ctx context.Context, variable string, expected int) error {
+	w := getWorld(ctx, variable)
+	if len(w.Objects) != expected {
+		return fmt.Errorf("Error count %d not %d!", len(w.Objects), expected)
+	}
+	return nil
+}
+
+func assertIntersectionCount(ctx context.Context, variable string, expected int) error {
+	xs := ctx.Value(sharedtest.Variables{Name: variable}).(ray.Intersections)
+	if len(xs) != expected {
+		return fmt.Errorf("Error count %d not %d!", len(xs), expected)
+	}
+	return nil
+}
+
+func assertIntersectionT(ctx context.Context, variable string, index int, t float64) error {
+	xs := ctx.Value(sharedtest.Variables{Name: variable}).(ray.Intersections)
+	if !shared.CompareFloat(xs[index].T, t) {
+		return fmt.Errorf("Error %f != %f!", xs[index].T, t)
+	}
+	return nil
+}
+
+func assertComputationsT(ctx context.Context, variable string, t float64) error {
+	comps := getComputations(ctx, variable)
+	if !shared.CompareFloat(comps.T, t) {
+		return fmt.Errorf("Error %f != %f!", comps.T, t)
+	}
+	return nil
+}
+
+func assertComputationsObject(ctx context.Context, variable, objectVariable string) error {
+	comps := getComputations(ctx, variable)
+	s := ctx.Value(sharedtest.Variables{Name: objectVariable}).(*ray.Sphere)
+	if comps.Object != s {
+		return fmt.Errorf("Error %+v != %+v!", comps.Object, s)
+	}
+	return nil
+}
+
+func assertComputationsPoint(ctx context.Context, variable, component, xs, ys, zs string) error {
+	comps := getComputations(ctx, variable)
+	x, y, z, err := sharedtest.ParseXYZ(xs, ys, zs)
+	if err != nil {
+		return err
+	}
+
+	actual := comps.Point
+	if component == "over_point" {
+		actual = comps.OverPoint
+	}
+
+	expected := tuple.NewPoint(x, y, z)
+	if !actual.Equals(expected) {
+		return fmt.Errorf("Error %+v != %+v!", actual, expected)
+	}
+	return nil
+}
+
+func assertComputationsVector(ctx context.Context, variable, component, xs, ys, zs string) error {
+	comps := getComputations(ctx, variable)
+	x, y, z, err := sharedtest.ParseXYZ(xs, ys, zs)
+	if err != nil {
+		return err
+	}
+
+	var actual tuple.Vector
+	switch component {
+	case "eyev":
+		actual = comps.Eyev
+	case "normalv":
+		actual = comps.Normalv
+	case "reflectv":
+		actual = comps.Reflectv
+	}
+
+	expected := tuple.NewVector(x, y, z)
+	if !actual.Equals(expected) {
+		return fmt.Errorf("Error %+v != %+v!", actual, expected)
+	}
+	return nil
+}
+
+func assertComputationsInside(ctx context.Context, variable, expected string) error {
+	comps := getComputations(ctx, variable)
+	if comps.Inside != (expected == "true") {
+		return fmt.Errorf("Error inside was %t!", comps.Inside)
+	}
+	return nil
+}
+
+func assertOverPointBelowEpsilon(ctx context.Context, variable string) error {
+	comps := getComputations(ctx, variable)
+	if !(comps.OverPoint.Z < -shared.DefaultSurfaceOffset/2) {
+		return fmt.Errorf("Error over_point.z %f not < %f!", comps.OverPoint.Z, -shared.DefaultSurfaceOffset/2)
+	}
+	return nil
+}
+
+func assertPointAboveOverPoint(ctx context.Context, variable, overVariable string) error {
+	comps := getComputations(ctx, variable)
+	over := getComputations(ctx, overVariable)
+	if !(comps.Point.Z > over.OverPoint.Z) {
+		return fmt.Errorf("Error point.z %f not > over_point.z %f!", comps.Point.Z, over.OverPoint.Z)
+	}
+	return nil
+}
+
+func assertMaterialColor(ctx context.Context, variable, objectVariable string) error {
+	actual := ctx.Value(sharedtest.Variables{Name: variable}).(tuple.Color)
+	s := ctx.Value(sharedtest.Variables{Name: objectVariable}).(*ray.Sphere)
+	if !actual.Equals(s.Material.Color) {
+		return fmt.Errorf("Error %+v != %+v!", actual, s.Material.Color)
+	}
+	return nil
+}
+
+func assertIsShadowed(ctx context.Context, worldVariable, pointVariable, expected string) error {
+	w := getWorld(ctx, worldVariable)
+	p := ctx.Value(sharedtest.Variables{Name: pointVariable}).(tuple.Point)
+
+	actual := w.IsShadowed(w.Lights[0].(*ray.PointLight).Position, p)
+	if actual != (expected == "true") {
+		return fmt.Errorf("Error is_shadowed was %t!", actual)
+	}
+	return nil
+}
+
+func assertIsShadowedBetween(ctx context.Context, worldVariable, lightPositionVariable, pointVariable, expected string) error {
+	w := getWorld(ctx, worldVariable)
+	lightPosition := ctx.Value(sharedtest.Variables{Name: lightPositionVariable}).(tuple.Point)
+	p := ctx.Value(sharedtest.Variables{Name: pointVariable}).(tuple.Point)
+
+	actual := w.IsShadowed(lightPosition, p)
+	if actual != (expected == "true") {
+		return fmt.Errorf("Error is_shadowed was %t!", actual)
+	}
+	return nil
+}
+
+func assertIntensity(ctx context.Context, variable string, expected float64) error {
+	actual := ctx.Value(sharedtest.Variables{Name: variable}).(float64)
+	if !shared.CompareFloat(actual, expected) {
+		return fmt.Errorf("Error %f != %f!", actual, expected)
+	}
+	return nil
+}
+
+func assertColorAtTerminates(ctx context.Context, worldVariable, rayVariable string) error {
+	w := getWorld(ctx, worldVariable)
+	r := ctx.Value(sharedtest.Variables{Name: rayVariable}).(*ray.Ray)
+	w.ColorAt(r)
+	return nil
+}
+
+func constructors(sc *godog.ScenarioContext) {
+	v := sharedtest.TupleVariableName
+	d := sharedtest.Decimal
+	n := sharedtest.PosInt
+
+	tupletest.AddConstructPoint(sc)
+	sc.Step(fmt.Sprintf(`^%s ← world\(\)$`, v), aWorld)
+	sc.Step(fmt.Sprintf(`^%s ← default_world\(\)$`, v), aDefaultWorld)
+	sc.Step(fmt.Sprintf(`^%s ← point_light\(point\(%s, %s, %s\), color\(%s, %s, %s\)\)$`, v, d, d, d, d, d, d), aPointLight)
+	sc.Step(fmt.Sprintf(`^%s.light ← point_light\(point\(%s, %s, %s\), color\(%s, %s, %s\)\)$`, v, d, d, d, d, d, d), setWorldLight)
+	sc.Step(fmt.Sprintf(`^%s ← ray\(point\(%s, %s, %s\), vector\(%s, %s, %s\)\)$`, v, d, d, d, d, d, d), aRay)
+	sc.Step(fmt.Sprintf(`^%s ← intersect_world\(%s, %s\)$`, v, v, v), anIntersectWorld)
+	sc.Step(fmt.Sprintf(`^%s ← the (first|second) object in %s$`, v, v), anObjectInWorld)
+	sc.Step(fmt.Sprintf(`^%s ← sphere\(\) in %s$`, v, v), aSphereInWorld)
+	sc.Step(fmt.Sprintf(`^%s ← sphere\(\) with (translation|scaling)\(%s, %s, %s\) in %s$`, v, d, d, d, v), aTranslatedSphereInWorld)
+	sc.Step(fmt.Sprintf(`^%s ← sphere\(\) moving by translation\(%s, %s, %s\) in %s$`, v, d, d, d, v), aMovingSphereInWorld)
+	sc.Step(fmt.Sprintf(`^%s.time ← %s$`, v, d), setRayTime)
+	sc.Step(fmt.Sprintf(`^%s ← intersection\(%s, %s\)$`, v, d, v), anIntersection)
+	sc.Step(fmt.Sprintf(`^%s ← prepare_computations\(%s, %s, %s\)$`, v, v, v, v), somePreparedComputations)
+	sc.Step(fmt.Sprintf(`^%s ← shade_hit\(%s, %s\)$`, v, v, v), aShadeHit)
+	sc.Step(fmt.Sprintf(`^%s ← reflected_color\(%s, %s(?:, (\d+))?\)$`, v, v, v), aReflectedColor)
+	sc.Step(fmt.Sprintf(`^%s ← color_at\(%s, %s\)$`, v, v, v), aColorAt)
+	sc.Step(fmt.Sprintf(`^%s.material.(ambient|diffuse|specular|reflective) ← %s$`, v, d), setMaterialComponent)
+	sc.Step(fmt.Sprintf(`^%s.material.pattern ← marble\(color\(%s, %s, %s\), color\(%s, %s, %s\)\)$`, v, d, d, d, d, d, d), setMarblePattern)
+	sc.Step(fmt.Sprintf(`^%s.surface_offset ← %s$`, v, d), setSurfaceOffset)
+	sc.Step(fmt.Sprintf(`^%s.material.(color|emission) ← color\(%s, %s, %s\)$`, v, d, d, d), setMaterialColor)
+	sc.Step(fmt.Sprintf(`^%s.integrator ← ([a-z]+)$`, v), setIntegrator)
+	sc.Step(fmt.Sprintf(`^%s.max_depth ← %s$`, v, sharedtest.PosInt), setMaxDepth)
+	sc.Step(fmt.Sprintf(`^%s ← radiance\(%s, %s\)(?: averaged over (\d+) paths)?$`, v, v, v), aRadiance)
+	sc.Step(fmt.Sprintf(`^%s.background ← background\(color\(%s, %s, %s\)\)$`, v, d, d, d), setFlatBackground)
+	sc.Step(fmt.Sprintf(`^%s.background ← sky\(color\(%s, %s, %s\), color\(%s, %s, %s\), color\(%s, %s, %s\)\)$`, v, d, d, d, d, d, d, d, d, d), setSky)
+	sc.Step(fmt.Sprintf(`^%s.background ← environment_map\(%s\)$`, v, v), setEnvironmentMap)
+	sc.Step(fmt.Sprintf(`^%s ← gradient_canvas\(%s, %s\)$`, v, n, n), aGradientCanvas)
+	sc.Step(fmt.Sprintf(`^%s ← canvas\(%s, %s\) filled with color\(%s, %s, %s\)$`, v, n, n, d, d, d), aFilledCanvas)
+	sc.Step(fmt.Sprintf(`^write_pixel\(%s, %s, %s, color\(%s, %s, %s\)\)$`, v, n, n, d, d, d), writePixel)
+	sc.Step(fmt.Sprintf(`^%s ← environment_light\(%s.background, %s, %s\)$`, v, v, n, n), anEnvironmentLight)
+	sc.Step(fmt.Sprintf(`^%s ← background_at\(%s, vector\(%s, %s, %s\)\)$`, v, v, d, d, d), aBackgroundAt)
+	sc.Step(fmt.Sprintf(`^%s.material.casts_shadow ← (true|false)$`, v), setCastsShadow)
+	sc.Step(fmt.Sprintf(`^%s ← %s.light$`, v, v), theWorldLight)
+	sc.Step(fmt.Sprintf(`^%s ← directional_light\(vector\(%s, %s, %s\), color\(%s, %s, %s\)\)$`, v, d, d, d, d, d, d), aDirectionalLight)
+	sc.Step(fmt.Sprintf(`^%s ← area_light\(point\(%s, %s, %s\), vector\(%s, %s, %s\), %s, vector\(%s, %s, %s\), %s, color\(1, 1, 1\)\)$`, v, d, d, d, d, d, d, n, d, d, d, n), anAreaLight)
+	sc.Step(fmt.Sprintf(`^%s ← shape_light\(%s, %s, %s\)$`, v, v, n, n), aShapeLight)
+	sc.Step(fmt.Sprintf(`^%s.jitter_by ← sequence\(([0-9\., ]+)\)$`, v), setJitter)
+	sc.Step(fmt.Sprintf(`^%s has light %s$`, v, v), addLight)
+	sc.Step(fmt.Sprintf(`^%s ← intensity_at\(%s, %s, %s\)$`, v, v, v, v), anIntensityAt)
+}
+
+func assertions(sc *godog.ScenarioContext) {
+	v := sharedtest.TupleVariableName
+	d := sharedtest.Decimal
+
+	tupletest.AddCompareColor(sc)
+	sc.Step(fmt.Sprintf(`^%s contains no objects$`, v), assertNoObjects)
+	sc.Step(fmt.Sprintf(`^%s has no light source$`, v), assertNoLights)
+	sc.Step(fmt.Sprintf(`^%s.light = %s$`, v, v), assertWorldLight)
+	sc.Step(fmt.Sprintf(`^%s.objects.count = %s$`, v, sharedtest.PosInt), assertObjectCount)
+	sc.Step(fmt.Sprintf(`^%s.count = %s$`, v, sharedtest.PosInt), assertIntersectionCount)
+	sc.Step(fmt.Sprintf(`^%s\[%s\].t = %s$`, v, sharedtest.PosInt, d), assertIntersectionT)
+	sc.Step(fmt.Sprintf(`^%s.t = %s$`, v, d), assertComputationsT)
+	sc.Step(fmt.Sprintf(`^%s.object = %s$`, v, v), assertComputationsObject)
+	sc.Step(fmt.Sprintf(`^%s.(point|over_point) = point\(%s, %s, %s\)$`, v, d, d, d), assertComputationsPoint)
+	sc.Step(fmt.Sprintf(`^%s.(eyev|normalv|reflectv) = vector\(%s, %s, %s\)$`, v, d, d, d), assertComputationsVector)
+	sc.Step(fmt.Sprintf(`^%s.inside = (true|false)$`, v), assertComputationsInside)
+	sc.Step(fmt.Sprintf(`^%s.over_point.z < -EPSILON/2$`, v), assertOverPointBelowEpsilon)
+	sc.Step(fmt.Sprintf(`^%s.point.z > %s.over_point.z$`, v, v), assertPointAboveOverPoint)
+	sc.Step(fmt.Sprintf(`^%s = %s.material.color$`, v, v), assertMaterialColor)
+	sc.Step(fmt.Sprintf(`^is_shadowed\(%s, %s\) is (true|false)$`, v, v), assertIsShadowed)
+	sc.Step(fmt.Sprintf(`^%s = color\(%s, %s, %s\) within %s$`, v, d, d, d, d), assertColorWithin)
+	sc.Step(fmt.Sprintf(`^%s.integrator = ([a-z]+)$`, v), assertIntegrator)
+	sc.Step(`^parsing the integrator "([a-z]+)" fails with "(.*)"$`, assertUnknownIntegrator)
+	sc.Step(fmt.Sprintf(`^any_hit\(%s, %s, %s\) is (true|false)$`, v, v, d), assertAnyHit)
+	sc.Step(fmt.Sprintf(`^is_shadowed\(%s, ([a-z_]+), %s\) is (true|false)$`, v, v), assertIsShadowedBetween)
+	sc.Step(fmt.Sprintf(`^%s = %s$`, v, d), assertIntensity)
+	sc.Step(fmt.Sprintf(`^color_at\(%s, %s\) should terminate successfully$`, v, v), assertColorAtTerminates)
+}
+
+func initializeScenario(sc *godog.ScenarioContext) {
+	constructors(sc)
+	assertions(sc)
+}
+
+func TestFeatures(t *testing.T) {
+	suite := godog.TestSuite{
+		ScenarioInitializer: initializeScenario,
+		Options: &godog.Options{
+			Format:   "pretty",
+			Paths:    []string{"features/world.feature"},
+			TestingT: t,
+		},
+	}
+
+	if suite.Run() != 0 {
+		t.Fatal("non-zero exit status")
+	}
+}